@@ -0,0 +1,57 @@
+package api
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"todo/internal/controller"
+
+	"github.com/gin-gonic/gin"
+)
+
+var errorKindStatus = map[controller.Kind]int{
+	controller.KindInternal:        http.StatusInternalServerError,
+	controller.KindBadRequest:      http.StatusBadRequest,
+	controller.KindUnauthorized:    http.StatusUnauthorized,
+	controller.KindNotFound:        http.StatusNotFound,
+	controller.KindForbidden:       http.StatusForbidden,
+	controller.KindConflict:        http.StatusConflict,
+	controller.KindUnprocessable:   http.StatusUnprocessableEntity,
+	controller.KindTooLarge:        http.StatusRequestEntityTooLarge,
+	controller.KindUnsupportedType: http.StatusUnsupportedMediaType,
+}
+
+// respondError is the only place where errors are turned into responses,
+// body is RFC 7807 problem details extended with code and request_id
+func respondError(c *gin.Context, err error) {
+	var appErr *controller.Error
+	if !errors.As(err, &appErr) {
+		appErr = controller.InternalError(err)
+	}
+
+	status, ok := errorKindStatus[appErr.Kind]
+	if !ok {
+		status = http.StatusInternalServerError
+	}
+
+	if status == http.StatusInternalServerError {
+		// cause is never sent to client, so keep it in log
+		log.Printf("request %s failed: %v", requestId(c), appErr)
+	}
+
+	body := gin.H{}
+	for key, value := range appErr.Details {
+		body[key] = value
+	}
+	body["type"] = "about:blank"
+	body["title"] = http.StatusText(status)
+	body["status"] = status
+	body["detail"] = appErr.Message
+	body["instance"] = c.Request.URL.Path
+	body["code"] = appErr.Code
+	body["request_id"] = requestId(c)
+
+	c.Header("Content-Type", "application/problem+json")
+	c.AbortWithStatusJSON(status, body)
+}
@@ -0,0 +1,339 @@
+package api
+
+import (
+	"log"
+	"net/http"
+
+	"todo/internal/config"
+	"todo/internal/controller"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetLabelList godoc
+// @ID get-label-list
+// @Security ApiKeyAuth
+// @Summary      Get label list
+// @Description  Get labels of the user ordered by name
+// @Tags         label
+// @Accept       json
+// @Produce      json
+// @Param X-Workspace header int false "workspace id, own workspace of the user by default"
+// @Success 200 {array} model.Label
+// @Failure      400  {object}  http.StatusBadRequest
+// @Failure      500  {object}  http.StatusInternalServerError
+
+// @Router       /label [get]
+func (h *Handler) GetLabelList(c *gin.Context) {
+	_, workspaceId, err := h.authorizeWorkspace(c, controller.ActionView)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	if config.DebugLog() {
+		log.Println("requesting label list", fullUrl(c))
+	}
+
+	res, err := h.ctrl.GetLabelList(workspaceId)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+// CreateLabel godoc
+// @ID create-label
+// @Security ApiKeyAuth
+// @Summary      Create label
+// @Description  Create label, name is unique per user
+// @Tags         label
+// @Accept       json
+// @Produce      json
+// @Param input body todo.Model true "label input name,color"
+// @Param X-Workspace header int false "workspace id, own workspace of the user by default"
+// @Success 201 {object} model.Label
+// @Failure      400  {object}  http.StatusBadRequest
+// @Failure      403  {object}  http.StatusForbidden
+// @Failure      422  {object}  http.StatusUnprocessableEntity
+// @Failure      500  {object}  http.StatusInternalServerError
+
+// @Router       /label [post]
+func (h *Handler) CreateLabel(c *gin.Context) {
+	_, workspaceId, err := h.authorizeWorkspace(c, controller.ActionEdit)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	var bodyData map[string]interface{}
+	err = extractBody(c, &bodyData)
+	if err != nil {
+		respondError(c, controller.ErrInvalidBodyParams)
+		return
+	}
+
+	name, _ := bodyData["name"].(string)
+	color, _ := bodyData["color"].(string)
+
+	if config.DebugLog() {
+		log.Println("requesting label create", fullUrl(c))
+	}
+
+	label, err := h.ctrl.CreateLabel(workspaceId, name, color)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, label)
+}
+
+// GetLabel godoc
+// @ID get-label
+// @Security ApiKeyAuth
+// @Summary      Get label
+// @Description  Get label
+// @Tags         label
+// @Accept       json
+// @Produce      json
+// @Param id path int true "label id"
+// @Param X-Workspace header int false "workspace id, own workspace of the user by default"
+// @Success 200 {object} model.Label
+// @Failure      400  {object}  http.StatusBadRequest
+// @Failure      404  {object}  http.StatusNotFound
+// @Failure      500  {object}  http.StatusInternalServerError
+
+// @Router       /label/{id} [get]
+func (h *Handler) GetLabel(c *gin.Context) {
+	_, workspaceId, err := h.authorizeWorkspace(c, controller.ActionView)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	id, err := controller.ParseLabelId(c.Param("id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	if config.DebugLog() {
+		log.Println("requesting label by id", fullUrl(c))
+	}
+
+	res, err := h.ctrl.GetLabel(workspaceId, id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+// EditLabel godoc
+// @ID edit-label
+// @Security ApiKeyAuth
+// @Summary      Edit label
+// @Description  Edit label
+// @Tags         label
+// @Accept       json
+// @Produce      json
+// @Param id path int true "label id"
+// @Param input body todo.Model true "label input name,color"
+// @Param X-Workspace header int false "workspace id, own workspace of the user by default"
+// @Success	200 {object}
+// @Failure      400  {object}  http.StatusBadRequest
+// @Failure      403  {object}  http.StatusForbidden
+// @Failure      404  {object}  http.StatusNotFound
+// @Failure      422  {object}  http.StatusUnprocessableEntity
+// @Failure      500  {object}  http.StatusInternalServerError
+
+// @Router       /label/{id} [put]
+func (h *Handler) EditLabel(c *gin.Context) {
+	_, workspaceId, err := h.authorizeWorkspace(c, controller.ActionEdit)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	id, err := controller.ParseLabelId(c.Param("id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	var bodyData map[string]interface{}
+	err = extractBody(c, &bodyData)
+	if err != nil {
+		respondError(c, controller.ErrInvalidBodyParams)
+		return
+	}
+
+	name, _ := bodyData["name"].(string)
+	color, _ := bodyData["color"].(string)
+
+	if config.DebugLog() {
+		log.Println("requesting label edit", fullUrl(c))
+	}
+
+	err = h.ctrl.EditLabel(workspaceId, id, name, color)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": true,
+	})
+}
+
+// DeleteLabel godoc
+// @ID delete-label
+// @Security ApiKeyAuth
+// @Summary      Delete label
+// @Description  Delete label and remove it from all tasks
+// @Tags         label
+// @Accept       json
+// @Produce      json
+// @Param id path int true "label id"
+// @Param X-Workspace header int false "workspace id, own workspace of the user by default"
+// @Success	200 {object}
+// @Failure      400  {object}  http.StatusBadRequest
+// @Failure      403  {object}  http.StatusForbidden
+// @Failure      404  {object}  http.StatusNotFound
+// @Failure      500  {object}  http.StatusInternalServerError
+
+// @Router       /label/{id} [delete]
+func (h *Handler) DeleteLabel(c *gin.Context) {
+	_, workspaceId, err := h.authorizeWorkspace(c, controller.ActionEdit)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	id, err := controller.ParseLabelId(c.Param("id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	if config.DebugLog() {
+		log.Println("requesting label delete", fullUrl(c))
+	}
+
+	err = h.ctrl.DeleteLabel(workspaceId, id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": true,
+	})
+}
+
+// taskLabelIds resolves task and label of /task/:id/label/:label_id path
+func (h *Handler) taskLabelIds(c *gin.Context, userId uint16) (taskId, labelId int64, err error) {
+	taskId, err = h.ctrl.ResolveTaskId(userId, c.Param("id"))
+	if err != nil {
+		return
+	}
+
+	labelId, err = controller.ParseLabelId(c.Param("label_id"))
+	return
+}
+
+// AddTaskLabel godoc
+// @ID add-task-label
+// @Security ApiKeyAuth
+// @Summary      Add label to task
+// @Description  Add label to task, adding it twice does nothing
+// @Tags         task
+// @Accept       json
+// @Produce      json
+// @Param id path string true "task id or public id"
+// @Param label_id path int true "label id"
+// @Param X-Workspace header int false "workspace id, own workspace of the user by default"
+// @Success	200 {object}
+// @Failure      400  {object}  http.StatusBadRequest
+// @Failure      403  {object}  http.StatusForbidden
+// @Failure      404  {object}  http.StatusNotFound
+// @Failure      500  {object}  http.StatusInternalServerError
+
+// @Router       /task/{id}/label/{label_id} [put]
+func (h *Handler) AddTaskLabel(c *gin.Context) {
+	_, workspaceId, err := h.authorizeWorkspace(c, controller.ActionEdit)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	taskId, labelId, err := h.taskLabelIds(c, workspaceId)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	if config.DebugLog() {
+		log.Println("requesting task label add", fullUrl(c))
+	}
+
+	err = h.ctrl.AddTaskLabel(workspaceId, taskId, labelId)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": true,
+	})
+}
+
+// RemoveTaskLabel godoc
+// @ID remove-task-label
+// @Security ApiKeyAuth
+// @Summary      Remove label from task
+// @Description  Remove label from task, removing label task does not have does nothing
+// @Tags         task
+// @Accept       json
+// @Produce      json
+// @Param id path string true "task id or public id"
+// @Param label_id path int true "label id"
+// @Param X-Workspace header int false "workspace id, own workspace of the user by default"
+// @Success	200 {object}
+// @Failure      400  {object}  http.StatusBadRequest
+// @Failure      403  {object}  http.StatusForbidden
+// @Failure      404  {object}  http.StatusNotFound
+// @Failure      500  {object}  http.StatusInternalServerError
+
+// @Router       /task/{id}/label/{label_id} [delete]
+func (h *Handler) RemoveTaskLabel(c *gin.Context) {
+	_, workspaceId, err := h.authorizeWorkspace(c, controller.ActionEdit)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	taskId, labelId, err := h.taskLabelIds(c, workspaceId)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	if config.DebugLog() {
+		log.Println("requesting task label remove", fullUrl(c))
+	}
+
+	err = h.ctrl.RemoveTaskLabel(workspaceId, taskId, labelId)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": true,
+	})
+}
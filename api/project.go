@@ -0,0 +1,293 @@
+package api
+
+import (
+	"log"
+	"net/http"
+
+	"todo/internal/config"
+	"todo/internal/controller"
+
+	"github.com/gin-gonic/gin"
+)
+
+// projectInput reads create and edit project body
+func projectInput(c *gin.Context) (controller.ProjectInput, error) {
+	var input controller.ProjectInput
+
+	var bodyData map[string]interface{}
+	err := extractBody(c, &bodyData)
+	if err != nil {
+		return input, controller.ErrInvalidBodyParams
+	}
+
+	input.Name, _ = bodyData["name"].(string)
+	input.Description, _ = bodyData["description"].(string)
+	input.Color, _ = bodyData["color"].(string)
+	input.Archived, _ = bodyData["archived"].(bool)
+
+	return input, nil
+}
+
+// GetProjectList godoc
+// @ID get-project-list
+// @Security ApiKeyAuth
+// @Summary      Get project list
+// @Description  Get projects of the user ordered by name
+// @Tags         project
+// @Accept       json
+// @Produce      json
+// @Param archived query bool false "only archived or only active projects"
+// @Param X-Workspace header int false "workspace id, own workspace of the user by default"
+// @Success 200 {array} model.Project
+// @Failure      400  {object}  http.StatusBadRequest
+// @Failure      500  {object}  http.StatusInternalServerError
+
+// @Router       /project [get]
+func (h *Handler) GetProjectList(c *gin.Context) {
+	_, workspaceId, err := h.authorizeWorkspace(c, controller.ActionView)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	if config.DebugLog() {
+		log.Println("requesting project list", fullUrl(c))
+	}
+
+	res, err := h.ctrl.GetProjectList(workspaceId, c.Query("archived"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+// CreateProject godoc
+// @ID create-project
+// @Security ApiKeyAuth
+// @Summary      Create project
+// @Description  Create project, name is unique per user
+// @Tags         project
+// @Accept       json
+// @Produce      json
+// @Param input body todo.Model true "project input name,description,color,archived"
+// @Param X-Workspace header int false "workspace id, own workspace of the user by default"
+// @Success 201 {object} model.Project
+// @Failure      400  {object}  http.StatusBadRequest
+// @Failure      403  {object}  http.StatusForbidden
+// @Failure      422  {object}  http.StatusUnprocessableEntity
+// @Failure      500  {object}  http.StatusInternalServerError
+
+// @Router       /project [post]
+func (h *Handler) CreateProject(c *gin.Context) {
+	_, workspaceId, err := h.authorizeWorkspace(c, controller.ActionEdit)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	input, err := projectInput(c)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	if config.DebugLog() {
+		log.Println("requesting project create", fullUrl(c))
+	}
+
+	project, err := h.ctrl.CreateProject(workspaceId, input)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, project)
+}
+
+// GetProject godoc
+// @ID get-project
+// @Security ApiKeyAuth
+// @Summary      Get project
+// @Description  Get project
+// @Tags         project
+// @Accept       json
+// @Produce      json
+// @Param id path int true "project id"
+// @Param X-Workspace header int false "workspace id, own workspace of the user by default"
+// @Success 200 {object} model.Project
+// @Failure      400  {object}  http.StatusBadRequest
+// @Failure      404  {object}  http.StatusNotFound
+// @Failure      500  {object}  http.StatusInternalServerError
+
+// @Router       /project/{id} [get]
+func (h *Handler) GetProject(c *gin.Context) {
+	_, workspaceId, err := h.authorizeWorkspace(c, controller.ActionView)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	id, err := controller.ParseProjectId(c.Param("id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	if config.DebugLog() {
+		log.Println("requesting project by id", fullUrl(c))
+	}
+
+	res, err := h.ctrl.GetProject(workspaceId, id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+// EditProject godoc
+// @ID edit-project
+// @Security ApiKeyAuth
+// @Summary      Edit project
+// @Description  Edit project, archive it by archived true
+// @Tags         project
+// @Accept       json
+// @Produce      json
+// @Param id path int true "project id"
+// @Param input body todo.Model true "project input name,description,color,archived"
+// @Param X-Workspace header int false "workspace id, own workspace of the user by default"
+// @Success	200 {object}
+// @Failure      400  {object}  http.StatusBadRequest
+// @Failure      403  {object}  http.StatusForbidden
+// @Failure      404  {object}  http.StatusNotFound
+// @Failure      422  {object}  http.StatusUnprocessableEntity
+// @Failure      500  {object}  http.StatusInternalServerError
+
+// @Router       /project/{id} [put]
+func (h *Handler) EditProject(c *gin.Context) {
+	_, workspaceId, err := h.authorizeWorkspace(c, controller.ActionEdit)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	id, err := controller.ParseProjectId(c.Param("id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	input, err := projectInput(c)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	if config.DebugLog() {
+		log.Println("requesting project edit", fullUrl(c))
+	}
+
+	err = h.ctrl.EditProject(workspaceId, id, input)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": true,
+	})
+}
+
+// DeleteProject godoc
+// @ID delete-project
+// @Security ApiKeyAuth
+// @Summary      Delete project
+// @Description  Delete project, its tasks are kept without project
+// @Tags         project
+// @Accept       json
+// @Produce      json
+// @Param id path int true "project id"
+// @Param X-Workspace header int false "workspace id, own workspace of the user by default"
+// @Success	200 {object}
+// @Failure      400  {object}  http.StatusBadRequest
+// @Failure      403  {object}  http.StatusForbidden
+// @Failure      404  {object}  http.StatusNotFound
+// @Failure      500  {object}  http.StatusInternalServerError
+
+// @Router       /project/{id} [delete]
+func (h *Handler) DeleteProject(c *gin.Context) {
+	_, workspaceId, err := h.authorizeWorkspace(c, controller.ActionEdit)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	id, err := controller.ParseProjectId(c.Param("id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	if config.DebugLog() {
+		log.Println("requesting project delete", fullUrl(c))
+	}
+
+	err = h.ctrl.DeleteProject(workspaceId, id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": true,
+	})
+}
+
+// GetProjectTaskList godoc
+// @ID get-project-task-list
+// @Security ApiKeyAuth
+// @Summary      Get project task list
+// @Description  Get task list page of the project, takes the same query params as task list
+// @Tags         project
+// @Accept       json
+// @Produce      json
+// @Param id path int true "project id"
+// @Param status query []string false "filter by status, repeat or comma separate for several" collectionFormat(multi)
+// @Param sort query string false "id, name, status, created_at, updated_at, due_at, priority or position, prefix with - for descending" default(id)
+// @Param limit query int false "page size, max 500" default(50)
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param X-Workspace header int false "workspace id, own workspace of the user by default"
+// @Success 200 {object} model.TaskPage
+// @Failure      400  {object}  http.StatusBadRequest
+// @Failure      404  {object}  http.StatusNotFound
+// @Failure      500  {object}  http.StatusInternalServerError
+
+// @Router       /project/{id}/task [get]
+func (h *Handler) GetProjectTaskList(c *gin.Context) {
+	userId, workspaceId, err := h.authorizeWorkspace(c, controller.ActionView)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	id, err := controller.ParseProjectId(c.Param("id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	if config.DebugLog() {
+		log.Println("requesting project task list", fullUrl(c))
+	}
+
+	res, err := h.ctrl.GetProjectTaskList(workspaceId, id, taskListQuery(c, userId))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, res)
+}
@@ -0,0 +1,106 @@
+package api
+
+import (
+	"encoding/csv"
+	"log"
+	"net/http"
+	"strconv"
+
+	"todo/internal/config"
+	"todo/internal/controller"
+	"todo/internal/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetReportSummary godoc
+// @ID get-report-summary
+// @Security ApiKeyAuth
+// @Summary      Get report summary
+// @Description  Get counts per status, tasks completed per period, cycle time from in_progress to done and cumulative flow of the date range
+// @Tags         report
+// @Accept       json
+// @Produce      json
+// @Produce      text/csv
+// @Param from query string false "first date YYYY-MM-DD, 30 days before to by default"
+// @Param to query string false "last date YYYY-MM-DD, today by default"
+// @Param interval query string false "day or week, weeks start on Monday" default(day)
+// @Param timezone query string false "IANA time zone of the dates" default(UTC)
+// @Param format query string false "json or csv, csv has a row of completed tasks and flow per period" default(json)
+// @Param X-Workspace header int false "workspace id, own workspace of the user by default"
+// @Success 200 {object} model.ReportSummary
+// @Failure      400  {object}  http.StatusBadRequest
+// @Failure      404  {object}  http.StatusNotFound
+// @Failure      500  {object}  http.StatusInternalServerError
+
+// @Router       /report/summary [get]
+func (h *Handler) GetReportSummary(c *gin.Context) {
+	_, workspaceId, err := h.authorizeWorkspace(c, controller.ActionView)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "csv" {
+		respondError(c, controller.ErrInvalidReportFormat)
+		return
+	}
+
+	if config.DebugLog() {
+		log.Println("requesting report summary", fullUrl(c))
+	}
+
+	summary, err := h.ctrl.GetReportSummary(workspaceId, controller.ReportQuery{
+		From:     c.Query("from"),
+		To:       c.Query("to"),
+		Interval: c.Query("interval"),
+		Timezone: c.Query("timezone"),
+	})
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	if format == "json" {
+		c.JSON(http.StatusOK, summary)
+		return
+	}
+
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", `attachment; filename="summary.csv"`)
+	c.Status(http.StatusOK)
+
+	err = writeReportCsv(csv.NewWriter(c.Writer), summary)
+	if err != nil {
+		log.Printf("request %s failed: %v", requestId(c), err)
+	}
+}
+
+// writeReportCsv writes a row per period: its start date, completed tasks and then flow of every status
+func writeReportCsv(w *csv.Writer, summary *model.ReportSummary) error {
+	header := []string{"date", "completed"}
+	for _, count := range summary.StatusCounts {
+		header = append(header, count.Status)
+	}
+
+	err := w.Write(header)
+	if err != nil {
+		return err
+	}
+
+	for _, period := range summary.Periods {
+		row := []string{period.Start.Format("2006-01-02"), strconv.Itoa(period.Completed)}
+		for _, count := range summary.StatusCounts {
+			row = append(row, strconv.Itoa(period.Flow[count.Status]))
+		}
+
+		err = w.Write(row)
+		if err != nil {
+			return err
+		}
+	}
+
+	w.Flush()
+	return w.Error()
+}
@@ -5,9 +5,22 @@ import (
 	"io/ioutil"
 	"net/http"
 
+	"todo/internal/controller"
+
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
+const requestIdHeader = "X-Request-Id"
+
+type Handler struct {
+	ctrl *controller.Controller
+}
+
+func NewHandler(ctrl *controller.Controller) *Handler {
+	return &Handler{ctrl: ctrl}
+}
+
 func extractBody(c *gin.Context, data *map[string]interface{}) error {
 	body := c.Request.Body
 	value, err := ioutil.ReadAll(body)
@@ -26,3 +39,22 @@ func fullUrl(c *gin.Context) string {
 func RootIndex(c *gin.Context) {
 	c.String(http.StatusOK, "it works")
 }
+
+// RequestId middleware takes request id from X-Request-Id header or generates a new one
+// and echoes it back, the same id is put into error responses
+func RequestId() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(requestIdHeader)
+		if id == "" {
+			id = uuid.NewString()
+		}
+
+		c.Set(requestIdHeader, id)
+		c.Header(requestIdHeader, id)
+		c.Next()
+	}
+}
+
+func requestId(c *gin.Context) string {
+	return c.GetString(requestIdHeader)
+}
@@ -3,6 +3,8 @@ package api
 import (
 	"log"
 	"net/http"
+	"strconv"
+	"strings"
 
 	_ "todo/docs"
 
@@ -12,28 +14,133 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+// taskInput takes task fields of create and edit body, missing ones are left empty
+func taskInput(bodyData map[string]interface{}) controller.TaskInput {
+	var input controller.TaskInput
+
+	input.Name, _ = bodyData["name"].(string)
+	input.Description, _ = bodyData["description"].(string)
+	input.Priority, _ = bodyData["priority"].(string)
+	input.DueAt, _ = bodyData["due_at"].(string)
+	input.DueTimezone, _ = bodyData["due_timezone"].(string)
+	input.ParentId = bodyNumber(bodyData["parent_id"])
+	input.ProjectId = bodyNumber(bodyData["project_id"])
+	input.Recurrence, _ = bodyData["recurrence"].(string)
+	input.Estimate = bodyNumber(bodyData["estimate_minutes"])
+
+	return input
+}
+
+// taskEditInput takes task fields of edit body, missing ones are left nil and keep their value
+func taskEditInput(bodyData map[string]interface{}) controller.TaskEditInput {
+	input := taskInput(bodyData)
+
+	present := func(key, value string) *string {
+		if _, ok := bodyData[key]; !ok {
+			return nil
+		}
+		return &value
+	}
+
+	return controller.TaskEditInput{
+		Name:        present("name", input.Name),
+		Description: present("description", input.Description),
+		Priority:    present("priority", input.Priority),
+		DueAt:       present("due_at", input.DueAt),
+		DueTimezone: present("due_timezone", input.DueTimezone),
+		ParentId:    present("parent_id", input.ParentId),
+		ProjectId:   present("project_id", input.ProjectId),
+		Recurrence:  present("recurrence", input.Recurrence),
+		Estimate:    present("estimate_minutes", input.Estimate),
+	}
+}
+
+// bodyNumber accepts an id or another whole number given either as JSON number or string,
+// the controller validates it
+func bodyNumber(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return ""
+}
+
+// taskListQuery reads query params of task list, project is left to the caller.
+// userId is the requesting user, assignee=me stands for userId
+func taskListQuery(c *gin.Context, userId uint16) controller.TaskListQuery {
+	var statuses []string
+	for _, status := range c.QueryArray("status") {
+		statuses = append(statuses, strings.Split(status, ",")...)
+	}
+
+	var labels []string
+	for _, label := range c.QueryArray("label") {
+		labels = append(labels, strings.Split(label, ",")...)
+	}
+
+	return controller.TaskListQuery{
+		Statuses:   statuses,
+		Search:     c.Query("q"),
+		DueBefore:  c.Query("due_before"),
+		Overdue:    c.Query("overdue"),
+		Blocked:    c.Query("blocked"),
+		Labels:     labels,
+		LabelMatch: c.Query("label_match"),
+		Assignee:   c.Query("assignee"),
+		Me:         userId,
+		Tree:       c.Query("tree"),
+		Sort:       c.Query("sort"),
+		Limit:      c.Query("limit"),
+		Cursor:     c.Query("cursor"),
+	}
+}
+
 // GetTaskOffset godoc
 // @ID get-task-list
+// @Security ApiKeyAuth
 // @Summary      Get task list
-// @Description  Get task list
+// @Description  Get task list page, use next_cursor of the response as cursor to get the next page
 // @Tags         task
 // @Accept       json
 // @Produce      json
-// @Success 200 {array} model.Task
+// @Param status query []string false "filter by status, repeat or comma separate for several" collectionFormat(multi)
+// @Param q query string false "search substring in name or description"
+// @Param due_before query string false "only tasks due before the time, RFC 3339"
+// @Param overdue query bool false "only tasks past their due date and not done"
+// @Param blocked query bool false "only tasks with a blocker which is not done"
+// @Param label query []string false "filter by label name, repeat or comma separate for several" collectionFormat(multi)
+// @Param label_match query string false "any or all of the labels" default(any)
+// @Param tree query bool false "filter top level tasks only, each one with its descendants in children"
+// @Param project query int false "only tasks of the project"
+// @Param assignee query string false "only tasks assigned to the user id, me for the requesting user"
+// @Param sort query string false "id, name, status, created_at, updated_at, due_at, priority or position, prefix with - for descending" default(id)
+// @Param limit query int false "page size, max 500" default(50)
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param X-Workspace header int false "workspace id, own workspace of the user by default"
+// @Success 200 {object} model.TaskPage
 // @Failure      400  {object}  http.StatusBadRequest
 // @Failure      500  {object}  http.StatusInternalServerError
 
 // @Router       /task [get]
-func GetTaskList(c *gin.Context) {
-	status := c.Query("status")
+func (h *Handler) GetTaskList(c *gin.Context) {
+	userId, workspaceId, err := h.authorizeWorkspace(c, controller.ActionView)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	query := taskListQuery(c, userId)
+	query.Project = c.Query("project")
 
 	if config.DebugLog() {
 		log.Println("requesting task offset", fullUrl(c))
 	}
 
-	res, err := controller.GetTaskList(status)
+	res, err := h.ctrl.GetTaskList(workspaceId, query)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, err.Error())
+		respondError(c, err)
 		return
 	}
 
@@ -52,14 +159,14 @@ func GetTaskList(c *gin.Context) {
 // @Failure      500  {object}  http.StatusInternalServerError
 
 // @Router       /task/status [get]
-func GetTaskStatusList(c *gin.Context) {
+func (h *Handler) GetTaskStatusList(c *gin.Context) {
 	if config.DebugLog() {
 		log.Println("requesting task status list", fullUrl(c))
 	}
 
-	res, err := controller.GetTaskStatusList()
+	res, err := h.ctrl.GetTaskStatusList()
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, err.Error())
+		respondError(c, err)
 		return
 	}
 
@@ -74,49 +181,41 @@ func GetTaskStatusList(c *gin.Context) {
 // @Tags         task
 // @Accept       json
 // @Produce      json
-// @Param input body todo.Model true "task input name,description"
+// @Param input body todo.Model true "task input name,description,priority,due_at,due_timezone,parent_id,project_id,recurrence,estimate_minutes"
+// @Param X-Workspace header int false "workspace id, own workspace of the user by default"
 // @Success 201 {object} model.Task
 // @Failure      400  {object}  http.StatusBadRequest
+// @Failure      403  {object}  http.StatusForbidden
 // @Failure      500  {object}  http.StatusInternalServerError
 
 // @Router       /task [post]
-func CreateTask(c *gin.Context) {
-	_, err := authorizeToken(c)
+func (h *Handler) CreateTask(c *gin.Context) {
+	userId, workspaceId, err := h.authorizeWorkspace(c, controller.ActionEdit)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, "invalid token")
+		respondError(c, err)
 		return
 	}
 
 	var bodyData map[string]interface{}
 	err = extractBody(c, &bodyData)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, "invalid body param(s)")
+		respondError(c, controller.ErrInvalidBodyParams)
 		return
 	}
 
-	name := bodyData["name"].(string)
-	var description string
-	if bodyData["description"] != nil {
-		description = bodyData["description"].(string)
-	}
-
 	if config.DebugLog() {
 		log.Println("requesting task create", fullUrl(c))
 	}
 
-	id, err := controller.CreateTask(name, description)
+	task, err := h.ctrl.CreateTask(workspaceId, userId, taskInput(bodyData))
 	if err != nil {
-		errMsg := err.Error()
-		if errMsg == "create_task_failure_name_is_required" {
-			c.JSON(http.StatusUnprocessableEntity, errMsg)
-			return
-		}
-		c.JSON(http.StatusInternalServerError, errMsg)
+		respondError(c, err)
 		return
 	}
 
 	data := gin.H{
-		"id": id,
+		"id":        task.Id,
+		"public_id": task.PublicId,
 	}
 
 	c.JSON(http.StatusCreated, data)
@@ -124,31 +223,168 @@ func CreateTask(c *gin.Context) {
 
 // GetTask godoc
 // @ID get-task
+// @Security ApiKeyAuth
 // @Summary      Get task
-// @Description  Get task
+// @Description  Get task with its blockers and dependents
 // @Tags         task
 // @Accept       json
 // @Produce      json
-// @Param id path int true "task id"
+// @Param id path string true "task id or public id"
+// @Param X-Workspace header int false "workspace id, own workspace of the user by default"
 // @Success	200 {object} model.Task
 // @Failure      400  {object}  http.StatusBadRequest
+// @Failure      404  {object}  http.StatusNotFound
 // @Failure      500  {object}  http.StatusInternalServerError
 
 // @Router       /task/{id} [get]
-func GetTask(c *gin.Context) {
+func (h *Handler) GetTask(c *gin.Context) {
+	_, workspaceId, err := h.authorizeWorkspace(c, controller.ActionView)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
 	if config.DebugLog() {
 		log.Println("requesting task by id", fullUrl(c))
 	}
 
-	id, err := controller.StringToUint16(c.Param("id"))
+	id, err := h.ctrl.ResolveTaskId(workspaceId, c.Param("id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	res, err := h.ctrl.GetTask(workspaceId, id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+// GetTaskHistory godoc
+// @ID get-task-history
+// @Security ApiKeyAuth
+// @Summary      Get task status history
+// @Description  Get task status changes from the oldest one, the first change has empty from_status
+// @Tags         task
+// @Accept       json
+// @Produce      json
+// @Param id path string true "task id or public id"
+// @Param X-Workspace header int false "workspace id, own workspace of the user by default"
+// @Success 200 {array} model.TaskStatusChange
+// @Failure      400  {object}  http.StatusBadRequest
+// @Failure      404  {object}  http.StatusNotFound
+// @Failure      500  {object}  http.StatusInternalServerError
+
+// @Router       /task/{id}/history [get]
+func (h *Handler) GetTaskHistory(c *gin.Context) {
+	_, workspaceId, err := h.authorizeWorkspace(c, controller.ActionView)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, err.Error())
+		respondError(c, err)
 		return
 	}
 
-	res, err := controller.GetTask(id)
+	if config.DebugLog() {
+		log.Println("requesting task history", fullUrl(c))
+	}
+
+	id, err := h.ctrl.ResolveTaskId(workspaceId, c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, err.Error())
+		respondError(c, err)
+		return
+	}
+
+	res, err := h.ctrl.GetTaskHistory(workspaceId, id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+// GetTaskOccurrences godoc
+// @ID get-task-occurrences
+// @Security ApiKeyAuth
+// @Summary      Preview task occurrences
+// @Description  Get due dates of the next occurrences of a recurring task, the ones DoneTask would create one by one, missed occurrences are skipped
+// @Tags         task
+// @Accept       json
+// @Produce      json
+// @Param id path string true "task id or public id"
+// @Param count query int false "number of occurrences, max 100" default(5)
+// @Param X-Workspace header int false "workspace id, own workspace of the user by default"
+// @Success 200 {array} string
+// @Failure      400  {object}  http.StatusBadRequest
+// @Failure      404  {object}  http.StatusNotFound
+// @Failure      422  {object}  http.StatusUnprocessableEntity
+// @Failure      500  {object}  http.StatusInternalServerError
+
+// @Router       /task/{id}/occurrences [get]
+func (h *Handler) GetTaskOccurrences(c *gin.Context) {
+	_, workspaceId, err := h.authorizeWorkspace(c, controller.ActionView)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	if config.DebugLog() {
+		log.Println("requesting task occurrences", fullUrl(c))
+	}
+
+	id, err := h.ctrl.ResolveTaskId(workspaceId, c.Param("id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	res, err := h.ctrl.GetTaskOccurrences(workspaceId, id, c.Query("count"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+// GetTaskChildren godoc
+// @ID get-task-children
+// @Security ApiKeyAuth
+// @Summary      Get task children
+// @Description  Get not deleted direct children of the task ordered by position
+// @Tags         task
+// @Accept       json
+// @Produce      json
+// @Param id path string true "task id or public id"
+// @Param X-Workspace header int false "workspace id, own workspace of the user by default"
+// @Success 200 {array} model.Task
+// @Failure      400  {object}  http.StatusBadRequest
+// @Failure      404  {object}  http.StatusNotFound
+// @Failure      500  {object}  http.StatusInternalServerError
+
+// @Router       /task/{id}/children [get]
+func (h *Handler) GetTaskChildren(c *gin.Context) {
+	_, workspaceId, err := h.authorizeWorkspace(c, controller.ActionView)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	if config.DebugLog() {
+		log.Println("requesting task children", fullUrl(c))
+	}
+
+	id, err := h.ctrl.ResolveTaskId(workspaceId, c.Param("id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	res, err := h.ctrl.GetTaskChildren(workspaceId, id)
+	if err != nil {
+		respondError(c, err)
 		return
 	}
 
@@ -159,55 +395,47 @@ func GetTask(c *gin.Context) {
 // @ID edit-task
 // @Security ApiKeyAuth
 // @Summary      Edit task
-// @Description  Edit task
+// @Description  Edit task, fields left out of the body keep their value and null clears optional ones
 // @Tags         task
 // @Accept       json
 // @Produce      json
-// @Param id path int true "task id"
-// @Param input body todo.Model true "task input name,description"
+// @Param id path string true "task id or public id"
+// @Param input body todo.Model true "task input name,description,priority,due_at,due_timezone,parent_id,project_id,recurrence,estimate_minutes"
+// @Param X-Workspace header int false "workspace id, own workspace of the user by default"
 // @Success	200 {object}
 // @Failure      400  {object}  http.StatusBadRequest
+// @Failure      403  {object}  http.StatusForbidden
+// @Failure      404  {object}  http.StatusNotFound
 // @Failure      500  {object}  http.StatusInternalServerError
 
 // @Router       /task/{id} [put]
-func EditTask(c *gin.Context) {
-	_, err := authorizeToken(c)
+func (h *Handler) EditTask(c *gin.Context) {
+	_, workspaceId, err := h.authorizeWorkspace(c, controller.ActionEdit)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, "invalid token")
+		respondError(c, err)
 		return
 	}
 
-	id, err := controller.StringToUint16(c.Param("id"))
+	id, err := h.ctrl.ResolveTaskId(workspaceId, c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, err.Error())
+		respondError(c, err)
 		return
 	}
 
 	var bodyData map[string]interface{}
 	err = extractBody(c, &bodyData)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, "invalid body param(s)")
+		respondError(c, controller.ErrInvalidBodyParams)
 		return
 	}
 
-	name := bodyData["name"].(string)
-	var description string
-	if bodyData["description"] != nil {
-		description = bodyData["description"].(string)
-	}
-
 	if config.DebugLog() {
 		log.Println("requesting task edit", fullUrl(c))
 	}
 
-	err = controller.EditTask(id, name, description)
+	err = h.ctrl.EditTask(workspaceId, id, taskEditInput(bodyData))
 	if err != nil {
-		errMsg := err.Error()
-		if errMsg == "edit_task_failure_name_is_required" {
-			c.JSON(http.StatusUnprocessableEntity, errMsg)
-			return
-		}
-		c.JSON(http.StatusInternalServerError, errMsg)
+		respondError(c, err)
 		return
 	}
 
@@ -216,30 +444,92 @@ func EditTask(c *gin.Context) {
 	})
 }
 
+// MoveTask godoc
+// @ID move-task
+// @Security ApiKeyAuth
+// @Summary      Move task
+// @Description  Move task into status column between after and before neighbours, without them to the end of the column
+// @Tags         task
+// @Accept       json
+// @Produce      json
+// @Param id path string true "task id or public id"
+// @Param input body todo.Model true "status, after, before task ids or public ids, all optional"
+// @Param X-Workspace header int false "workspace id, own workspace of the user by default"
+// @Success 200 {object} model.Task
+// @Failure      400  {object}  http.StatusBadRequest
+// @Failure      403  {object}  http.StatusForbidden
+// @Failure      404  {object}  http.StatusNotFound
+// @Failure      409  {object}  http.StatusConflict
+// @Failure      422  {object}  http.StatusUnprocessableEntity
+// @Failure      500  {object}  http.StatusInternalServerError
+
+// @Router       /task/{id}/move [put]
+func (h *Handler) MoveTask(c *gin.Context) {
+	userId, workspaceId, err := h.authorizeWorkspace(c, controller.ActionEdit)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	id, err := h.ctrl.ResolveTaskId(workspaceId, c.Param("id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	var bodyData map[string]interface{}
+	err = extractBody(c, &bodyData)
+	if err != nil {
+		respondError(c, controller.ErrInvalidBodyParams)
+		return
+	}
+
+	var input controller.TaskMoveInput
+	input.Status, _ = bodyData["status"].(string)
+	input.After = bodyNumber(bodyData["after"])
+	input.Before = bodyNumber(bodyData["before"])
+
+	if config.DebugLog() {
+		log.Println("requesting task move", fullUrl(c))
+	}
+
+	task, err := h.ctrl.MoveTask(workspaceId, id, userId, input)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, task)
+}
+
 // StartTaskProgress godoc
 // @ID start-task-progress
 // @Security ApiKeyAuth
 // @Summary      Start task progress
-// @Description  Start task progress
+// @Description  Start task progress, task can not be started until all its blockers are done
 // @Tags         task
 // @Accept       json
 // @Produce      json
-// @Param id path int true "task id" default(1)
+// @Param id path string true "task id or public id" default(1)
+// @Param X-Workspace header int false "workspace id, own workspace of the user by default"
 // @Success	200 {object}
 // @Failure      400  {object}  http.StatusBadRequest
+// @Failure      403  {object}  http.StatusForbidden
+// @Failure      404  {object}  http.StatusNotFound
+// @Failure      409  {object}  http.StatusConflict
 // @Failure      500  {object}  http.StatusInternalServerError
 
 // @Router       /task/{id}/start_progress [put]
-func StartTaskProgress(c *gin.Context) {
-	_, err := authorizeToken(c)
+func (h *Handler) StartTaskProgress(c *gin.Context) {
+	userId, workspaceId, err := h.authorizeWorkspace(c, controller.ActionEdit)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, "invalid token")
+		respondError(c, err)
 		return
 	}
 
-	id, err := controller.StringToUint16(c.Param("id"))
+	id, err := h.ctrl.ResolveTaskId(workspaceId, c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, err.Error())
+		respondError(c, err)
 		return
 	}
 
@@ -247,9 +537,9 @@ func StartTaskProgress(c *gin.Context) {
 		log.Println("requesting task start progress", fullUrl(c))
 	}
 
-	err = controller.StartTaskProgress(id)
+	err = h.ctrl.StartTaskProgress(workspaceId, id, userId)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, err.Error())
+		respondError(c, err)
 		return
 	}
 
@@ -266,22 +556,26 @@ func StartTaskProgress(c *gin.Context) {
 // @Tags         task
 // @Accept       json
 // @Produce      json
-// @Param id path int true "task id" default(1)
+// @Param id path string true "task id or public id" default(1)
+// @Param X-Workspace header int false "workspace id, own workspace of the user by default"
 // @Success	200 {object}
 // @Failure      400  {object}  http.StatusBadRequest
+// @Failure      403  {object}  http.StatusForbidden
+// @Failure      404  {object}  http.StatusNotFound
+// @Failure      409  {object}  http.StatusConflict
 // @Failure      500  {object}  http.StatusInternalServerError
 
 // @Router       /task/{id}/pause [put]
-func PauseTask(c *gin.Context) {
-	_, err := authorizeToken(c)
+func (h *Handler) PauseTask(c *gin.Context) {
+	userId, workspaceId, err := h.authorizeWorkspace(c, controller.ActionEdit)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, "invalid token")
+		respondError(c, err)
 		return
 	}
 
-	id, err := controller.StringToUint16(c.Param("id"))
+	id, err := h.ctrl.ResolveTaskId(workspaceId, c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, err.Error())
+		respondError(c, err)
 		return
 	}
 
@@ -289,9 +583,9 @@ func PauseTask(c *gin.Context) {
 		log.Println("requesting task pause", fullUrl(c))
 	}
 
-	err = controller.PauseTask(id)
+	err = h.ctrl.PauseTask(workspaceId, id, userId)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, err.Error())
+		respondError(c, err)
 		return
 	}
 
@@ -308,22 +602,26 @@ func PauseTask(c *gin.Context) {
 // @Tags         task
 // @Accept       json
 // @Produce      json
-// @Param id path int true "task id" default(1)
+// @Param id path string true "task id or public id" default(1)
+// @Param X-Workspace header int false "workspace id, own workspace of the user by default"
 // @Success	200 {object}
 // @Failure      400  {object}  http.StatusBadRequest
+// @Failure      403  {object}  http.StatusForbidden
+// @Failure      404  {object}  http.StatusNotFound
+// @Failure      409  {object}  http.StatusConflict
 // @Failure      500  {object}  http.StatusInternalServerError
 
 // @Router       /task/{id}/done [put]
-func DoneTask(c *gin.Context) {
-	_, err := authorizeToken(c)
+func (h *Handler) DoneTask(c *gin.Context) {
+	userId, workspaceId, err := h.authorizeWorkspace(c, controller.ActionEdit)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, "invalid token")
+		respondError(c, err)
 		return
 	}
 
-	id, err := controller.StringToUint16(c.Param("id"))
+	id, err := h.ctrl.ResolveTaskId(workspaceId, c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, err.Error())
+		respondError(c, err)
 		return
 	}
 
@@ -331,9 +629,9 @@ func DoneTask(c *gin.Context) {
 		log.Println("requesting task done", fullUrl(c))
 	}
 
-	err = controller.DoneTask(id)
+	err = h.ctrl.DoneTask(workspaceId, id, userId)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, err.Error())
+		respondError(c, err)
 		return
 	}
 
@@ -346,26 +644,30 @@ func DoneTask(c *gin.Context) {
 // @ID delete-task
 // @Security ApiKeyAuth
 // @Summary      Delete task
-// @Description  Delete task
+// @Description  Move task with its descendants to trash
 // @Tags         task
 // @Accept       json
 // @Produce      json
-// @Param id path int true "task id" default(1)
+// @Param id path string true "task id or public id" default(1)
+// @Param X-Workspace header int false "workspace id, own workspace of the user by default"
 // @Success	200 {object}
 // @Failure      400  {object}  http.StatusBadRequest
+// @Failure      403  {object}  http.StatusForbidden
+// @Failure      404  {object}  http.StatusNotFound
+// @Failure      409  {object}  http.StatusConflict
 // @Failure      500  {object}  http.StatusInternalServerError
 
 // @Router       /task/{id} [delete]
-func DeleteTask(c *gin.Context) {
-	_, err := authorizeToken(c)
+func (h *Handler) DeleteTask(c *gin.Context) {
+	userId, workspaceId, err := h.authorizeWorkspace(c, controller.ActionEdit)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, "invalid token")
+		respondError(c, err)
 		return
 	}
 
-	id, err := controller.StringToUint16(c.Param("id"))
+	id, err := h.ctrl.ResolveTaskId(workspaceId, c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, err.Error())
+		respondError(c, err)
 		return
 	}
 
@@ -373,9 +675,9 @@ func DeleteTask(c *gin.Context) {
 		log.Println("requesting task delete", fullUrl(c))
 	}
 
-	err = controller.DeleteTask(id)
+	err = h.ctrl.DeleteTask(workspaceId, id, userId)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, err.Error())
+		respondError(c, err)
 		return
 	}
 
@@ -388,26 +690,30 @@ func DeleteTask(c *gin.Context) {
 // @ID restore-task
 // @Security ApiKeyAuth
 // @Summary      Restore task
-// @Description  Restore task
+// @Description  Restore task with descendants deleted together with it
 // @Tags         task
 // @Accept       json
 // @Produce      json
-// @Param id path int true "task id" default(1)
+// @Param id path string true "task id or public id" default(1)
+// @Param X-Workspace header int false "workspace id, own workspace of the user by default"
 // @Success	200 {object}
 // @Failure      400  {object}  http.StatusBadRequest
+// @Failure      403  {object}  http.StatusForbidden
+// @Failure      404  {object}  http.StatusNotFound
+// @Failure      409  {object}  http.StatusConflict
 // @Failure      500  {object}  http.StatusInternalServerError
 
 // @Router       /task/{id}/restore [put]
-func RestoreTask(c *gin.Context) {
-	_, err := authorizeToken(c)
+func (h *Handler) RestoreTask(c *gin.Context) {
+	userId, workspaceId, err := h.authorizeWorkspace(c, controller.ActionEdit)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, "invalid token")
+		respondError(c, err)
 		return
 	}
 
-	id, err := controller.StringToUint16(c.Param("id"))
+	id, err := h.ctrl.ResolveTaskId(workspaceId, c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, err.Error())
+		respondError(c, err)
 		return
 	}
 
@@ -415,9 +721,9 @@ func RestoreTask(c *gin.Context) {
 		log.Println("requesting task restore", fullUrl(c))
 	}
 
-	err = controller.RestoreTask(id)
+	err = h.ctrl.RestoreTask(workspaceId, id, userId)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, err.Error())
+		respondError(c, err)
 		return
 	}
 
@@ -430,26 +736,29 @@ func RestoreTask(c *gin.Context) {
 // @ID delete-task-completely
 // @Security ApiKeyAuth
 // @Summary      Delete task completely
-// @Description  Delete task completely
+// @Description  Delete task with its descendants completely
 // @Tags         task
 // @Accept       json
 // @Produce      json
-// @Param id path int true "task id" default(1)
+// @Param id path string true "task id or public id" default(1)
+// @Param X-Workspace header int false "workspace id, own workspace of the user by default"
 // @Success	200 {object}
 // @Failure      400  {object}  http.StatusBadRequest
+// @Failure      403  {object}  http.StatusForbidden
+// @Failure      404  {object}  http.StatusNotFound
 // @Failure      500  {object}  http.StatusInternalServerError
 
 // @Router       /task/{id}/completely [delete]
-func DeleteTaskCompletely(c *gin.Context) {
-	_, err := authorizeToken(c)
+func (h *Handler) DeleteTaskCompletely(c *gin.Context) {
+	_, workspaceId, err := h.authorizeWorkspace(c, controller.ActionPurge)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, "invalid token")
+		respondError(c, err)
 		return
 	}
 
-	id, err := controller.StringToUint16(c.Param("id"))
+	id, err := h.ctrl.ResolveTaskId(workspaceId, c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, err.Error())
+		respondError(c, err)
 		return
 	}
 
@@ -457,9 +766,9 @@ func DeleteTaskCompletely(c *gin.Context) {
 		log.Println("requesting task delete competely", fullUrl(c))
 	}
 
-	err = controller.DeleteTaskCompletely(id)
+	err = h.ctrl.DeleteTaskCompletely(workspaceId, id)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, err.Error())
+		respondError(c, err)
 		return
 	}
 
@@ -472,19 +781,23 @@ func DeleteTaskCompletely(c *gin.Context) {
 // @ID free-task-trash
 // @Security ApiKeyAuth
 // @Summary      Free task trash
-// @Description  Free task trash
+// @Description  Free task trash, with project only trash of the project
 // @Tags         task
 // @Accept       json
 // @Produce      json
+// @Param project query int false "project id"
+// @Param X-Workspace header int false "workspace id, own workspace of the user by default"
 // @Success	200 {object}
 // @Failure      400  {object}  http.StatusBadRequest
+// @Failure      403  {object}  http.StatusForbidden
+// @Failure      404  {object}  http.StatusNotFound
 // @Failure      500  {object}  http.StatusInternalServerError
 
 // @Router       /task/free_trash [delete]
-func FreeTaskTrash(c *gin.Context) {
-	_, err := authorizeToken(c)
+func (h *Handler) FreeTaskTrash(c *gin.Context) {
+	_, workspaceId, err := h.authorizeWorkspace(c, controller.ActionPurge)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, "invalid token")
+		respondError(c, err)
 		return
 	}
 
@@ -492,9 +805,9 @@ func FreeTaskTrash(c *gin.Context) {
 		log.Println("requesting free task trash", fullUrl(c))
 	}
 
-	err = controller.FreeTaskTrash()
+	err = h.ctrl.FreeTaskTrash(workspaceId, c.Query("project"))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, err.Error())
+		respondError(c, err)
 		return
 	}
 
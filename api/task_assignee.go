@@ -0,0 +1,76 @@
+package api
+
+import (
+	"log"
+	"net/http"
+
+	"todo/internal/config"
+	"todo/internal/controller"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetTaskAssignees godoc
+// @ID set-task-assignees
+// @Security ApiKeyAuth
+// @Summary      Set task assignees
+// @Description  Replace assignees of the task, assignees must be the owner or members of the workspace, empty list unassigns everybody
+// @Tags         task
+// @Accept       json
+// @Produce      json
+// @Param id path string true "task id or public id"
+// @Param input body todo.Model true "assignees body, list of user ids"
+// @Param X-Workspace header int false "workspace id, own workspace of the user by default"
+// @Success 200 {array} int
+// @Failure      400  {object}  http.StatusBadRequest
+// @Failure      403  {object}  http.StatusForbidden
+// @Failure      404  {object}  http.StatusNotFound
+// @Failure      422  {object}  http.StatusUnprocessableEntity
+// @Failure      500  {object}  http.StatusInternalServerError
+
+// @Router       /task/{id}/assignees [put]
+func (h *Handler) SetTaskAssignees(c *gin.Context) {
+	_, workspaceId, err := h.authorizeWorkspace(c, controller.ActionEdit)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	taskId, err := h.ctrl.ResolveTaskId(workspaceId, c.Param("id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	var bodyData map[string]interface{}
+	err = extractBody(c, &bodyData)
+	if err != nil {
+		respondError(c, controller.ErrInvalidBodyParams)
+		return
+	}
+
+	list, ok := bodyData["assignees"].([]interface{})
+	if !ok {
+		respondError(c, controller.ErrInvalidBodyParams)
+		return
+	}
+
+	assignees := make([]string, 0, len(list))
+	for _, item := range list {
+		assignees = append(assignees, bodyNumber(item))
+	}
+
+	if config.DebugLog() {
+		log.Println("requesting task assignees set", fullUrl(c))
+	}
+
+	result, err := h.ctrl.SetTaskAssignees(workspaceId, taskId, assignees)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": result,
+	})
+}
@@ -0,0 +1,231 @@
+package api
+
+import (
+	"errors"
+	"log"
+	"mime"
+	"net/http"
+
+	"todo/internal/config"
+	"todo/internal/controller"
+
+	"github.com/gin-gonic/gin"
+)
+
+// multipartOverhead is room for boundaries and part headers of the upload form
+const multipartOverhead = 64 << 10
+
+// taskAttachmentIds resolves task and attachment of /task/:id/attachment/:attachment_id path
+func (h *Handler) taskAttachmentIds(c *gin.Context, userId uint16) (taskId, attachmentId int64, err error) {
+	taskId, err = h.ctrl.ResolveTaskId(userId, c.Param("id"))
+	if err != nil {
+		return
+	}
+
+	attachmentId, err = controller.ParseAttachmentId(c.Param("attachment_id"))
+	return
+}
+
+// GetTaskAttachments godoc
+// @ID get-task-attachments
+// @Security ApiKeyAuth
+// @Summary      Get task attachments
+// @Description  Get attachments of task from the oldest one
+// @Tags         attachment
+// @Accept       json
+// @Produce      json
+// @Param id path string true "task id or public id"
+// @Param X-Workspace header int false "workspace id, own workspace of the user by default"
+// @Success 200 {array} model.TaskAttachment
+// @Failure      400  {object}  http.StatusBadRequest
+// @Failure      404  {object}  http.StatusNotFound
+// @Failure      500  {object}  http.StatusInternalServerError
+
+// @Router       /task/{id}/attachment [get]
+func (h *Handler) GetTaskAttachments(c *gin.Context) {
+	_, workspaceId, err := h.authorizeWorkspace(c, controller.ActionView)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	taskId, err := h.ctrl.ResolveTaskId(workspaceId, c.Param("id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	if config.DebugLog() {
+		log.Println("requesting task attachments", fullUrl(c))
+	}
+
+	attachments, err := h.ctrl.GetTaskAttachments(workspaceId, taskId)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": attachments,
+	})
+}
+
+// AddTaskAttachment godoc
+// @ID add-task-attachment
+// @Security ApiKeyAuth
+// @Summary      Add task attachment
+// @Description  Upload file to task, size and content type are limited by the server
+// @Tags         attachment
+// @Accept       multipart/form-data
+// @Produce      json
+// @Param id path string true "task id or public id"
+// @Param file formData file true "attached file"
+// @Param X-Workspace header int false "workspace id, own workspace of the user by default"
+// @Success 201 {object} model.TaskAttachment
+// @Failure      400  {object}  http.StatusBadRequest
+// @Failure      403  {object}  http.StatusForbidden
+// @Failure      404  {object}  http.StatusNotFound
+// @Failure      413  {object}  http.StatusRequestEntityTooLarge
+// @Failure      415  {object}  http.StatusUnsupportedMediaType
+// @Failure      422  {object}  http.StatusUnprocessableEntity
+// @Failure      500  {object}  http.StatusInternalServerError
+
+// @Router       /task/{id}/attachment [post]
+func (h *Handler) AddTaskAttachment(c *gin.Context) {
+	userId, workspaceId, err := h.authorizeWorkspace(c, controller.ActionEdit)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	taskId, err := h.ctrl.ResolveTaskId(workspaceId, c.Param("id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, config.AttachmentMaxSize()+multipartOverhead)
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			respondError(c, controller.ErrAttachmentTooLarge)
+			return
+		}
+		respondError(c, controller.ErrAttachmentFileRequired)
+		return
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	defer file.Close()
+
+	if config.DebugLog() {
+		log.Println("requesting task attachment add", fullUrl(c))
+	}
+
+	attachment, err := h.ctrl.AddTaskAttachment(workspaceId, taskId, userId, header.Filename, header.Header.Get("Content-Type"), header.Size, file)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, attachment)
+}
+
+// DownloadTaskAttachment godoc
+// @ID download-task-attachment
+// @Security ApiKeyAuth
+// @Summary      Download task attachment
+// @Description  Download content of attachment, ETag is its sha256 checksum
+// @Tags         attachment
+// @Produce      octet-stream
+// @Param id path string true "task id or public id"
+// @Param attachment_id path int true "attachment id"
+// @Param X-Workspace header int false "workspace id, own workspace of the user by default"
+// @Success 200 {file} file
+// @Failure      400  {object}  http.StatusBadRequest
+// @Failure      404  {object}  http.StatusNotFound
+// @Failure      500  {object}  http.StatusInternalServerError
+
+// @Router       /task/{id}/attachment/{attachment_id} [get]
+func (h *Handler) DownloadTaskAttachment(c *gin.Context) {
+	_, workspaceId, err := h.authorizeWorkspace(c, controller.ActionView)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	taskId, attachmentId, err := h.taskAttachmentIds(c, workspaceId)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	if config.DebugLog() {
+		log.Println("requesting task attachment download", fullUrl(c))
+	}
+
+	attachment, content, err := h.ctrl.GetTaskAttachmentContent(workspaceId, taskId, attachmentId)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	defer content.Close()
+
+	c.DataFromReader(http.StatusOK, attachment.Size, attachment.ContentType, content, map[string]string{
+		"Content-Disposition":    mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Name}),
+		"ETag":                   `"` + attachment.Checksum + `"`,
+		"X-Content-Type-Options": "nosniff",
+	})
+}
+
+// DeleteTaskAttachment godoc
+// @ID delete-task-attachment
+// @Security ApiKeyAuth
+// @Summary      Delete task attachment
+// @Description  Delete attachment with its content
+// @Tags         attachment
+// @Accept       json
+// @Produce      json
+// @Param id path string true "task id or public id"
+// @Param attachment_id path int true "attachment id"
+// @Param X-Workspace header int false "workspace id, own workspace of the user by default"
+// @Success	200 {object}
+// @Failure      400  {object}  http.StatusBadRequest
+// @Failure      403  {object}  http.StatusForbidden
+// @Failure      404  {object}  http.StatusNotFound
+// @Failure      500  {object}  http.StatusInternalServerError
+
+// @Router       /task/{id}/attachment/{attachment_id} [delete]
+func (h *Handler) DeleteTaskAttachment(c *gin.Context) {
+	_, workspaceId, err := h.authorizeWorkspace(c, controller.ActionEdit)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	taskId, attachmentId, err := h.taskAttachmentIds(c, workspaceId)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	if config.DebugLog() {
+		log.Println("requesting task attachment delete", fullUrl(c))
+	}
+
+	err = h.ctrl.DeleteTaskAttachment(workspaceId, taskId, attachmentId)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": true,
+	})
+}
@@ -0,0 +1,284 @@
+package api
+
+import (
+	"log"
+	"net/http"
+
+	"todo/internal/config"
+	"todo/internal/controller"
+
+	"github.com/gin-gonic/gin"
+)
+
+// taskChecklistItemIds resolves task and checklist item of /task/:id/checklist/:item_id path
+func (h *Handler) taskChecklistItemIds(c *gin.Context, userId uint16) (taskId, itemId int64, err error) {
+	taskId, err = h.ctrl.ResolveTaskId(userId, c.Param("id"))
+	if err != nil {
+		return
+	}
+
+	itemId, err = controller.ParseChecklistItemId(c.Param("item_id"))
+	return
+}
+
+// GetChecklist godoc
+// @ID get-task-checklist
+// @Security ApiKeyAuth
+// @Summary      Get task checklist
+// @Description  Get checklist items of task in their order
+// @Tags         checklist
+// @Accept       json
+// @Produce      json
+// @Param id path string true "task id or public id"
+// @Param X-Workspace header int false "workspace id, own workspace of the user by default"
+// @Success 200 {array} model.ChecklistItem
+// @Failure      400  {object}  http.StatusBadRequest
+// @Failure      404  {object}  http.StatusNotFound
+// @Failure      500  {object}  http.StatusInternalServerError
+
+// @Router       /task/{id}/checklist [get]
+func (h *Handler) GetChecklist(c *gin.Context) {
+	_, workspaceId, err := h.authorizeWorkspace(c, controller.ActionView)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	taskId, err := h.ctrl.ResolveTaskId(workspaceId, c.Param("id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	if config.DebugLog() {
+		log.Println("requesting task checklist", fullUrl(c))
+	}
+
+	items, err := h.ctrl.GetChecklist(workspaceId, taskId)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": items,
+	})
+}
+
+// AddChecklistItem godoc
+// @ID add-checklist-item
+// @Security ApiKeyAuth
+// @Summary      Add checklist item
+// @Description  Add unchecked item to the end of task checklist
+// @Tags         checklist
+// @Accept       json
+// @Produce      json
+// @Param id path string true "task id or public id"
+// @Param input body todo.Model true "checklist item input body"
+// @Param X-Workspace header int false "workspace id, own workspace of the user by default"
+// @Success 201 {object} model.ChecklistItem
+// @Failure      400  {object}  http.StatusBadRequest
+// @Failure      403  {object}  http.StatusForbidden
+// @Failure      404  {object}  http.StatusNotFound
+// @Failure      422  {object}  http.StatusUnprocessableEntity
+// @Failure      500  {object}  http.StatusInternalServerError
+
+// @Router       /task/{id}/checklist [post]
+func (h *Handler) AddChecklistItem(c *gin.Context) {
+	_, workspaceId, err := h.authorizeWorkspace(c, controller.ActionEdit)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	taskId, err := h.ctrl.ResolveTaskId(workspaceId, c.Param("id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	var bodyData map[string]interface{}
+	err = extractBody(c, &bodyData)
+	if err != nil {
+		respondError(c, controller.ErrInvalidBodyParams)
+		return
+	}
+
+	text, _ := bodyData["text"].(string)
+
+	if config.DebugLog() {
+		log.Println("requesting checklist item add", fullUrl(c))
+	}
+
+	item, err := h.ctrl.AddChecklistItem(workspaceId, taskId, text)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, item)
+}
+
+// EditChecklistItem godoc
+// @ID edit-checklist-item
+// @Security ApiKeyAuth
+// @Summary      Edit checklist item
+// @Description  Change text or check and uncheck item, omitted fields are kept
+// @Tags         checklist
+// @Accept       json
+// @Produce      json
+// @Param id path string true "task id or public id"
+// @Param item_id path int true "checklist item id"
+// @Param input body todo.Model true "checklist item input body"
+// @Param X-Workspace header int false "workspace id, own workspace of the user by default"
+// @Success 200 {object} model.ChecklistItem
+// @Failure      400  {object}  http.StatusBadRequest
+// @Failure      403  {object}  http.StatusForbidden
+// @Failure      404  {object}  http.StatusNotFound
+// @Failure      422  {object}  http.StatusUnprocessableEntity
+// @Failure      500  {object}  http.StatusInternalServerError
+
+// @Router       /task/{id}/checklist/{item_id} [put]
+func (h *Handler) EditChecklistItem(c *gin.Context) {
+	_, workspaceId, err := h.authorizeWorkspace(c, controller.ActionEdit)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	taskId, itemId, err := h.taskChecklistItemIds(c, workspaceId)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	var bodyData map[string]interface{}
+	err = extractBody(c, &bodyData)
+	if err != nil {
+		respondError(c, controller.ErrInvalidBodyParams)
+		return
+	}
+
+	var input controller.ChecklistItemInput
+	if text, ok := bodyData["text"].(string); ok {
+		input.Text = &text
+	}
+	if checked, ok := bodyData["checked"].(bool); ok {
+		input.Checked = &checked
+	}
+
+	if config.DebugLog() {
+		log.Println("requesting checklist item edit", fullUrl(c))
+	}
+
+	item, err := h.ctrl.EditChecklistItem(workspaceId, taskId, itemId, input)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, item)
+}
+
+// MoveChecklistItem godoc
+// @ID move-checklist-item
+// @Security ApiKeyAuth
+// @Summary      Move checklist item
+// @Description  Put item between after and before items, without them to the end of the checklist
+// @Tags         checklist
+// @Accept       json
+// @Produce      json
+// @Param id path string true "task id or public id"
+// @Param item_id path int true "checklist item id"
+// @Param input body todo.Model true "checklist item move body"
+// @Param X-Workspace header int false "workspace id, own workspace of the user by default"
+// @Success 200 {object} model.ChecklistItem
+// @Failure      400  {object}  http.StatusBadRequest
+// @Failure      403  {object}  http.StatusForbidden
+// @Failure      404  {object}  http.StatusNotFound
+// @Failure      422  {object}  http.StatusUnprocessableEntity
+// @Failure      500  {object}  http.StatusInternalServerError
+
+// @Router       /task/{id}/checklist/{item_id}/move [put]
+func (h *Handler) MoveChecklistItem(c *gin.Context) {
+	_, workspaceId, err := h.authorizeWorkspace(c, controller.ActionEdit)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	taskId, itemId, err := h.taskChecklistItemIds(c, workspaceId)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	var bodyData map[string]interface{}
+	err = extractBody(c, &bodyData)
+	if err != nil {
+		respondError(c, controller.ErrInvalidBodyParams)
+		return
+	}
+
+	var input controller.ChecklistMoveInput
+	input.After = bodyNumber(bodyData["after"])
+	input.Before = bodyNumber(bodyData["before"])
+
+	if config.DebugLog() {
+		log.Println("requesting checklist item move", fullUrl(c))
+	}
+
+	item, err := h.ctrl.MoveChecklistItem(workspaceId, taskId, itemId, input)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, item)
+}
+
+// RemoveChecklistItem godoc
+// @ID remove-checklist-item
+// @Security ApiKeyAuth
+// @Summary      Remove checklist item
+// @Description  Remove item from task checklist
+// @Tags         checklist
+// @Accept       json
+// @Produce      json
+// @Param id path string true "task id or public id"
+// @Param item_id path int true "checklist item id"
+// @Param X-Workspace header int false "workspace id, own workspace of the user by default"
+// @Success	200 {object}
+// @Failure      400  {object}  http.StatusBadRequest
+// @Failure      403  {object}  http.StatusForbidden
+// @Failure      404  {object}  http.StatusNotFound
+// @Failure      500  {object}  http.StatusInternalServerError
+
+// @Router       /task/{id}/checklist/{item_id} [delete]
+func (h *Handler) RemoveChecklistItem(c *gin.Context) {
+	_, workspaceId, err := h.authorizeWorkspace(c, controller.ActionEdit)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	taskId, itemId, err := h.taskChecklistItemIds(c, workspaceId)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	if config.DebugLog() {
+		log.Println("requesting checklist item remove", fullUrl(c))
+	}
+
+	err = h.ctrl.RemoveChecklistItem(workspaceId, taskId, itemId)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": true,
+	})
+}
@@ -0,0 +1,221 @@
+package api
+
+import (
+	"log"
+	"net/http"
+
+	"todo/internal/config"
+	"todo/internal/controller"
+
+	"github.com/gin-gonic/gin"
+)
+
+// taskCommentIds resolves task and comment of /task/:id/comment/:comment_id path
+func (h *Handler) taskCommentIds(c *gin.Context, userId uint16) (taskId, commentId int64, err error) {
+	taskId, err = h.ctrl.ResolveTaskId(userId, c.Param("id"))
+	if err != nil {
+		return
+	}
+
+	commentId, err = controller.ParseCommentId(c.Param("comment_id"))
+	return
+}
+
+// GetTaskComments godoc
+// @ID get-task-comments
+// @Security ApiKeyAuth
+// @Summary      Get task comments
+// @Description  Get task comments page from the oldest one, use next_cursor of the response as cursor to get the next page
+// @Tags         comment
+// @Accept       json
+// @Produce      json
+// @Param id path string true "task id or public id"
+// @Param limit query int false "page size, max 200" default(50)
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param X-Workspace header int false "workspace id, own workspace of the user by default"
+// @Success 200 {object} model.TaskCommentPage
+// @Failure      400  {object}  http.StatusBadRequest
+// @Failure      404  {object}  http.StatusNotFound
+// @Failure      500  {object}  http.StatusInternalServerError
+
+// @Router       /task/{id}/comment [get]
+func (h *Handler) GetTaskComments(c *gin.Context) {
+	_, workspaceId, err := h.authorizeWorkspace(c, controller.ActionView)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	taskId, err := h.ctrl.ResolveTaskId(workspaceId, c.Param("id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	if config.DebugLog() {
+		log.Println("requesting task comments", fullUrl(c))
+	}
+
+	page, err := h.ctrl.GetTaskComments(workspaceId, taskId, c.Query("limit"), c.Query("cursor"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+// AddTaskComment godoc
+// @ID add-task-comment
+// @Security ApiKeyAuth
+// @Summary      Add task comment
+// @Description  Add comment to task, the user is its author
+// @Tags         comment
+// @Accept       json
+// @Produce      json
+// @Param id path string true "task id or public id"
+// @Param input body todo.Model true "comment input body"
+// @Param X-Workspace header int false "workspace id, own workspace of the user by default"
+// @Success 201 {object} model.TaskComment
+// @Failure      400  {object}  http.StatusBadRequest
+// @Failure      403  {object}  http.StatusForbidden
+// @Failure      404  {object}  http.StatusNotFound
+// @Failure      422  {object}  http.StatusUnprocessableEntity
+// @Failure      500  {object}  http.StatusInternalServerError
+
+// @Router       /task/{id}/comment [post]
+func (h *Handler) AddTaskComment(c *gin.Context) {
+	userId, workspaceId, err := h.authorizeWorkspace(c, controller.ActionEdit)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	taskId, err := h.ctrl.ResolveTaskId(workspaceId, c.Param("id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	var bodyData map[string]interface{}
+	err = extractBody(c, &bodyData)
+	if err != nil {
+		respondError(c, controller.ErrInvalidBodyParams)
+		return
+	}
+
+	body, _ := bodyData["body"].(string)
+
+	if config.DebugLog() {
+		log.Println("requesting task comment add", fullUrl(c))
+	}
+
+	comment, err := h.ctrl.AddTaskComment(workspaceId, taskId, userId, body)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, comment)
+}
+
+// EditTaskComment godoc
+// @ID edit-task-comment
+// @Security ApiKeyAuth
+// @Summary      Edit task comment
+// @Description  Edit own comment
+// @Tags         comment
+// @Accept       json
+// @Produce      json
+// @Param id path string true "task id or public id"
+// @Param comment_id path int true "comment id"
+// @Param input body todo.Model true "comment input body"
+// @Param X-Workspace header int false "workspace id, own workspace of the user by default"
+// @Success 200 {object} model.TaskComment
+// @Failure      400  {object}  http.StatusBadRequest
+// @Failure      403  {object}  http.StatusForbidden
+// @Failure      404  {object}  http.StatusNotFound
+// @Failure      422  {object}  http.StatusUnprocessableEntity
+// @Failure      500  {object}  http.StatusInternalServerError
+
+// @Router       /task/{id}/comment/{comment_id} [put]
+func (h *Handler) EditTaskComment(c *gin.Context) {
+	userId, workspaceId, err := h.authorizeWorkspace(c, controller.ActionEdit)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	taskId, commentId, err := h.taskCommentIds(c, workspaceId)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	var bodyData map[string]interface{}
+	err = extractBody(c, &bodyData)
+	if err != nil {
+		respondError(c, controller.ErrInvalidBodyParams)
+		return
+	}
+
+	body, _ := bodyData["body"].(string)
+
+	if config.DebugLog() {
+		log.Println("requesting task comment edit", fullUrl(c))
+	}
+
+	comment, err := h.ctrl.EditTaskComment(workspaceId, taskId, commentId, userId, body)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, comment)
+}
+
+// DeleteTaskComment godoc
+// @ID delete-task-comment
+// @Security ApiKeyAuth
+// @Summary      Delete task comment
+// @Description  Delete own comment
+// @Tags         comment
+// @Accept       json
+// @Produce      json
+// @Param id path string true "task id or public id"
+// @Param comment_id path int true "comment id"
+// @Param X-Workspace header int false "workspace id, own workspace of the user by default"
+// @Success	200 {object}
+// @Failure      400  {object}  http.StatusBadRequest
+// @Failure      403  {object}  http.StatusForbidden
+// @Failure      404  {object}  http.StatusNotFound
+// @Failure      500  {object}  http.StatusInternalServerError
+
+// @Router       /task/{id}/comment/{comment_id} [delete]
+func (h *Handler) DeleteTaskComment(c *gin.Context) {
+	userId, workspaceId, err := h.authorizeWorkspace(c, controller.ActionEdit)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	taskId, commentId, err := h.taskCommentIds(c, workspaceId)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	if config.DebugLog() {
+		log.Println("requesting task comment delete", fullUrl(c))
+	}
+
+	err = h.ctrl.DeleteTaskComment(workspaceId, taskId, commentId, userId)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": true,
+	})
+}
@@ -0,0 +1,115 @@
+package api
+
+import (
+	"log"
+	"net/http"
+
+	"todo/internal/config"
+	"todo/internal/controller"
+
+	"github.com/gin-gonic/gin"
+)
+
+// taskBlockerIds resolves task and blocker of /task/:id/blocker/:blocker_id path
+func (h *Handler) taskBlockerIds(c *gin.Context, userId uint16) (taskId, blockerId int64, err error) {
+	taskId, err = h.ctrl.ResolveTaskId(userId, c.Param("id"))
+	if err != nil {
+		return
+	}
+
+	blockerId, err = h.ctrl.ResolveBlockerId(userId, c.Param("blocker_id"))
+	return
+}
+
+// AddTaskBlocker godoc
+// @ID add-task-blocker
+// @Security ApiKeyAuth
+// @Summary      Add blocker to task
+// @Description  Make blocker_id block the task until it is done, adding it twice does nothing
+// @Tags         task
+// @Accept       json
+// @Produce      json
+// @Param id path string true "task id or public id"
+// @Param blocker_id path string true "blocker task id or public id"
+// @Param X-Workspace header int false "workspace id, own workspace of the user by default"
+// @Success	200 {object}
+// @Failure      400  {object}  http.StatusBadRequest
+// @Failure      403  {object}  http.StatusForbidden
+// @Failure      404  {object}  http.StatusNotFound
+// @Failure      422  {object}  http.StatusUnprocessableEntity
+// @Failure      500  {object}  http.StatusInternalServerError
+
+// @Router       /task/{id}/blocker/{blocker_id} [put]
+func (h *Handler) AddTaskBlocker(c *gin.Context) {
+	_, workspaceId, err := h.authorizeWorkspace(c, controller.ActionEdit)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	taskId, blockerId, err := h.taskBlockerIds(c, workspaceId)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	if config.DebugLog() {
+		log.Println("requesting task blocker add", fullUrl(c))
+	}
+
+	err = h.ctrl.AddTaskBlocker(workspaceId, taskId, blockerId)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": true,
+	})
+}
+
+// RemoveTaskBlocker godoc
+// @ID remove-task-blocker
+// @Security ApiKeyAuth
+// @Summary      Remove blocker from task
+// @Description  Remove blocker from task, removing task which does not block it does nothing
+// @Tags         task
+// @Accept       json
+// @Produce      json
+// @Param id path string true "task id or public id"
+// @Param blocker_id path string true "blocker task id or public id"
+// @Param X-Workspace header int false "workspace id, own workspace of the user by default"
+// @Success	200 {object}
+// @Failure      400  {object}  http.StatusBadRequest
+// @Failure      403  {object}  http.StatusForbidden
+// @Failure      404  {object}  http.StatusNotFound
+// @Failure      500  {object}  http.StatusInternalServerError
+
+// @Router       /task/{id}/blocker/{blocker_id} [delete]
+func (h *Handler) RemoveTaskBlocker(c *gin.Context) {
+	_, workspaceId, err := h.authorizeWorkspace(c, controller.ActionEdit)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	taskId, blockerId, err := h.taskBlockerIds(c, workspaceId)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	if config.DebugLog() {
+		log.Println("requesting task blocker remove", fullUrl(c))
+	}
+
+	err = h.ctrl.RemoveTaskBlocker(workspaceId, taskId, blockerId)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": true,
+	})
+}
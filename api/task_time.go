@@ -0,0 +1,110 @@
+package api
+
+import (
+	"log"
+	"net/http"
+
+	"todo/internal/config"
+	"todo/internal/controller"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetTaskTime godoc
+// @ID get-task-time
+// @Security ApiKeyAuth
+// @Summary      Get task time
+// @Description  Get time entries of the task from the earliest, total tracked seconds and the original estimate
+// @Tags         time
+// @Accept       json
+// @Produce      json
+// @Param id path string true "task id or public id"
+// @Param X-Workspace header int false "workspace id, own workspace of the user by default"
+// @Success 200 {object} model.TaskTime
+// @Failure      400  {object}  http.StatusBadRequest
+// @Failure      404  {object}  http.StatusNotFound
+// @Failure      500  {object}  http.StatusInternalServerError
+
+// @Router       /task/{id}/time [get]
+func (h *Handler) GetTaskTime(c *gin.Context) {
+	_, workspaceId, err := h.authorizeWorkspace(c, controller.ActionView)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	taskId, err := h.ctrl.ResolveTaskId(workspaceId, c.Param("id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	if config.DebugLog() {
+		log.Println("requesting task time", fullUrl(c))
+	}
+
+	result, err := h.ctrl.GetTaskTime(workspaceId, taskId)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// AddTimeEntry godoc
+// @ID add-time-entry
+// @Security ApiKeyAuth
+// @Summary      Add time entry
+// @Description  Add manual time entry of the user to the task
+// @Tags         time
+// @Accept       json
+// @Produce      json
+// @Param id path string true "task id or public id"
+// @Param input body todo.Model true "time entry input started_at,ended_at,note"
+// @Param X-Workspace header int false "workspace id, own workspace of the user by default"
+// @Success 201 {object} model.TimeEntry
+// @Failure      400  {object}  http.StatusBadRequest
+// @Failure      403  {object}  http.StatusForbidden
+// @Failure      404  {object}  http.StatusNotFound
+// @Failure      422  {object}  http.StatusUnprocessableEntity
+// @Failure      500  {object}  http.StatusInternalServerError
+
+// @Router       /task/{id}/time [post]
+func (h *Handler) AddTimeEntry(c *gin.Context) {
+	userId, workspaceId, err := h.authorizeWorkspace(c, controller.ActionEdit)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	taskId, err := h.ctrl.ResolveTaskId(workspaceId, c.Param("id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	var bodyData map[string]interface{}
+	err = extractBody(c, &bodyData)
+	if err != nil {
+		respondError(c, controller.ErrInvalidBodyParams)
+		return
+	}
+
+	var input controller.TimeEntryInput
+	input.StartedAt, _ = bodyData["started_at"].(string)
+	input.EndedAt, _ = bodyData["ended_at"].(string)
+	input.Note, _ = bodyData["note"].(string)
+
+	if config.DebugLog() {
+		log.Println("requesting time entry add", fullUrl(c))
+	}
+
+	entry, err := h.ctrl.AddTimeEntry(workspaceId, taskId, userId, input)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, entry)
+}
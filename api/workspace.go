@@ -0,0 +1,299 @@
+package api
+
+import (
+	"log"
+	"net/http"
+
+	"todo/internal/config"
+	"todo/internal/controller"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetWorkspaceList godoc
+// @ID get-workspace-list
+// @Security ApiKeyAuth
+// @Summary      Get workspace list
+// @Description  Get own workspace of the user first, then the ones the user is a member of, with role of the user
+// @Tags         workspace
+// @Accept       json
+// @Produce      json
+// @Success 200 {array} model.Workspace
+// @Failure      400  {object}  http.StatusBadRequest
+// @Failure      500  {object}  http.StatusInternalServerError
+
+// @Router       /workspace [get]
+func (h *Handler) GetWorkspaceList(c *gin.Context) {
+	userId, err := h.authorizeToken(c)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	if config.DebugLog() {
+		log.Println("requesting workspace list", fullUrl(c))
+	}
+
+	res, err := h.ctrl.GetWorkspaceList(userId)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+// GetWorkspaceInvitations godoc
+// @ID get-workspace-invitations
+// @Security ApiKeyAuth
+// @Summary      Get workspace invitations
+// @Description  Get workspaces the user is invited to and has not accepted yet
+// @Tags         workspace
+// @Accept       json
+// @Produce      json
+// @Success 200 {array} model.Workspace
+// @Failure      400  {object}  http.StatusBadRequest
+// @Failure      500  {object}  http.StatusInternalServerError
+
+// @Router       /workspace/invitation [get]
+func (h *Handler) GetWorkspaceInvitations(c *gin.Context) {
+	userId, err := h.authorizeToken(c)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	if config.DebugLog() {
+		log.Println("requesting workspace invitations", fullUrl(c))
+	}
+
+	res, err := h.ctrl.GetWorkspaceInvitations(userId)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+// AcceptWorkspaceInvitation godoc
+// @ID accept-workspace-invitation
+// @Security ApiKeyAuth
+// @Summary      Accept workspace invitation
+// @Description  Become a member of the workspace the user is invited to
+// @Tags         workspace
+// @Accept       json
+// @Produce      json
+// @Param id path int true "workspace id"
+// @Success	200 {object}
+// @Failure      400  {object}  http.StatusBadRequest
+// @Failure      404  {object}  http.StatusNotFound
+// @Failure      500  {object}  http.StatusInternalServerError
+
+// @Router       /workspace/{id}/accept [put]
+func (h *Handler) AcceptWorkspaceInvitation(c *gin.Context) {
+	userId, err := h.authorizeToken(c)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	if config.DebugLog() {
+		log.Println("requesting workspace invitation accept", fullUrl(c))
+	}
+
+	err = h.ctrl.AcceptWorkspaceInvitation(userId, c.Param("id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": true,
+	})
+}
+
+// GetWorkspaceMembers godoc
+// @ID get-workspace-members
+// @Security ApiKeyAuth
+// @Summary      Get workspace members
+// @Description  Get members of the workspace and pending invitations ordered by login, the owner is not listed
+// @Tags         workspace
+// @Accept       json
+// @Produce      json
+// @Param id path int true "workspace id"
+// @Success 200 {array} model.WorkspaceMember
+// @Failure      400  {object}  http.StatusBadRequest
+// @Failure      404  {object}  http.StatusNotFound
+// @Failure      500  {object}  http.StatusInternalServerError
+
+// @Router       /workspace/{id}/member [get]
+func (h *Handler) GetWorkspaceMembers(c *gin.Context) {
+	userId, err := h.authorizeToken(c)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	if config.DebugLog() {
+		log.Println("requesting workspace members", fullUrl(c))
+	}
+
+	res, err := h.ctrl.GetWorkspaceMembers(userId, c.Param("id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+// InviteWorkspaceMember godoc
+// @ID invite-workspace-member
+// @Security ApiKeyAuth
+// @Summary      Invite workspace member
+// @Description  Invite user by login, the user becomes a member after accepting the invitation
+// @Tags         workspace
+// @Accept       json
+// @Produce      json
+// @Param id path int true "workspace id"
+// @Param input body todo.Model true "member input login,role"
+// @Success 201 {object} model.WorkspaceMember
+// @Failure      400  {object}  http.StatusBadRequest
+// @Failure      403  {object}  http.StatusForbidden
+// @Failure      404  {object}  http.StatusNotFound
+// @Failure      409  {object}  http.StatusConflict
+// @Failure      422  {object}  http.StatusUnprocessableEntity
+// @Failure      500  {object}  http.StatusInternalServerError
+
+// @Router       /workspace/{id}/member [post]
+func (h *Handler) InviteWorkspaceMember(c *gin.Context) {
+	userId, err := h.authorizeToken(c)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	var bodyData map[string]interface{}
+	err = extractBody(c, &bodyData)
+	if err != nil {
+		respondError(c, controller.ErrInvalidBodyParams)
+		return
+	}
+
+	login, _ := bodyData["login"].(string)
+	role, _ := bodyData["role"].(string)
+
+	if config.DebugLog() {
+		log.Println("requesting workspace member invite", fullUrl(c))
+	}
+
+	member, err := h.ctrl.InviteWorkspaceMember(userId, c.Param("id"), login, role)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, member)
+}
+
+// EditWorkspaceMember godoc
+// @ID edit-workspace-member
+// @Security ApiKeyAuth
+// @Summary      Edit workspace member
+// @Description  Change role of the member or of the pending invitation
+// @Tags         workspace
+// @Accept       json
+// @Produce      json
+// @Param id path int true "workspace id"
+// @Param user_id path int true "member user id"
+// @Param input body todo.Model true "member input role"
+// @Success	200 {object}
+// @Failure      400  {object}  http.StatusBadRequest
+// @Failure      403  {object}  http.StatusForbidden
+// @Failure      404  {object}  http.StatusNotFound
+// @Failure      422  {object}  http.StatusUnprocessableEntity
+// @Failure      500  {object}  http.StatusInternalServerError
+
+// @Router       /workspace/{id}/member/{user_id} [put]
+func (h *Handler) EditWorkspaceMember(c *gin.Context) {
+	userId, err := h.authorizeToken(c)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	memberId, err := controller.ParseMemberId(c.Param("user_id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	var bodyData map[string]interface{}
+	err = extractBody(c, &bodyData)
+	if err != nil {
+		respondError(c, controller.ErrInvalidBodyParams)
+		return
+	}
+
+	role, _ := bodyData["role"].(string)
+
+	if config.DebugLog() {
+		log.Println("requesting workspace member edit", fullUrl(c))
+	}
+
+	err = h.ctrl.EditWorkspaceMember(userId, c.Param("id"), memberId, role)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": true,
+	})
+}
+
+// RemoveWorkspaceMember godoc
+// @ID remove-workspace-member
+// @Security ApiKeyAuth
+// @Summary      Remove workspace member
+// @Description  Remove member or pending invitation, users may remove themselves to leave or decline
+// @Tags         workspace
+// @Accept       json
+// @Produce      json
+// @Param id path int true "workspace id"
+// @Param user_id path int true "member user id"
+// @Success	200 {object}
+// @Failure      400  {object}  http.StatusBadRequest
+// @Failure      403  {object}  http.StatusForbidden
+// @Failure      404  {object}  http.StatusNotFound
+// @Failure      500  {object}  http.StatusInternalServerError
+
+// @Router       /workspace/{id}/member/{user_id} [delete]
+func (h *Handler) RemoveWorkspaceMember(c *gin.Context) {
+	userId, err := h.authorizeToken(c)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	memberId, err := controller.ParseMemberId(c.Param("user_id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	if config.DebugLog() {
+		log.Println("requesting workspace member remove", fullUrl(c))
+	}
+
+	err = h.ctrl.RemoveWorkspaceMember(userId, c.Param("id"), memberId)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": true,
+	})
+}
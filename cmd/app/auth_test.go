@@ -0,0 +1,133 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserRegister(t *testing.T) {
+	bodyData := map[string]interface{}{
+		"login":    "michael",
+		"password": "jordan23",
+	}
+
+	jsonValue, _ := json.Marshal(bodyData)
+	req, _ := http.NewRequest("POST", "/api/user/register", bytes.NewBuffer(jsonValue))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	req, _ = http.NewRequest("POST", "/api/user/register", bytes.NewBuffer(jsonValue))
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Equal(t, "register_failure_login_is_taken", errorCode(w))
+
+	register := func(login, password string) *httptest.ResponseRecorder {
+		jsonValue, _ := json.Marshal(map[string]interface{}{"login": login, "password": password})
+		req, _ := http.NewRequest("POST", "/api/user/register", bytes.NewBuffer(jsonValue))
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	// login is limited in characters, password in bytes bcrypt takes
+	w = register(strings.Repeat("m", 121), "jordan23")
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Equal(t, "register_failure_login_is_too_long", errorCode(w))
+
+	w = register(strings.Repeat("ü", 120), "jordan23")
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	w = register("air-jordan", strings.Repeat("j", 73))
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Equal(t, "register_failure_password_is_too_long", errorCode(w))
+
+	w = register("air-jordan", strings.Repeat("j", 72))
+	assert.Equal(t, http.StatusCreated, w.Code)
+}
+
+func TestUserLogin(t *testing.T) {
+	registerAndLogin("patrick", "ewing33")
+
+	bodyData := map[string]interface{}{
+		"login":    "patrick",
+		"password": "wrong password",
+	}
+
+	jsonValue, _ := json.Marshal(bodyData)
+	req, _ := http.NewRequest("POST", "/api/user/login", bytes.NewBuffer(jsonValue))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Equal(t, "login_incorrect_credentials", errorCode(w))
+
+	bodyData["password"] = "ewing33"
+	jsonValue, _ = json.Marshal(bodyData)
+	req, _ = http.NewRequest("POST", "/api/user/login", bytes.NewBuffer(jsonValue))
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	result := make(map[string]interface{})
+	json.Unmarshal([]byte(w.Body.String()), &result)
+	assert.NotEmpty(t, result["access_token"])
+	assert.NotEmpty(t, result["refresh_token"])
+}
+
+func TestLegacyTokenRejected(t *testing.T) {
+	req, _ := http.NewRequest("GET", "/api/task", nil)
+	req.Header.Add("Authorization", "Bearer "+legacyToken)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestUserRefreshAndLogout(t *testing.T) {
+	accessToken, refreshToken := registerAndLogin("dennis", "rodman91")
+
+	jsonValue, _ := json.Marshal(map[string]interface{}{
+		"refresh_token": refreshToken,
+	})
+	req, _ := http.NewRequest("POST", "/api/user/refresh", bytes.NewBuffer(jsonValue))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	result := make(map[string]interface{})
+	json.Unmarshal([]byte(w.Body.String()), &result)
+	newRefreshToken, _ := result["refresh_token"].(string)
+	assert.NotEmpty(t, newRefreshToken)
+
+	// refresh token is rotated, the old one can not be used twice
+	req, _ = http.NewRequest("POST", "/api/user/refresh", bytes.NewBuffer(jsonValue))
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	jsonValue, _ = json.Marshal(map[string]interface{}{
+		"refresh_token": newRefreshToken,
+	})
+	req, _ = http.NewRequest("POST", "/api/user/logout", bytes.NewBuffer(jsonValue))
+	req.Header.Add("Authorization", "Bearer "+accessToken)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	req, _ = http.NewRequest("GET", "/api/task", nil)
+	req.Header.Add("Authorization", "Bearer "+accessToken)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	req, _ = http.NewRequest("POST", "/api/user/refresh", bytes.NewBuffer(jsonValue))
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
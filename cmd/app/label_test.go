@@ -0,0 +1,119 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"todo/internal/model"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTaskLabels(t *testing.T) {
+	labelToken, _ := registerAndLogin("dirk", "fadeaway41")
+
+	send := func(method, path string, body map[string]interface{}) *httptest.ResponseRecorder {
+		jsonValue, _ := json.Marshal(body)
+		req, _ := http.NewRequest(method, path, bytes.NewBuffer(jsonValue))
+		req.Header.Add("Authorization", "Bearer "+labelToken)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	labels := map[string]string{}
+	for _, name := range []string{"backend", "bug"} {
+		w := send("POST", "/api/label", map[string]interface{}{"name": name, "color": "#1d76db"})
+		assert.Equal(t, http.StatusCreated, w.Code)
+
+		var label model.Label
+		json.Unmarshal([]byte(w.Body.String()), &label)
+		labels[name] = strconv.Itoa(int(label.Id))
+	}
+
+	w := send("POST", "/api/label", map[string]interface{}{"name": "bug"})
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Equal(t, "label_failure_name_is_taken", errorCode(w))
+
+	w = send("POST", "/api/label", map[string]interface{}{"name": "ui", "color": "blue"})
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Equal(t, "label_failure_invalid_color", errorCode(w))
+
+	tasks := map[string]string{}
+	for _, name := range []string{"Api", "Crash", "Docs"} {
+		w := send("POST", "/api/task", map[string]interface{}{"name": name})
+		assert.Equal(t, http.StatusCreated, w.Code)
+
+		result := make(map[string]interface{})
+		json.Unmarshal([]byte(w.Body.String()), &result)
+		tasks[name] = strconv.Itoa(int(result["id"].(float64)))
+	}
+
+	for _, item := range [][2]string{{"Api", "backend"}, {"Crash", "backend"}, {"Crash", "bug"}, {"Crash", "bug"}} {
+		w := send("PUT", "/api/task/"+tasks[item[0]]+"/label/"+labels[item[1]], nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+	}
+
+	list := func(query string) (names []string) {
+		w := send("GET", "/api/task?"+query, nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var result struct {
+			Data []model.Task `json:"data"`
+		}
+		json.Unmarshal([]byte(w.Body.String()), &result)
+
+		for _, task := range result.Data {
+			var labelNames []string
+			for _, label := range task.Labels {
+				labelNames = append(labelNames, label.Name)
+			}
+			names = append(names, task.Name+":"+strings.Join(labelNames, ","))
+		}
+		return
+	}
+
+	assert.Equal(t, []string{"Api:backend", "Crash:backend,bug", "Docs:"}, list(""))
+	assert.Equal(t, []string{"Api:backend", "Crash:backend,bug"}, list("label=backend&label=bug"))
+	assert.Equal(t, []string{"Crash:backend,bug"}, list("label=backend,bug&label_match=all"))
+
+	w = send("DELETE", "/api/task/"+tasks["Crash"]+"/label/"+labels["backend"], nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []string{"Api:backend"}, list("label=backend"))
+
+	w = send("PUT", "/api/label/"+labels["bug"], map[string]interface{}{"name": "defect"})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []string{"Crash:defect"}, list("label=defect"))
+
+	w = send("DELETE", "/api/label/"+labels["bug"], nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = send("GET", "/api/task/"+tasks["Crash"], nil)
+	var task model.Task
+	json.Unmarshal([]byte(w.Body.String()), &task)
+	assert.Empty(t, task.Labels)
+
+	w = send("GET", "/api/label/"+labels["bug"], nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, "label_not_found", errorCode(w))
+
+	w = send("PUT", "/api/task/"+tasks["Api"]+"/label/"+labels["bug"], nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, "label_not_found", errorCode(w))
+
+	w = send("GET", "/api/task?label=backend&label_match=most", nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "invalid_label_match", errorCode(w))
+
+	// labels of other users are not visible
+	otherToken, _ := registerAndLogin("magic", "johnson32")
+	req, _ := http.NewRequest("GET", "/api/label/"+labels["backend"], nil)
+	req.Header.Add("Authorization", "Bearer "+otherToken)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
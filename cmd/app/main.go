@@ -1,11 +1,19 @@
 package main
 
 import (
+	"context"
 	"log" // swagger embed files	"log"
 	"os"
 	"strconv"
+	"strings"
+	"time"
+	_ "time/tzdata" // due date timezones do not depend on the host
 	"todo/api"
+	"todo/internal/blob"
 	"todo/internal/config"
+	"todo/internal/controller"
+	"todo/internal/model"
+	"todo/internal/reminder"
 	"todo/pkg/db"
 
 	_ "todo/docs"
@@ -42,6 +50,36 @@ func readEnvVariables() (serverHost, serverPort string) {
 	}
 	config.SetJwtSecret(jwtSecret)
 
+	if ttl, err := time.ParseDuration(os.Getenv("ACCESS_TOKEN_TTL")); err == nil {
+		config.SetAccessTokenTTL(ttl)
+	}
+
+	if ttl, err := time.ParseDuration(os.Getenv("REFRESH_TOKEN_TTL")); err == nil {
+		config.SetRefreshTokenTTL(ttl)
+	}
+
+	if lead, err := time.ParseDuration(os.Getenv("REMINDER_LEAD")); err == nil {
+		config.SetReminderLead(lead)
+	}
+
+	if interval, err := time.ParseDuration(os.Getenv("REMINDER_INTERVAL")); err == nil {
+		config.SetReminderInterval(interval)
+	}
+
+	strictChecklist, _ := strconv.ParseBool(os.Getenv("CHECKLIST_STRICT"))
+	config.SetStrictChecklist(strictChecklist)
+
+	autoAssign, _ := strconv.ParseBool(os.Getenv("AUTO_ASSIGN_ON_START"))
+	config.SetAutoAssign(autoAssign)
+
+	if size, err := strconv.ParseInt(os.Getenv("ATTACHMENT_MAX_SIZE"), 10, 64); err == nil {
+		config.SetAttachmentMaxSize(size)
+	}
+
+	if types, ok := os.LookupEnv("ATTACHMENT_TYPES"); ok {
+		config.SetAttachmentTypes(strings.FieldsFunc(types, func(r rune) bool { return r == ',' || r == ' ' }))
+	}
+
 	debug, _ := strconv.ParseBool(os.Getenv("DEBUG"))
 	config.SetDebugLog(debug)
 
@@ -64,24 +102,96 @@ func connectDB() (*pgxpool.Pool, error) {
 	return dbpool, nil
 }
 
-func setupRouter() (r *gin.Engine) {
+// newBlobStore keeps attachments in BLOB_DIR by default or in S3 bucket when BLOB_STORAGE=s3
+func newBlobStore() blob.Store {
+	if os.Getenv("BLOB_STORAGE") == "s3" {
+		return blob.NewS3Store(blob.S3Config{
+			Endpoint:  os.Getenv("S3_ENDPOINT"),
+			Region:    os.Getenv("S3_REGION"),
+			Bucket:    os.Getenv("S3_BUCKET"),
+			AccessKey: os.Getenv("S3_ACCESS_KEY"),
+			SecretKey: os.Getenv("S3_SECRET_KEY"),
+		})
+	}
+
+	dir := os.Getenv("BLOB_DIR")
+	if "" == dir {
+		dir = "data/blobs" // default value
+	}
+	return blob.NewLocalStore(dir)
+}
+
+func setupRouter(store model.Store, blobs blob.Store) (r *gin.Engine) {
+	h := api.NewHandler(controller.New(store, blobs))
+
 	r = gin.New()
 	r.Use(gin.Recovery()) // recovery middleware
+	r.Use(api.RequestId())
 
 	r.GET("/api", api.RootIndex)
-	r.POST("/api/user/login", api.UserLogin)
-	r.GET("/api/task", api.GetTaskList)
-	r.GET("/api/task/status", api.GetTaskStatusList)
-	r.POST("/api/task", api.CreateTask)
-	r.GET("/api/task/:id", api.GetTask)
-	r.PUT("/api/task/:id", api.EditTask)
-	r.PUT("/api/task/:id/start_progress", api.StartTaskProgress)
-	r.PUT("/api/task/:id/pause", api.PauseTask)
-	r.PUT("/api/task/:id/done", api.DoneTask)
-	r.DELETE("/api/task/:id", api.DeleteTask) // only changes status to 'deleted'
-	r.PUT("/api/task/:id/restore", api.RestoreTask)
-	r.DELETE("/api/task/:id/completely", api.DeleteTaskCompletely)
-	r.DELETE("/api/task/free_trash", api.FreeTaskTrash)
+	r.POST("/api/user/login", h.UserLogin)
+	r.POST("/api/user/register", h.UserRegister)
+	r.POST("/api/user/refresh", h.UserRefresh)
+	r.POST("/api/user/logout", h.UserLogout)
+	r.GET("/api/task", h.GetTaskList)
+	r.GET("/api/task/status", h.GetTaskStatusList)
+	r.POST("/api/task", h.CreateTask)
+	r.GET("/api/task/:id", h.GetTask)
+	r.GET("/api/task/:id/history", h.GetTaskHistory)
+	r.GET("/api/task/:id/occurrences", h.GetTaskOccurrences)
+	r.GET("/api/task/:id/children", h.GetTaskChildren)
+	r.PUT("/api/task/:id", h.EditTask)
+	r.PUT("/api/task/:id/move", h.MoveTask)
+	r.PUT("/api/task/:id/label/:label_id", h.AddTaskLabel)
+	r.DELETE("/api/task/:id/label/:label_id", h.RemoveTaskLabel)
+	r.PUT("/api/task/:id/assignees", h.SetTaskAssignees)
+	r.GET("/api/task/:id/time", h.GetTaskTime)
+	r.POST("/api/task/:id/time", h.AddTimeEntry)
+	r.PUT("/api/task/:id/blocker/:blocker_id", h.AddTaskBlocker)
+	r.DELETE("/api/task/:id/blocker/:blocker_id", h.RemoveTaskBlocker)
+	r.GET("/api/task/:id/comment", h.GetTaskComments)
+	r.POST("/api/task/:id/comment", h.AddTaskComment)
+	r.PUT("/api/task/:id/comment/:comment_id", h.EditTaskComment)
+	r.DELETE("/api/task/:id/comment/:comment_id", h.DeleteTaskComment)
+	r.GET("/api/task/:id/checklist", h.GetChecklist)
+	r.POST("/api/task/:id/checklist", h.AddChecklistItem)
+	r.PUT("/api/task/:id/checklist/:item_id", h.EditChecklistItem)
+	r.PUT("/api/task/:id/checklist/:item_id/move", h.MoveChecklistItem)
+	r.DELETE("/api/task/:id/checklist/:item_id", h.RemoveChecklistItem)
+	r.GET("/api/task/:id/attachment", h.GetTaskAttachments)
+	r.POST("/api/task/:id/attachment", h.AddTaskAttachment)
+	r.GET("/api/task/:id/attachment/:attachment_id", h.DownloadTaskAttachment)
+	r.DELETE("/api/task/:id/attachment/:attachment_id", h.DeleteTaskAttachment)
+	r.PUT("/api/task/:id/start_progress", h.StartTaskProgress)
+	r.PUT("/api/task/:id/pause", h.PauseTask)
+	r.PUT("/api/task/:id/done", h.DoneTask)
+	r.DELETE("/api/task/:id", h.DeleteTask) // only changes status to 'deleted'
+	r.PUT("/api/task/:id/restore", h.RestoreTask)
+	r.DELETE("/api/task/:id/completely", h.DeleteTaskCompletely)
+	r.DELETE("/api/task/free_trash", h.FreeTaskTrash)
+
+	r.GET("/api/label", h.GetLabelList)
+	r.POST("/api/label", h.CreateLabel)
+	r.GET("/api/label/:id", h.GetLabel)
+	r.PUT("/api/label/:id", h.EditLabel)
+	r.DELETE("/api/label/:id", h.DeleteLabel)
+
+	r.GET("/api/project", h.GetProjectList)
+	r.POST("/api/project", h.CreateProject)
+	r.GET("/api/project/:id", h.GetProject)
+	r.PUT("/api/project/:id", h.EditProject)
+	r.DELETE("/api/project/:id", h.DeleteProject)
+	r.GET("/api/project/:id/task", h.GetProjectTaskList)
+
+	r.GET("/api/workspace", h.GetWorkspaceList)
+	r.GET("/api/workspace/invitation", h.GetWorkspaceInvitations)
+	r.PUT("/api/workspace/:id/accept", h.AcceptWorkspaceInvitation)
+	r.GET("/api/workspace/:id/member", h.GetWorkspaceMembers)
+	r.POST("/api/workspace/:id/member", h.InviteWorkspaceMember)
+	r.PUT("/api/workspace/:id/member/:user_id", h.EditWorkspaceMember)
+	r.DELETE("/api/workspace/:id/member/:user_id", h.RemoveWorkspaceMember)
+
+	r.GET("/api/report/summary", h.GetReportSummary)
 
 	r.GET("/docs/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
@@ -111,6 +221,13 @@ func setupRouter() (r *gin.Engine) {
 // @in header
 // @name Authorization
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
 	gin.SetMode(gin.ReleaseMode)
 
 	serverHost, serverPort := readEnvVariables()
@@ -127,7 +244,20 @@ func main() {
 	}
 	defer dbpool.Close()
 
-	r := setupRouter()
+	if migrateOnStart() {
+		applied, err := db.MigrateUp(dbpool)
+		logAppliedMigrations(applied)
+		if err != nil {
+			log.Fatalf("Error on migrations: %v\n", err)
+		}
+	}
+
+	store := model.NewPgStore(dbpool)
+
+	scheduler := reminder.NewScheduler(store, reminder.LogNotifier{}, config.ReminderLead(), config.ReminderInterval())
+	go scheduler.Run(context.Background())
+
+	r := setupRouter(store, newBlobStore())
 
 	if config.DebugLog() {
 		log.Println("Todo list App started SUCCESSFULL")
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
+	"todo/pkg/db"
+)
+
+const migrateUsage = `usage: todo migrate up|down|status|force <version>
+  up       apply every pending migration
+  down     revert the latest applied migration
+  status   list migrations and when they were applied
+  force    mark migrations up to <version> as applied without running them`
+
+// runMigrate handles `todo migrate ...` subcommand
+func runMigrate(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf(migrateUsage)
+	}
+
+	_, _ = readEnvVariables()
+
+	dbpool, err := connectDB()
+	if err != nil {
+		return err
+	}
+	defer dbpool.Close()
+
+	switch args[0] {
+	case "up":
+		applied, err := db.MigrateUp(dbpool)
+		for _, m := range applied {
+			fmt.Printf("applied %04d_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			return err
+		}
+		if len(applied) == 0 {
+			fmt.Println("no pending migrations")
+		}
+
+	case "down":
+		reverted, err := db.MigrateDown(dbpool)
+		if err != nil {
+			return err
+		}
+		if reverted == nil {
+			fmt.Println("no applied migrations")
+		} else {
+			fmt.Printf("reverted %04d_%s\n", reverted.Version, reverted.Name)
+		}
+
+	case "status":
+		statuses, err := db.GetMigrationStatus(dbpool)
+		if err != nil {
+			return err
+		}
+		for _, s := range statuses {
+			appliedAt := "pending"
+			if s.AppliedAt != nil {
+				appliedAt = s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d_%-40s %s\n", s.Version, s.Name, appliedAt)
+		}
+
+	case "force":
+		if len(args) < 2 {
+			return fmt.Errorf(migrateUsage)
+		}
+		version, err := strconv.Atoi(args[1])
+		if err != nil {
+			return fmt.Errorf("invalid version %q", args[1])
+		}
+		err = db.MigrateForce(dbpool, version)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("forced version %d\n", version)
+
+	default:
+		return fmt.Errorf(migrateUsage)
+	}
+
+	return nil
+}
+
+// migrateOnStart applies pending migrations before serving traffic when MIGRATE_ON_START is set
+func migrateOnStart() bool {
+	enabled, _ := strconv.ParseBool(os.Getenv("MIGRATE_ON_START"))
+	return enabled
+}
+
+func logAppliedMigrations(applied []db.Migration) {
+	for _, m := range applied {
+		log.Printf("migration %04d_%s applied", m.Version, m.Name)
+	}
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"todo/internal/model"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProjects(t *testing.T) {
+	projectToken, _ := registerAndLogin("elgin", "baylor22")
+
+	send := func(method, path string, body map[string]interface{}) *httptest.ResponseRecorder {
+		jsonValue, _ := json.Marshal(body)
+		req, _ := http.NewRequest(method, path, bytes.NewBuffer(jsonValue))
+		req.Header.Add("Authorization", "Bearer "+projectToken)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	taskIds := func(w *httptest.ResponseRecorder) []int64 {
+		var page model.TaskPage
+		json.Unmarshal([]byte(w.Body.String()), &page)
+		var ids []int64
+		for _, task := range page.Tasks {
+			ids = append(ids, task.Id)
+		}
+		return ids
+	}
+
+	w := send("POST", "/api/project", map[string]interface{}{"name": "Mobile app", "color": "#1d76db"})
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var project model.Project
+	json.Unmarshal([]byte(w.Body.String()), &project)
+	assert.Equal(t, "Mobile app", project.Name)
+	projectId := strconv.FormatInt(project.Id, 10)
+	projectPath := "/api/project/" + projectId
+
+	w = send("POST", "/api/project", map[string]interface{}{"name": "Mobile app"})
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Equal(t, "project_failure_name_is_taken", errorCode(w))
+
+	w = send("POST", "/api/project", map[string]interface{}{"name": "Website", "color": "blue"})
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Equal(t, "project_failure_invalid_color", errorCode(w))
+
+	w = send("GET", "/api/project/abc", nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = send("GET", "/api/project/999999", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	// tasks in the project and one without project
+	w = send("POST", "/api/task", map[string]interface{}{"name": "Login screen", "project_id": project.Id})
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var login model.Task
+	json.Unmarshal([]byte(w.Body.String()), &login)
+
+	w = send("GET", "/api/task/"+strconv.FormatInt(login.Id, 10), nil)
+	assert.Contains(t, w.Body.String(), `"project_id":`+projectId)
+
+	w = send("POST", "/api/task", map[string]interface{}{"name": "Push notifications", "project_id": projectId})
+	var push model.Task
+	json.Unmarshal([]byte(w.Body.String()), &push)
+
+	w = send("POST", "/api/task", map[string]interface{}{"name": "Groceries"})
+	var groceries model.Task
+	json.Unmarshal([]byte(w.Body.String()), &groceries)
+
+	w = send("POST", "/api/task", map[string]interface{}{"name": "Lost", "project_id": 999999})
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Equal(t, "task_invalid_project", errorCode(w))
+
+	w = send("PUT", "/api/task/"+strconv.FormatInt(push.Id, 10)+"/start_progress", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = send("GET", projectPath+"/task", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []int64{login.Id, push.Id}, taskIds(w))
+
+	w = send("GET", projectPath+"/task?status=in_progress", nil)
+	assert.Equal(t, []int64{push.Id}, taskIds(w))
+
+	w = send("GET", "/api/task?project="+projectId+"&status=created", nil)
+	assert.Equal(t, []int64{login.Id}, taskIds(w))
+
+	w = send("GET", "/api/project/999999/task", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	// archived project keeps its tasks and takes no new ones
+	w = send("PUT", projectPath, map[string]interface{}{"name": "Mobile app", "archived": true})
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = send("POST", "/api/task", map[string]interface{}{"name": "Dark mode", "project_id": project.Id})
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Equal(t, "task_project_archived", errorCode(w))
+
+	w = send("PUT", "/api/task/"+strconv.FormatInt(groceries.Id, 10), map[string]interface{}{"name": "Groceries", "project_id": project.Id})
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	w = send("PUT", "/api/task/"+strconv.FormatInt(login.Id, 10), map[string]interface{}{"name": "Login and signup", "project_id": project.Id})
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = send("GET", "/api/project?archived=true", nil)
+	assert.Contains(t, w.Body.String(), `"name":"Mobile app"`)
+
+	w = send("GET", "/api/project?archived=false", nil)
+	assert.Equal(t, "[]", w.Body.String())
+
+	w = send("GET", "/api/project?archived=maybe", nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// trash of the project only
+	w = send("POST", "/api/task", map[string]interface{}{"name": "Login docs", "parent_id": login.Id})
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var docs model.Task
+	json.Unmarshal([]byte(w.Body.String()), &docs)
+
+	send("DELETE", "/api/task/"+strconv.FormatInt(login.Id, 10), nil)
+	send("DELETE", "/api/task/"+strconv.FormatInt(groceries.Id, 10), nil)
+
+	w = send("DELETE", "/api/task/free_trash?project=999999", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	// the subtask outside the project would go together with its parent
+	w = send("DELETE", "/api/task/free_trash?project="+projectId, nil)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Equal(t, "free_trash_failure_subtasks_in_other_projects", errorCode(w))
+
+	w = send("GET", "/api/task/"+strconv.FormatInt(docs.Id, 10), nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = send("GET", "/api/task/"+strconv.FormatInt(login.Id, 10), nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = send("DELETE", "/api/task/"+strconv.FormatInt(docs.Id, 10)+"/completely", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = send("DELETE", "/api/task/free_trash?project="+projectId, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = send("GET", "/api/task/"+strconv.FormatInt(login.Id, 10), nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = send("GET", "/api/task/"+strconv.FormatInt(groceries.Id, 10), nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// deleting the project keeps its tasks
+	w = send("DELETE", projectPath, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = send("GET", "/api/task/"+strconv.FormatInt(push.Id, 10), nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"project_id":null`)
+
+	w = send("GET", projectPath, nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
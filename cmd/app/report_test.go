@@ -0,0 +1,78 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+	"todo/internal/model"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReportSummary(t *testing.T) {
+	accessToken, _ := registerAndLogin("rick", "mahorn44")
+
+	send := func(method, path string, body map[string]interface{}) *httptest.ResponseRecorder {
+		jsonValue, _ := json.Marshal(body)
+		req, _ := http.NewRequest(method, path, bytes.NewBuffer(jsonValue))
+		req.Header.Add("Authorization", "Bearer "+accessToken)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	createTask := func(name string) string {
+		w := send("POST", "/api/task", map[string]interface{}{"name": name})
+		var created model.Task
+		json.Unmarshal([]byte(w.Body.String()), &created)
+		return "/api/task/" + strconv.FormatInt(created.Id, 10)
+	}
+
+	donePath := createTask("Box out")
+	send("PUT", donePath+"/start_progress", nil)
+	send("PUT", donePath+"/done", nil)
+
+	startedPath := createTask("Set a screen")
+	send("PUT", startedPath+"/start_progress", nil)
+
+	createTask("Take a charge")
+
+	w := send("GET", "/api/report/summary", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var summary model.ReportSummary
+	json.Unmarshal([]byte(w.Body.String()), &summary)
+	assert.Equal(t, "day", summary.Interval)
+	assert.Len(t, summary.Periods, 30)
+	assert.Len(t, summary.StatusCounts, 5)
+	assert.Equal(t, model.StatusCreated, summary.StatusCounts[0].Status)
+	assert.Equal(t, 1, summary.StatusCounts[0].Count)
+	assert.Equal(t, 1, summary.CycleTime.Count)
+
+	today := summary.Periods[len(summary.Periods)-1]
+	assert.Equal(t, 1, today.Completed)
+	assert.Equal(t, map[string]int{"created": 1, "in_progress": 1, "paused": 0, "done": 1, "deleted": 0}, today.Flow)
+	assert.Equal(t, 0, summary.Periods[0].Flow["created"])
+
+	w = send("GET", "/api/report/summary?interval=week&timezone=Europe/Amsterdam", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	json.Unmarshal([]byte(w.Body.String()), &summary)
+	assert.Equal(t, time.Monday, summary.Periods[0].Start.Weekday())
+
+	date := time.Now().UTC().Format("2006-01-02")
+	w = send("GET", "/api/report/summary?format=csv&from="+date+"&to="+date, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Equal(t, "date,completed,created,in_progress,paused,done,deleted\n"+date+",1,1,1,0,1,0\n", w.Body.String())
+
+	for _, query := range []string{"interval=month", "from=2024-03-02&to=2024-03-01", "from=2020-01-01&to=2021-06-01", "to=2999-01-01", "from=yesterday", "timezone=Mars/Base"} {
+		w = send("GET", "/api/report/summary?"+query, nil)
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+
+	w = send("GET", "/api/report/summary?format=xml", nil)
+	assert.Equal(t, "invalid_report_format", errorCode(w))
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"todo/internal/config"
+	"todo/internal/model"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTaskAssignees(t *testing.T) {
+	ownerToken, _ := registerAndLogin("isiah", "thomas11")
+	memberToken, _ := registerAndLogin("joe", "dumars4")
+	strangerToken, _ := registerAndLogin("adrian", "dantley4")
+
+	send := func(accessToken, workspace, method, path string, body map[string]interface{}) *httptest.ResponseRecorder {
+		jsonValue, _ := json.Marshal(body)
+		req, _ := http.NewRequest(method, path, bytes.NewBuffer(jsonValue))
+		req.Header.Add("Authorization", "Bearer "+accessToken)
+		if workspace != "" {
+			req.Header.Add("X-Workspace", workspace)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	ownId := func(accessToken string) string {
+		var list []*model.Workspace
+		json.Unmarshal([]byte(send(accessToken, "", "GET", "/api/workspace", nil).Body.String()), &list)
+		return strconv.Itoa(int(list[0].Id))
+	}
+	createTask := func(name string) string {
+		w := send(ownerToken, "", "POST", "/api/task", map[string]interface{}{"name": name})
+		var created model.Task
+		json.Unmarshal([]byte(w.Body.String()), &created)
+		return "/api/task/" + strconv.FormatInt(created.Id, 10)
+	}
+
+	workspace := ownId(ownerToken)
+	memberId := ownId(memberToken)
+	strangerId := ownId(strangerToken)
+
+	send(ownerToken, "", "POST", "/api/workspace/"+workspace+"/member", map[string]interface{}{"login": "joe", "role": "editor"})
+	send(memberToken, "", "PUT", "/api/workspace/"+workspace+"/accept", nil)
+
+	taskPath := createTask("Guard the perimeter")
+	createTask("Bad boys defense")
+
+	w := send(ownerToken, "", "PUT", taskPath+"/assignees", map[string]interface{}{"assignees": []interface{}{memberId, workspace, memberId}})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `{"data":[`+workspace+`,`+memberId+`]}`, w.Body.String())
+
+	w = send(ownerToken, "", "GET", taskPath, nil)
+	assert.Contains(t, w.Body.String(), `"assignees":[`+workspace+`,`+memberId+`]`)
+
+	w = send(ownerToken, "", "PUT", taskPath+"/assignees", map[string]interface{}{"assignees": []interface{}{strangerId}})
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Equal(t, "task_assignee_not_member", errorCode(w))
+
+	w = send(ownerToken, "", "PUT", taskPath+"/assignees", map[string]interface{}{"assignees": []interface{}{"abc"}})
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Equal(t, "task_invalid_assignee", errorCode(w))
+
+	w = send(ownerToken, "", "PUT", taskPath+"/assignees", map[string]interface{}{"assignees": "abc"})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// assigned to me filter
+	w = send(memberToken, workspace, "GET", "/api/task?assignee=me", nil)
+	var page model.TaskPage
+	json.Unmarshal([]byte(w.Body.String()), &page)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, page.Tasks, 1)
+	assert.Equal(t, "Guard the perimeter", page.Tasks[0].Name)
+
+	w = send(ownerToken, "", "GET", "/api/task?assignee="+strangerId, nil)
+	json.Unmarshal([]byte(w.Body.String()), &page)
+	assert.Len(t, page.Tasks, 0)
+
+	w = send(ownerToken, "", "GET", "/api/task?assignee=abc", nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "invalid_assignee", errorCode(w))
+
+	// starting a task without assignees assigns the member who started it
+	config.SetAutoAssign(true)
+	defer config.SetAutoAssign(false)
+
+	otherPath := createTask("Rebound")
+	w = send(memberToken, workspace, "PUT", otherPath+"/start_progress", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = send(ownerToken, "", "GET", otherPath, nil)
+	assert.Contains(t, w.Body.String(), `"assignees":[`+memberId+`]`)
+
+	// leaving the workspace unassigns the member
+	send(memberToken, "", "DELETE", "/api/workspace/"+workspace+"/member/"+memberId, nil)
+
+	w = send(ownerToken, "", "GET", taskPath, nil)
+	assert.Contains(t, w.Body.String(), `"assignees":[`+workspace+`]`)
+
+	w = send(ownerToken, "", "PUT", taskPath+"/assignees", map[string]interface{}{"assignees": []interface{}{}})
+	assert.Equal(t, `{"data":[]}`, w.Body.String())
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"strconv"
+	"testing"
+	"todo/internal/config"
+	"todo/internal/model"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTaskAttachments(t *testing.T) {
+	attachmentToken, _ := registerAndLogin("bill", "russell6")
+
+	send := func(method, path string, body map[string]interface{}) *httptest.ResponseRecorder {
+		jsonValue, _ := json.Marshal(body)
+		req, _ := http.NewRequest(method, path, bytes.NewBuffer(jsonValue))
+		req.Header.Add("Authorization", "Bearer "+attachmentToken)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	upload := func(path, name, contentType, content string) *httptest.ResponseRecorder {
+		var body bytes.Buffer
+		form := multipart.NewWriter(&body)
+		header := textproto.MIMEHeader{}
+		header.Set("Content-Disposition", `form-data; name="file"; filename="`+name+`"`)
+		if contentType != "" {
+			header.Set("Content-Type", contentType)
+		}
+		part, _ := form.CreatePart(header)
+		part.Write([]byte(content))
+		form.Close()
+
+		req, _ := http.NewRequest("POST", path, &body)
+		req.Header.Add("Authorization", "Bearer "+attachmentToken)
+		req.Header.Set("Content-Type", form.FormDataContentType())
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := send("POST", "/api/task", map[string]interface{}{"name": "Attach to me"})
+	var created model.Task
+	json.Unmarshal([]byte(w.Body.String()), &created)
+	taskPath := "/api/task/" + strconv.FormatInt(created.Id, 10)
+	blobCount := blobs.Len()
+
+	w = upload(taskPath+"/attachment", "notes.txt", "text/plain", "hello")
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var attachment model.TaskAttachment
+	json.Unmarshal([]byte(w.Body.String()), &attachment)
+	assert.Equal(t, "notes.txt", attachment.Name)
+	assert.Equal(t, "text/plain", attachment.ContentType)
+	assert.Equal(t, int64(5), attachment.Size)
+	assert.Equal(t, "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824", attachment.Checksum)
+	assert.NotContains(t, w.Body.String(), "blob_key")
+	attachmentPath := taskPath + "/attachment/" + strconv.FormatInt(attachment.Id, 10)
+
+	// content type is sniffed when the client does not know it, path of the name is dropped
+	w = upload(taskPath+"/attachment", `C:\photos\dot.png`, "application/octet-stream", "\x89PNG\r\n\x1a\n0000")
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Contains(t, w.Body.String(), `"name":"dot.png"`)
+	assert.Contains(t, w.Body.String(), `"content_type":"image/png"`)
+
+	w = upload(taskPath+"/attachment", "run.sh", "application/x-sh", "#!/bin/sh")
+	assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+	assert.Equal(t, "attachment_failure_type_not_allowed", errorCode(w))
+
+	// content contradicting the declared type is rejected
+	w = upload(taskPath+"/attachment", "cat.png", "image/png", "<html><script>alert(1)</script></html>")
+	assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+	assert.Equal(t, "attachment_failure_type_mismatch", errorCode(w))
+
+	w = upload(taskPath+"/attachment", "dog.png", "image/png", "\x7fELF\x02\x01\x01\x00\x00\x00\x00\x00")
+	assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+	assert.Equal(t, "attachment_failure_type_mismatch", errorCode(w))
+
+	// sniffed type must be allowed as well
+	w = upload(taskPath+"/attachment", "page.txt", "", "<!DOCTYPE html><html></html>")
+	assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+	assert.Equal(t, "attachment_failure_type_not_allowed", errorCode(w))
+
+	maxSize := config.AttachmentMaxSize()
+	config.SetAttachmentMaxSize(4)
+	w = upload(taskPath+"/attachment", "big.txt", "text/plain", "hello")
+	config.SetAttachmentMaxSize(maxSize)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	assert.Equal(t, "attachment_failure_too_large", errorCode(w))
+
+	w = send("POST", taskPath+"/attachment", nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "attachment_failure_file_is_required", errorCode(w))
+
+	w = upload("/api/task/999999/attachment", "notes.txt", "text/plain", "hello")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, blobCount+2, blobs.Len())
+
+	w = send("GET", taskPath+"/attachment", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var list struct {
+		Data []*model.TaskAttachment `json:"data"`
+	}
+	json.Unmarshal([]byte(w.Body.String()), &list)
+	if assert.Len(t, list.Data, 2) {
+		assert.Equal(t, "notes.txt", list.Data[0].Name)
+		assert.Equal(t, "dot.png", list.Data[1].Name)
+	}
+
+	w = send("GET", attachmentPath, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "hello", w.Body.String())
+	assert.Equal(t, "text/plain", w.Header().Get("Content-Type"))
+	assert.Equal(t, `attachment; filename=notes.txt`, w.Header().Get("Content-Disposition"))
+	assert.Equal(t, `"`+attachment.Checksum+`"`, w.Header().Get("ETag"))
+
+	// attachments of another user's task are not visible
+	otherToken, _ := registerAndLogin("magic", "johnson32")
+	w = send("GET", createTask(t, otherToken, map[string]interface{}{"name": "Not yours"})+"/attachment", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = send("DELETE", attachmentPath, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, blobCount+1, blobs.Len())
+
+	w = send("GET", attachmentPath, nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, "attachment_not_found", errorCode(w))
+
+	// blobs go away with the task and its subtasks
+	w = send("POST", "/api/task", map[string]interface{}{"name": "Attached subtask", "parent_id": strconv.FormatInt(created.Id, 10)})
+	var child model.Task
+	json.Unmarshal([]byte(w.Body.String()), &child)
+	w = upload("/api/task/"+strconv.FormatInt(child.Id, 10)+"/attachment", "child.csv", "text/csv", "a,b")
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, blobCount+2, blobs.Len())
+
+	w = send("DELETE", taskPath+"/completely", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, blobCount, blobs.Len())
+
+	w = send("POST", "/api/task", map[string]interface{}{"name": "Attached trash"})
+	json.Unmarshal([]byte(w.Body.String()), &created)
+	taskPath = "/api/task/" + strconv.FormatInt(created.Id, 10)
+	upload(taskPath+"/attachment", "notes.txt", "text/plain", "hello")
+	assert.Equal(t, blobCount+1, blobs.Len())
+
+	send("DELETE", taskPath, nil)
+	w = send("DELETE", "/api/task/free_trash", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, blobCount, blobs.Len())
+}
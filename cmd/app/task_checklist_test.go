@@ -0,0 +1,107 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"todo/internal/config"
+	"todo/internal/model"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTaskChecklist(t *testing.T) {
+	checklistToken, _ := registerAndLogin("wilt", "chamberlain13")
+
+	send := func(method, path string, body map[string]interface{}) *httptest.ResponseRecorder {
+		jsonValue, _ := json.Marshal(body)
+		req, _ := http.NewRequest(method, path, bytes.NewBuffer(jsonValue))
+		req.Header.Add("Authorization", "Bearer "+checklistToken)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := send("POST", "/api/task", map[string]interface{}{"name": "Release"})
+	var created model.Task
+	json.Unmarshal([]byte(w.Body.String()), &created)
+	taskPath := "/api/task/" + strconv.FormatInt(created.Id, 10)
+
+	var itemIds []string
+	for _, text := range []string{"build", "test", "deploy"} {
+		w = send("POST", taskPath+"/checklist", map[string]interface{}{"text": text})
+		assert.Equal(t, http.StatusCreated, w.Code)
+
+		var item model.ChecklistItem
+		json.Unmarshal([]byte(w.Body.String()), &item)
+		assert.Equal(t, text, item.Text)
+		assert.False(t, item.Checked)
+		itemIds = append(itemIds, strconv.FormatInt(item.Id, 10))
+	}
+
+	w = send("POST", taskPath+"/checklist", map[string]interface{}{"text": " "})
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Equal(t, "checklist_item_failure_text_is_required", errorCode(w))
+
+	w = send("PUT", taskPath+"/checklist/"+itemIds[0], map[string]interface{}{"checked": true})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"checked":true`)
+	assert.Contains(t, w.Body.String(), `"text":"build"`)
+
+	w = send("PUT", taskPath+"/checklist/"+itemIds[2], map[string]interface{}{"text": "deploy to production"})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"checked":false`)
+
+	// deploy goes before test
+	w = send("PUT", taskPath+"/checklist/"+itemIds[2]+"/move", map[string]interface{}{"before": itemIds[1]})
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = send("PUT", taskPath+"/checklist/"+itemIds[2]+"/move", map[string]interface{}{"after": itemIds[1], "before": itemIds[0]})
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Equal(t, "checklist_item_invalid_neighbour", errorCode(w))
+
+	w = send("GET", taskPath+"/checklist", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var list struct {
+		Data []*model.ChecklistItem `json:"data"`
+	}
+	json.Unmarshal([]byte(w.Body.String()), &list)
+	var texts []string
+	for _, item := range list.Data {
+		texts = append(texts, item.Text)
+	}
+	assert.Equal(t, []string{"build", "deploy to production", "test"}, texts)
+
+	w = send("GET", taskPath, nil)
+	assert.Contains(t, w.Body.String(), `"checklist":{"checked":1,"total":3}`)
+
+	w = send("DELETE", taskPath+"/checklist/"+itemIds[1], nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = send("DELETE", taskPath+"/checklist/"+itemIds[1], nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, "checklist_item_not_found", errorCode(w))
+
+	// checklist of another user's task is not visible
+	otherToken, _ := registerAndLogin("magic", "johnson32")
+	w = send("GET", createTask(t, otherToken, map[string]interface{}{"name": "Not yours"})+"/checklist", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	config.SetStrictChecklist(true)
+	defer config.SetStrictChecklist(false)
+
+	w = send("PUT", taskPath+"/done", nil)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Equal(t, "task_checklist_incomplete", errorCode(w))
+	assert.Contains(t, w.Body.String(), `"unchecked":1`)
+
+	w = send("PUT", taskPath+"/move", map[string]interface{}{"status": "done"})
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	send("PUT", taskPath+"/checklist/"+itemIds[2], map[string]interface{}{"checked": true})
+	w = send("PUT", taskPath+"/done", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
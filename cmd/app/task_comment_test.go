@@ -0,0 +1,91 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"todo/internal/model"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTaskComments(t *testing.T) {
+	commentToken, _ := registerAndLogin("larry", "legend33")
+
+	send := func(method, path string, body map[string]interface{}) *httptest.ResponseRecorder {
+		jsonValue, _ := json.Marshal(body)
+		req, _ := http.NewRequest(method, path, bytes.NewBuffer(jsonValue))
+		req.Header.Add("Authorization", "Bearer "+commentToken)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := send("POST", "/api/task", map[string]interface{}{"name": "Discuss me"})
+	var created model.Task
+	json.Unmarshal([]byte(w.Body.String()), &created)
+	taskPath := "/api/task/" + strconv.FormatInt(created.Id, 10)
+
+	var commentIds []string
+	for _, body := range []string{"first", "second", "third"} {
+		w = send("POST", taskPath+"/comment", map[string]interface{}{"body": body})
+		assert.Equal(t, http.StatusCreated, w.Code)
+
+		var comment model.TaskComment
+		json.Unmarshal([]byte(w.Body.String()), &comment)
+		assert.Equal(t, body, comment.Body)
+		assert.Equal(t, created.Id, comment.TaskId)
+		commentIds = append(commentIds, strconv.FormatInt(comment.Id, 10))
+	}
+
+	w = send("POST", taskPath+"/comment", map[string]interface{}{"body": "  "})
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Equal(t, "comment_failure_body_is_required", errorCode(w))
+
+	var page model.TaskCommentPage
+	w = send("GET", taskPath+"/comment?limit=2", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	json.Unmarshal([]byte(w.Body.String()), &page)
+	assert.Equal(t, 3, page.Total)
+	assert.Len(t, page.Comments, 2)
+	assert.Equal(t, "first", page.Comments[0].Body)
+	assert.NotEmpty(t, page.NextCursor)
+
+	cursor := page.NextCursor
+	page = model.TaskCommentPage{}
+	w = send("GET", taskPath+"/comment?limit=2&cursor="+cursor, nil)
+	json.Unmarshal([]byte(w.Body.String()), &page)
+	assert.Len(t, page.Comments, 1)
+	assert.Equal(t, "third", page.Comments[0].Body)
+	assert.Empty(t, page.NextCursor)
+
+	w = send("PUT", taskPath+"/comment/"+commentIds[1], map[string]interface{}{"body": "second, edited"})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"body":"second, edited"`)
+
+	w = send("DELETE", taskPath+"/comment/"+commentIds[0], nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = send("DELETE", taskPath+"/comment/"+commentIds[0], nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, "comment_not_found", errorCode(w))
+
+	w = send("GET", "/api/task?q=Discuss", nil)
+	var list struct {
+		Data []model.Task `json:"data"`
+	}
+	json.Unmarshal([]byte(w.Body.String()), &list)
+	assert.Len(t, list.Data, 1)
+	assert.Equal(t, 2, list.Data[0].CommentCount)
+
+	// comments of another user's task are not visible
+	otherToken, _ := registerAndLogin("magic", "johnson32")
+	w = httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", taskPath+"/comment", nil)
+	req.Header.Add("Authorization", "Bearer "+otherToken)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
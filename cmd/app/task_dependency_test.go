@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"todo/internal/model"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTaskDependencies(t *testing.T) {
+	depToken, _ := registerAndLogin("kevin", "bigticket5")
+
+	send := func(method, path string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, path, nil)
+		req.Header.Add("Authorization", "Bearer "+depToken)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	ids := map[string]string{}
+	for _, name := range []string{"Design", "Build", "Ship"} {
+		jsonValue, _ := json.Marshal(map[string]interface{}{"name": name})
+		req, _ := http.NewRequest("POST", "/api/task", bytes.NewBuffer(jsonValue))
+		req.Header.Add("Authorization", "Bearer "+depToken)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusCreated, w.Code)
+
+		var task model.Task
+		json.Unmarshal([]byte(w.Body.String()), &task)
+		ids[name] = strconv.FormatInt(task.Id, 10)
+	}
+
+	getTask := func(name string) (task model.Task) {
+		w := send("GET", "/api/task/"+ids[name])
+		json.Unmarshal([]byte(w.Body.String()), &task)
+		return
+	}
+
+	// Design blocks Build, Build blocks Ship
+	w := send("PUT", "/api/task/"+ids["Build"]+"/blocker/"+ids["Design"])
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = send("PUT", "/api/task/"+ids["Ship"]+"/blocker/"+ids["Build"])
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = send("PUT", "/api/task/"+ids["Ship"]+"/blocker/"+ids["Build"])
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = send("PUT", "/api/task/"+ids["Design"]+"/blocker/"+ids["Ship"])
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Equal(t, "task_dependency_cycle", errorCode(w))
+
+	w = send("PUT", "/api/task/"+ids["Design"]+"/blocker/"+ids["Design"])
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Equal(t, "task_dependency_cycle", errorCode(w))
+
+	w = send("PUT", "/api/task/"+ids["Design"]+"/blocker/9999")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, "task_blocker_not_found", errorCode(w))
+
+	build := getTask("Build")
+	assert.True(t, build.Blocked)
+	assert.Len(t, build.Blockers, 1)
+	assert.Equal(t, "Design", build.Blockers[0].Name)
+	assert.Len(t, build.Dependents, 1)
+	assert.Equal(t, "Ship", build.Dependents[0].Name)
+
+	w = send("GET", "/api/task?blocked=true")
+	assert.Equal(t, http.StatusOK, w.Code)
+	var page struct {
+		Data []model.Task `json:"data"`
+	}
+	json.Unmarshal([]byte(w.Body.String()), &page)
+	assert.Len(t, page.Data, 2)
+
+	w = send("GET", "/api/task?blocked=maybe")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "invalid_blocked", errorCode(w))
+
+	w = send("PUT", "/api/task/"+ids["Build"]+"/start_progress")
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Equal(t, "task_blocked", errorCode(w))
+	assert.Contains(t, w.Body.String(), `"blockers":[`+ids["Design"]+`]`)
+	assert.Equal(t, model.StatusCreated, getTask("Build").Status)
+
+	w = send("PUT", "/api/task/"+ids["Design"]+"/done")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.False(t, getTask("Build").Blocked)
+
+	w = send("PUT", "/api/task/"+ids["Build"]+"/start_progress")
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// once removed the blocker does not block any more
+	w = send("DELETE", "/api/task/"+ids["Ship"]+"/blocker/"+ids["Build"])
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.False(t, getTask("Ship").Blocked)
+	assert.Empty(t, getTask("Build").Dependents)
+
+	w = send("PUT", "/api/task/"+ids["Ship"]+"/start_progress")
+	assert.Equal(t, http.StatusOK, w.Code)
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"todo/internal/model"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMoveTask(t *testing.T) {
+	boardToken, _ := registerAndLogin("hakeem", "dreamshake34")
+
+	ids := map[string]string{}
+	for _, task := range []map[string]interface{}{
+		{"name": "Alpha", "priority": "low"},
+		{"name": "Bravo"},
+		{"name": "Charlie", "priority": "urgent"},
+	} {
+		jsonValue, _ := json.Marshal(task)
+		req, _ := http.NewRequest("POST", "/api/task", bytes.NewBuffer(jsonValue))
+		req.Header.Add("Authorization", "Bearer "+boardToken)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusCreated, w.Code)
+
+		result := make(map[string]interface{})
+		json.Unmarshal([]byte(w.Body.String()), &result)
+		ids[task["name"].(string)] = strconv.Itoa(int(result["id"].(float64)))
+	}
+
+	column := func(query string) (names []string) {
+		req, _ := http.NewRequest("GET", "/api/task?"+query, nil)
+		req.Header.Add("Authorization", "Bearer "+boardToken)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var result struct {
+			Data []model.Task `json:"data"`
+		}
+		json.Unmarshal([]byte(w.Body.String()), &result)
+
+		for _, task := range result.Data {
+			names = append(names, task.Name)
+		}
+		return
+	}
+
+	move := func(name string, body map[string]interface{}) *httptest.ResponseRecorder {
+		jsonValue, _ := json.Marshal(body)
+		req, _ := http.NewRequest("PUT", "/api/task/"+ids[name]+"/move", bytes.NewBuffer(jsonValue))
+		req.Header.Add("Authorization", "Bearer "+boardToken)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	assert.Equal(t, []string{"Alpha", "Bravo", "Charlie"}, column("status=created&sort=position"))
+	assert.Equal(t, []string{"Charlie", "Bravo", "Alpha"}, column("sort=-priority"))
+
+	w := move("Charlie", map[string]interface{}{"after": ids["Alpha"]})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []string{"Alpha", "Charlie", "Bravo"}, column("status=created&sort=position"))
+
+	w = move("Bravo", map[string]interface{}{"before": ids["Alpha"]})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []string{"Bravo", "Alpha", "Charlie"}, column("status=created&sort=position"))
+
+	w = move("Charlie", map[string]interface{}{"status": model.StatusInProgress})
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var task model.Task
+	json.Unmarshal([]byte(w.Body.String()), &task)
+	assert.Equal(t, model.StatusInProgress, task.Status)
+	assert.NotNil(t, task.StartedAt)
+
+	id, _ := strconv.Atoi(ids["Charlie"])
+	w = move("Alpha", map[string]interface{}{"status": model.StatusInProgress, "before": id})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []string{"Alpha", "Charlie"}, column("status=in_progress&sort=position"))
+	assert.Equal(t, []string{"Bravo"}, column("status=created&sort=position"))
+
+	// neighbour must be in the target status
+	w = move("Alpha", map[string]interface{}{"after": ids["Bravo"]})
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Equal(t, "move_task_invalid_neighbour", errorCode(w))
+
+	w = move("Alpha", map[string]interface{}{"after": "9999"})
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Equal(t, "move_task_invalid_neighbour", errorCode(w))
+
+	// unfinished tasks go back to the created column
+	w = move("Alpha", map[string]interface{}{"status": model.StatusCreated})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []string{"Bravo", "Alpha"}, column("status=created&sort=position"))
+
+	// status changes put the task to the end of the new column
+	status := func(name, action string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("PUT", "/api/task/"+ids[name]+"/"+action, nil)
+		req.Header.Add("Authorization", "Bearer "+boardToken)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w = status("Bravo", "start_progress")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []string{"Charlie", "Bravo"}, column("status=in_progress&sort=position"))
+
+	w = status("Charlie", "pause")
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = status("Charlie", "start_progress")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []string{"Bravo", "Charlie"}, column("status=in_progress&sort=position"))
+
+	// done is final
+	w = status("Bravo", "done")
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = move("Bravo", map[string]interface{}{"status": model.StatusCreated})
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	w = move("Alpha", map[string]interface{}{"status": "archived"})
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Equal(t, "move_task_invalid_status", errorCode(w))
+
+	jsonValue, _ := json.Marshal(map[string]interface{}{"name": "Delta", "priority": "asap"})
+	req, _ := http.NewRequest("POST", "/api/task", bytes.NewBuffer(jsonValue))
+	req.Header.Add("Authorization", "Bearer "+boardToken)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Equal(t, "task_invalid_priority", errorCode(w))
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"todo/internal/model"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecurringTasks(t *testing.T) {
+	recurToken, _ := registerAndLogin("tim", "groundhog21")
+
+	send := func(method, path string, body map[string]interface{}) *httptest.ResponseRecorder {
+		jsonValue, _ := json.Marshal(body)
+		req, _ := http.NewRequest(method, path, bytes.NewBuffer(jsonValue))
+		req.Header.Add("Authorization", "Bearer "+recurToken)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := send("POST", "/api/task", map[string]interface{}{
+		"name":         "Take out trash",
+		"due_at":       "2030-01-07T09:00",
+		"due_timezone": "Europe/Amsterdam",
+		"recurrence":   "freq=weekly;byday=th,mo",
+	})
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var created model.Task
+	json.Unmarshal([]byte(w.Body.String()), &created)
+	taskId := strconv.FormatInt(created.Id, 10)
+
+	w = send("GET", "/api/task/"+taskId, nil)
+	assert.Contains(t, w.Body.String(), `"recurrence":"FREQ=WEEKLY;BYDAY=MO,TH"`)
+
+	w = send("GET", "/api/task/"+taskId+"/occurrences?count=3", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `["2030-01-10T09:00:00+01:00","2030-01-14T09:00:00+01:00","2030-01-17T09:00:00+01:00"]`, w.Body.String())
+
+	w = send("GET", "/api/task/"+taskId+"/occurrences?count=0", nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "invalid_count", errorCode(w))
+
+	w = send("PUT", "/api/task/"+taskId+"/done", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = send("GET", "/api/task?status=created", nil)
+	var page struct {
+		Data []model.Task `json:"data"`
+	}
+	json.Unmarshal([]byte(w.Body.String()), &page)
+	assert.Len(t, page.Data, 1)
+	next := page.Data[0]
+	assert.Equal(t, "Take out trash", next.Name)
+	assert.NotEqual(t, created.Id, next.Id)
+	assert.Contains(t, w.Body.String(), `"due_at":"2030-01-10T09:00:00+01:00"`)
+	assert.Equal(t, "FREQ=WEEKLY;BYDAY=MO,TH", next.Recurrence)
+
+	w = send("POST", "/api/task", map[string]interface{}{"name": "Water plants", "recurrence": "FREQ=DAILY"})
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Equal(t, "task_recurrence_without_due_at", errorCode(w))
+
+	w = send("POST", "/api/task", map[string]interface{}{"name": "Water plants", "due_at": "2030-01-07T09:00:00Z", "recurrence": "FREQ=YEARLY"})
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Equal(t, "task_invalid_recurrence", errorCode(w))
+
+	w = send("POST", "/api/task", map[string]interface{}{"name": "Water plants once"})
+	json.Unmarshal([]byte(w.Body.String()), &created)
+	w = send("GET", "/api/task/"+strconv.FormatInt(created.Id, 10)+"/occurrences", nil)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Equal(t, "task_not_recurring", errorCode(w))
+}
@@ -0,0 +1,580 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"todo/internal/model"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewTask(t *testing.T) {
+	token, _ := registerAndLogin("magic", "johnson32")
+
+	w := send(token, "POST", "/api/task", map[string]interface{}{"name": "New Task"})
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	result := make(map[string]interface{})
+	json.Unmarshal([]byte(w.Body.String()), &result)
+
+	assert.NotZero(t, result["id"])
+	assert.NotEmpty(t, result["public_id"])
+}
+
+func TestGetTask(t *testing.T) {
+	token, _ := registerAndLogin("magic", "johnson32")
+
+	w := send(token, "POST", "/api/task", map[string]interface{}{"name": "New Task"})
+	created := make(map[string]interface{})
+	json.Unmarshal([]byte(w.Body.String()), &created)
+
+	w = send(token, "GET", "/api/task/"+strconv.Itoa(int(created["id"].(float64))), nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	result := make(map[string]interface{})
+	json.Unmarshal([]byte(w.Body.String()), &result)
+
+	assert.Equal(t, created["id"], result["id"])
+	assert.Equal(t, created["public_id"], result["public_id"])
+	assert.Equal(t, "New Task", result["name"].(string))
+	assert.Equal(t, model.StatusCreated, result["status"].(string))
+}
+
+func TestEditTask(t *testing.T) {
+	token, _ := registerAndLogin("magic", "johnson32")
+	taskPath := createTask(t, token, map[string]interface{}{"name": "New Task"})
+
+	w := send(token, "PUT", taskPath, map[string]interface{}{
+		"name":        "Changed Task",
+		"description": "Lorem ipsum",
+	})
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	task := getTask(t, token, taskPath)
+	assert.Equal(t, "Changed Task", task.Name)
+	assert.Equal(t, "Lorem ipsum", task.Description)
+}
+
+func TestStartTaskProgress(t *testing.T) {
+	token, _ := registerAndLogin("magic", "johnson32")
+	taskPath := createTask(t, token, map[string]interface{}{"name": "New Task"})
+
+	w := send(token, "PUT", taskPath+"/start_progress", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	assert.Equal(t, model.StatusInProgress, getTask(t, token, taskPath).Status)
+}
+
+func TestPauseTask(t *testing.T) {
+	token, _ := registerAndLogin("magic", "johnson32")
+	taskPath := createTask(t, token, map[string]interface{}{"name": "New Task"})
+	send(token, "PUT", taskPath+"/start_progress", nil)
+
+	w := send(token, "PUT", taskPath+"/pause", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	assert.Equal(t, model.StatusPaused, getTask(t, token, taskPath).Status)
+}
+
+func TestDoneTask(t *testing.T) {
+	token, _ := registerAndLogin("magic", "johnson32")
+	taskPath := createTask(t, token, map[string]interface{}{"name": "New Task"})
+	send(token, "PUT", taskPath+"/start_progress", nil)
+
+	w := send(token, "PUT", taskPath+"/done", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	assert.Equal(t, model.StatusDone, getTask(t, token, taskPath).Status)
+}
+
+func TestIllegalStatusTransition(t *testing.T) {
+	token, _ := registerAndLogin("magic", "johnson32")
+	taskPath := createTask(t, token, map[string]interface{}{"name": "New Task"})
+	send(token, "PUT", taskPath+"/done", nil)
+
+	w := send(token, "PUT", taskPath+"/pause", nil)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	result := make(map[string]interface{})
+	json.Unmarshal([]byte(w.Body.String()), &result)
+
+	assert.Equal(t, model.StatusDone, result["current_status"].(string))
+	assert.Equal(t, model.StatusPaused, result["requested_status"].(string))
+}
+
+func TestDeleteTask(t *testing.T) {
+	token, _ := registerAndLogin("magic", "johnson32")
+	taskPath := createTask(t, token, map[string]interface{}{"name": "New Task"})
+
+	w := send(token, "DELETE", taskPath, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// only the status is changed
+	task := getTask(t, token, taskPath)
+	assert.Equal(t, model.StatusDeleted, task.Status)
+	assert.NotNil(t, task.DeletedAt)
+}
+
+func TestEditTaskKeepsFields(t *testing.T) {
+	accessToken, _ := registerAndLogin("dominique", "wilkins21")
+
+	send := func(method, path string, body map[string]interface{}) *httptest.ResponseRecorder {
+		jsonValue, _ := json.Marshal(body)
+		req, _ := http.NewRequest(method, path, bytes.NewBuffer(jsonValue))
+		req.Header.Add("Authorization", "Bearer "+accessToken)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	getTask := func(path string) model.Task {
+		var task model.Task
+		json.Unmarshal([]byte(send("GET", path, nil).Body.String()), &task)
+		return task
+	}
+
+	w := send("POST", "/api/project", map[string]interface{}{"name": "Highlight reel"})
+	var project model.Project
+	json.Unmarshal([]byte(w.Body.String()), &project)
+
+	w = send("POST", "/api/task", map[string]interface{}{"name": "Dunk contest"})
+	var parent model.Task
+	json.Unmarshal([]byte(w.Body.String()), &parent)
+
+	w = send("POST", "/api/task", map[string]interface{}{
+		"name":             "Windmill",
+		"description":      "Practice",
+		"priority":         "high",
+		"due_at":           "2030-02-10T09:00:00",
+		"due_timezone":     "Europe/Amsterdam",
+		"recurrence":       "FREQ=WEEKLY;BYDAY=MO",
+		"parent_id":        parent.Id,
+		"project_id":       project.Id,
+		"estimate_minutes": 45,
+	})
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var created model.Task
+	json.Unmarshal([]byte(w.Body.String()), &created)
+	taskPath := "/api/task/" + strconv.FormatInt(created.Id, 10)
+	before := getTask(taskPath)
+
+	// the name alone changes nothing else
+	w = send("PUT", taskPath, map[string]interface{}{"name": "Tomahawk"})
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	after := getTask(taskPath)
+	assert.Equal(t, "Tomahawk", after.Name)
+	assert.Equal(t, "Practice", after.Description)
+	assert.Equal(t, "high", after.Priority)
+	assert.True(t, before.DueAt.Equal(*after.DueAt))
+	assert.Equal(t, "Europe/Amsterdam", after.DueTimezone)
+	assert.Equal(t, "FREQ=WEEKLY;BYDAY=MO", after.Recurrence)
+	assert.Equal(t, parent.Id, *after.ParentId)
+	assert.Equal(t, project.Id, *after.ProjectId)
+	assert.Equal(t, int32(45), *after.EstimateMinutes)
+
+	// null clears an optional field, empty name is still rejected
+	w = send("PUT", taskPath, map[string]interface{}{"project_id": nil, "estimate_minutes": nil})
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	after = getTask(taskPath)
+	assert.Equal(t, "Tomahawk", after.Name)
+	assert.Nil(t, after.ProjectId)
+	assert.Nil(t, after.EstimateMinutes)
+	assert.NotNil(t, after.DueAt)
+
+	w = send("PUT", taskPath, map[string]interface{}{"name": ""})
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+
+	w = send("PUT", taskPath, map[string]interface{}{"due_at": nil})
+	assert.Equal(t, "task_recurrence_without_due_at", errorCode(w))
+
+	// concurrent edits of different fields are all kept
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		body := map[string]interface{}{"description": "Edit " + strconv.Itoa(i)}
+		if i%2 == 1 {
+			body = map[string]interface{}{"estimate_minutes": i}
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			send("PUT", taskPath, body)
+		}()
+	}
+	wg.Wait()
+
+	after = getTask(taskPath)
+	assert.True(t, strings.HasPrefix(after.Description, "Edit "))
+	assert.NotNil(t, after.EstimateMinutes)
+}
+
+func TestTaskIsolation(t *testing.T) {
+	token, _ := registerAndLogin("magic", "johnson32")
+	taskPath := createTask(t, token, map[string]interface{}{"name": "New Task"})
+	send(token, "DELETE", taskPath, nil)
+
+	otherToken, _ := registerAndLogin("scottie", "pippen33")
+
+	w := send(otherToken, "GET", taskPath, nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = send(otherToken, "PUT", taskPath+"/restore", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = send(otherToken, "GET", "/api/task?status="+model.StatusDeleted, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `{"data":[],"total":0}`, w.Body.String())
+}
+
+func TestRestoreTask(t *testing.T) {
+	token, _ := registerAndLogin("magic", "johnson32")
+	taskPath := createTask(t, token, map[string]interface{}{"name": "New Task"})
+	send(token, "PUT", taskPath+"/done", nil)
+	send(token, "DELETE", taskPath, nil)
+
+	w := send(token, "PUT", taskPath+"/restore", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// task is back in the status it had before deletion
+	assert.Equal(t, model.StatusDone, getTask(t, token, taskPath).Status)
+
+	w = send(token, "PUT", taskPath+"/restore", nil)
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
+
+func TestTrashedTaskStatus(t *testing.T) {
+	trashToken, _ := registerAndLogin("karl", "mailman32")
+
+	send := func(method, path string, body map[string]interface{}) *httptest.ResponseRecorder {
+		jsonValue, _ := json.Marshal(body)
+		req, _ := http.NewRequest(method, path, bytes.NewBuffer(jsonValue))
+		req.Header.Add("Authorization", "Bearer "+trashToken)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := send("POST", "/api/task", map[string]interface{}{"name": "Trashed"})
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var created model.Task
+	json.Unmarshal([]byte(w.Body.String()), &created)
+	taskPath := "/api/task/" + strconv.FormatInt(created.Id, 10)
+
+	w = send("DELETE", taskPath, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// only restore takes the task out of trash
+	for _, action := range []string{"done", "start_progress", "pause"} {
+		w = send("PUT", taskPath+"/"+action, nil)
+		assert.Equal(t, http.StatusConflict, w.Code)
+
+		result := make(map[string]interface{})
+		json.Unmarshal([]byte(w.Body.String()), &result)
+		assert.Equal(t, model.StatusDeleted, result["current_status"])
+	}
+
+	w = send("PUT", taskPath+"/move", map[string]interface{}{"status": model.StatusCreated})
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	w = send("GET", taskPath, nil)
+	var task model.Task
+	json.Unmarshal([]byte(w.Body.String()), &task)
+	assert.Equal(t, model.StatusDeleted, task.Status)
+	assert.NotNil(t, task.DeletedAt)
+
+	w = send("PUT", taskPath+"/restore", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = send("PUT", taskPath+"/done", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestTaskHistory(t *testing.T) {
+	token, _ := registerAndLogin("magic", "johnson32")
+	taskPath := createTask(t, token, map[string]interface{}{"name": "New Task"})
+	for _, action := range []string{"start_progress", "pause", "done"} {
+		send(token, "PUT", taskPath+"/"+action, nil)
+	}
+	send(token, "DELETE", taskPath, nil)
+	send(token, "PUT", taskPath+"/restore", nil)
+
+	w := send(token, "GET", taskPath+"/history", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var history []model.TaskStatusChange
+	json.Unmarshal([]byte(w.Body.String()), &history)
+
+	var transitions []string
+	for _, change := range history {
+		transitions = append(transitions, change.FromStatus+">"+change.ToStatus)
+		assert.NotZero(t, change.ChangedBy)
+		assert.False(t, change.ChangedAt.IsZero())
+	}
+	assert.Equal(t, []string{
+		">" + model.StatusCreated,
+		model.StatusCreated + ">" + model.StatusInProgress,
+		model.StatusInProgress + ">" + model.StatusPaused,
+		model.StatusPaused + ">" + model.StatusDone,
+		model.StatusDone + ">" + model.StatusDeleted,
+		model.StatusDeleted + ">" + model.StatusDone,
+	}, transitions)
+
+	task := getTask(t, token, taskPath)
+	assert.False(t, task.CreatedAt.IsZero())
+	assert.False(t, task.UpdatedAt.Before(task.CreatedAt))
+	assert.NotNil(t, task.StartedAt)
+	assert.NotNil(t, task.CompletedAt)
+	assert.Nil(t, task.DeletedAt) // restored
+}
+
+func TestMissingTask(t *testing.T) {
+	token, _ := registerAndLogin("magic", "johnson32")
+
+	requests := []struct {
+		method string
+		path   string
+	}{
+		{"GET", "/api/task/9999"},
+		{"PUT", "/api/task/9999"},
+		{"PUT", "/api/task/9999/start_progress"},
+		{"PUT", "/api/task/9999/pause"},
+		{"PUT", "/api/task/9999/done"},
+		{"DELETE", "/api/task/9999"},
+		{"PUT", "/api/task/9999/restore"},
+		{"DELETE", "/api/task/9999/completely"},
+		{"GET", "/api/task/9999/history"},
+	}
+
+	jsonValue, _ := json.Marshal(map[string]interface{}{
+		"name": "Missing Task",
+	})
+
+	for _, item := range requests {
+		req, _ := http.NewRequest(item.method, item.path, bytes.NewBuffer(jsonValue))
+		req.Header.Add("Authorization", "Bearer "+token)
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusNotFound, w.Code, item.method+" "+item.path)
+		assert.Equal(t, "task_not_found", errorCode(w), item.method+" "+item.path)
+	}
+}
+
+func TestErrorResponse(t *testing.T) {
+	token, _ := registerAndLogin("magic", "johnson32")
+
+	jsonValue, _ := json.Marshal(map[string]interface{}{
+		"name": " ",
+	})
+	req, _ := http.NewRequest("POST", "/api/task", bytes.NewBuffer(jsonValue))
+	req.Header.Add("Authorization", "Bearer "+token)
+	req.Header.Add("X-Request-Id", "test-request-id")
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Equal(t, "application/problem+json", w.Header().Get("Content-Type"))
+
+	result := make(map[string]interface{})
+	json.Unmarshal([]byte(w.Body.String()), &result)
+
+	assert.Equal(t, "create_task_failure_name_is_required", result["code"])
+	assert.Equal(t, float64(http.StatusUnprocessableEntity), result["status"])
+	assert.Equal(t, "test-request-id", result["request_id"])
+	assert.Equal(t, "/api/task", result["instance"])
+	assert.NotEmpty(t, result["detail"])
+
+	req, _ = http.NewRequest("GET", "/api/task/abc", nil)
+	req.Header.Add("Authorization", "Bearer "+token)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "invalid_task_id", errorCode(w))
+
+	req, _ = http.NewRequest("GET", "/api/task", nil)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Equal(t, "invalid_token", errorCode(w))
+}
+
+func TestGetTaskListPagination(t *testing.T) {
+	listToken, _ := registerAndLogin("toni", "kukoc777")
+
+	for _, name := range []string{"Alpha", "Bravo", "Charlie", "Delta", "Echo"} {
+		jsonValue, _ := json.Marshal(map[string]interface{}{
+			"name":        name,
+			"description": "board task",
+		})
+		req, _ := http.NewRequest("POST", "/api/task", bytes.NewBuffer(jsonValue))
+		req.Header.Add("Authorization", "Bearer "+listToken)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusCreated, w.Code)
+	}
+
+	var names []string
+	cursor := ""
+	for page := 0; page < 5; page++ {
+		req, _ := http.NewRequest("GET", "/api/task?sort=-name&limit=2&cursor="+cursor, nil)
+		req.Header.Add("Authorization", "Bearer "+listToken)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var result struct {
+			Data []struct {
+				Name string `json:"name"`
+			} `json:"data"`
+			Total      int    `json:"total"`
+			NextCursor string `json:"next_cursor"`
+		}
+		json.Unmarshal([]byte(w.Body.String()), &result)
+
+		assert.Equal(t, 5, result.Total)
+		for _, item := range result.Data {
+			names = append(names, item.Name)
+		}
+
+		cursor = result.NextCursor
+		if cursor == "" {
+			break
+		}
+	}
+	assert.Equal(t, []string{"Echo", "Delta", "Charlie", "Bravo", "Alpha"}, names)
+
+	req, _ := http.NewRequest("GET", "/api/task?status=created,done&q=HARL", nil)
+	req.Header.Add("Authorization", "Bearer "+listToken)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	result := make(map[string]interface{})
+	json.Unmarshal([]byte(w.Body.String()), &result)
+	assert.Equal(t, float64(1), result["total"])
+
+	req, _ = http.NewRequest("GET", "/api/task?sort=owner", nil)
+	req.Header.Add("Authorization", "Bearer "+listToken)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "invalid_sort", errorCode(w))
+}
+
+func TestTaskIdParam(t *testing.T) {
+	token, _ := registerAndLogin("magic", "johnson32")
+	taskPath := createTask(t, token, map[string]interface{}{"name": "New Task"})
+	task := getTask(t, token, taskPath)
+
+	w := send(token, "GET", "/api/task/"+task.PublicId, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	result := make(map[string]interface{})
+	json.Unmarshal([]byte(w.Body.String()), &result)
+	assert.Equal(t, task.Id, int64(result["id"].(float64)))
+
+	// used to be truncated to uint16 and resolve to task 1
+	w = send(token, "GET", "/api/task/"+strconv.Itoa(65536+int(task.Id)), nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	for _, param := range []string{"99999999999999999999", "-1", "0", "not-a-uuid"} {
+		w = send(token, "GET", "/api/task/"+param, nil)
+		assert.Equal(t, http.StatusBadRequest, w.Code, param)
+		assert.Equal(t, "invalid_task_id", errorCode(w), param)
+	}
+}
+
+func TestTaskDueDate(t *testing.T) {
+	dueToken, _ := registerAndLogin("kareem", "skyhook33")
+
+	tasks := []map[string]interface{}{
+		{"name": "Past", "due_at": "2020-01-01T10:00", "due_timezone": "Europe/Amsterdam"},
+		{"name": "Future", "due_at": "2999-01-01T10:00:00Z"},
+		{"name": "Someday"},
+	}
+	for _, task := range tasks {
+		jsonValue, _ := json.Marshal(task)
+		req, _ := http.NewRequest("POST", "/api/task", bytes.NewBuffer(jsonValue))
+		req.Header.Add("Authorization", "Bearer "+dueToken)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusCreated, w.Code)
+	}
+
+	list := func(query string) (names []string, dueAt []string) {
+		cursor := ""
+		for page := 0; page < 5; page++ {
+			req, _ := http.NewRequest("GET", "/api/task?limit=1&"+query+"&cursor="+cursor, nil)
+			req.Header.Add("Authorization", "Bearer "+dueToken)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			assert.Equal(t, http.StatusOK, w.Code, query)
+
+			var result struct {
+				Data []struct {
+					Name  string  `json:"name"`
+					DueAt *string `json:"due_at"`
+				} `json:"data"`
+				NextCursor string `json:"next_cursor"`
+			}
+			json.Unmarshal([]byte(w.Body.String()), &result)
+
+			for _, item := range result.Data {
+				names = append(names, item.Name)
+				if item.DueAt != nil {
+					dueAt = append(dueAt, *item.DueAt)
+				}
+			}
+
+			cursor = result.NextCursor
+			if cursor == "" {
+				break
+			}
+		}
+		return
+	}
+
+	names, dueAt := list("sort=due_at")
+	assert.Equal(t, []string{"Past", "Future", "Someday"}, names)
+	// local time is kept in the task timezone
+	assert.Equal(t, []string{"2020-01-01T10:00:00+01:00", "2999-01-01T10:00:00Z"}, dueAt)
+
+	names, _ = list("sort=-due_at")
+	assert.Equal(t, []string{"Someday", "Future", "Past"}, names)
+
+	names, _ = list("overdue=true")
+	assert.Equal(t, []string{"Past"}, names)
+
+	names, _ = list("due_before=2999-01-01T11:00:00%2B01:00")
+	assert.Equal(t, []string{"Past"}, names)
+
+	requests := []struct {
+		method string
+		path   string
+		body   map[string]interface{}
+		status int
+		code   string
+	}{
+		{"POST", "/api/task", map[string]interface{}{"name": "Bad", "due_at": "tomorrow"}, http.StatusUnprocessableEntity, "task_invalid_due_at"},
+		{"POST", "/api/task", map[string]interface{}{"name": "Bad", "due_at": "2030-01-01T10:00", "due_timezone": "Mars/Olympus"}, http.StatusUnprocessableEntity, "task_invalid_due_timezone"},
+		{"GET", "/api/task?due_before=tomorrow", nil, http.StatusBadRequest, "invalid_due_before"},
+		{"GET", "/api/task?overdue=maybe", nil, http.StatusBadRequest, "invalid_overdue"},
+	}
+	for _, item := range requests {
+		jsonValue, _ := json.Marshal(item.body)
+		req, _ := http.NewRequest(item.method, item.path, bytes.NewBuffer(jsonValue))
+		req.Header.Add("Authorization", "Bearer "+dueToken)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, item.status, w.Code, item.path)
+		assert.Equal(t, item.code, errorCode(w), item.path)
+	}
+}
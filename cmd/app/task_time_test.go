@@ -0,0 +1,118 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+	"todo/internal/model"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTaskTime(t *testing.T) {
+	accessToken, _ := registerAndLogin("vinnie", "johnson15")
+
+	send := func(method, path string, body map[string]interface{}) *httptest.ResponseRecorder {
+		jsonValue, _ := json.Marshal(body)
+		req, _ := http.NewRequest(method, path, bytes.NewBuffer(jsonValue))
+		req.Header.Add("Authorization", "Bearer "+accessToken)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	getTime := func(path string) model.TaskTime {
+		var result model.TaskTime
+		json.Unmarshal([]byte(send("GET", path+"/time", nil).Body.String()), &result)
+		return result
+	}
+
+	w := send("POST", "/api/task", map[string]interface{}{"name": "Microwave jumper", "estimate_minutes": 120})
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var created model.Task
+	json.Unmarshal([]byte(w.Body.String()), &created)
+	taskPath := "/api/task/" + strconv.FormatInt(created.Id, 10)
+
+	w = send("GET", taskPath, nil)
+	assert.Contains(t, w.Body.String(), `"estimate_minutes":120`)
+
+	for _, estimate := range []interface{}{-5, "-5", 0, 1.5, "half an hour"} {
+		w = send("POST", "/api/task", map[string]interface{}{"name": "Invalid estimate", "estimate_minutes": estimate})
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		assert.Equal(t, "task_invalid_estimate", errorCode(w))
+	}
+
+	w = send("POST", "/api/task", map[string]interface{}{"name": "Estimate as string", "estimate_minutes": "90"})
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	// start opens the entry and pause closes it
+	send("PUT", taskPath+"/start_progress", nil)
+
+	result := getTime(taskPath)
+	assert.Len(t, result.Entries, 1)
+	assert.Nil(t, result.Entries[0].EndedAt)
+	assert.False(t, result.Entries[0].Manual)
+
+	send("PUT", taskPath+"/pause", nil)
+	send("PUT", taskPath+"/start_progress", nil)
+	send("PUT", taskPath+"/done", nil)
+
+	result = getTime(taskPath)
+	assert.Len(t, result.Entries, 2)
+	for _, entry := range result.Entries {
+		assert.NotNil(t, entry.EndedAt)
+	}
+
+	// manual entry
+	w = send("POST", taskPath+"/time", map[string]interface{}{
+		"started_at": "2024-03-01T09:00:00Z",
+		"ended_at":   "2024-03-01T10:30:00Z",
+		"note":       "Film session",
+	})
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Contains(t, w.Body.String(), `"manual":true`)
+	assert.Contains(t, w.Body.String(), `"seconds":5400`)
+
+	result = getTime(taskPath)
+	assert.Len(t, result.Entries, 3)
+	assert.Equal(t, "Film session", result.Entries[0].Note)
+	assert.GreaterOrEqual(t, result.TotalSeconds, int64(5400))
+	assert.Equal(t, int32(120), *result.EstimateMinutes)
+
+	w = send("POST", taskPath+"/time", map[string]interface{}{
+		"started_at": "2024-03-01T10:30:00Z",
+		"ended_at":   "2024-03-01T09:00:00Z",
+	})
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Equal(t, "time_entry_invalid_ended_at", errorCode(w))
+
+	w = send("POST", taskPath+"/time", map[string]interface{}{
+		"started_at": time.Now().Format(time.RFC3339),
+		"ended_at":   time.Now().Add(time.Hour).Format(time.RFC3339),
+	})
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+
+	w = send("POST", taskPath+"/time", map[string]interface{}{"ended_at": "2024-03-01T09:00:00Z"})
+	assert.Equal(t, "time_entry_invalid_started_at", errorCode(w))
+
+	// move into progress opens the entry and trash closes it
+	w = send("POST", "/api/task", map[string]interface{}{"name": "Sixth man"})
+	json.Unmarshal([]byte(w.Body.String()), &created)
+	otherPath := "/api/task/" + strconv.FormatInt(created.Id, 10)
+
+	send("PUT", otherPath+"/move", map[string]interface{}{"status": "in_progress"})
+	result = getTime(otherPath)
+	assert.Len(t, result.Entries, 1)
+	assert.Nil(t, result.Entries[0].EndedAt)
+	assert.Nil(t, result.EstimateMinutes)
+
+	send("DELETE", otherPath, nil)
+	result = getTime(otherPath)
+	assert.NotNil(t, result.Entries[0].EndedAt)
+
+	w = send("GET", "/api/task/999999/time", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
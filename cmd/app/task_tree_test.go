@@ -0,0 +1,119 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"todo/internal/model"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubtasks(t *testing.T) {
+	treeToken, _ := registerAndLogin("shaquille", "diesel32")
+
+	send := func(method, path string, body map[string]interface{}) *httptest.ResponseRecorder {
+		jsonValue, _ := json.Marshal(body)
+		req, _ := http.NewRequest(method, path, bytes.NewBuffer(jsonValue))
+		req.Header.Add("Authorization", "Bearer "+treeToken)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	ids := map[string]string{}
+	create := func(name, parent string) *httptest.ResponseRecorder {
+		body := map[string]interface{}{"name": name}
+		if parent != "" {
+			body["parent_id"] = ids[parent]
+		}
+		w := send("POST", "/api/task", body)
+
+		result := make(map[string]interface{})
+		json.Unmarshal([]byte(w.Body.String()), &result)
+		if id, ok := result["id"].(float64); ok {
+			ids[name] = strconv.Itoa(int(id))
+		}
+		return w
+	}
+
+	for _, item := range [][2]string{{"Release", ""}, {"Build", "Release"}, {"Test", "Release"}, {"Unit", "Test"}, {"Docs", ""}} {
+		assert.Equal(t, http.StatusCreated, create(item[0], item[1]).Code)
+	}
+
+	w := send("PUT", "/api/task/"+ids["Build"]+"/done", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	getTask := func(name string) (task model.Task) {
+		w := send("GET", "/api/task/"+ids[name], nil)
+		json.Unmarshal([]byte(w.Body.String()), &task)
+		return
+	}
+
+	assert.Equal(t, model.TaskProgress{Done: 1, Total: 2}, getTask("Release").Progress)
+
+	w = send("GET", "/api/task/"+ids["Release"]+"/children", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var children []model.Task
+	json.Unmarshal([]byte(w.Body.String()), &children)
+	// children are grouped by status, done Build goes after created Test
+	assert.Len(t, children, 2)
+	assert.Equal(t, "Test", children[0].Name)
+	assert.Equal(t, "Build", children[1].Name)
+
+	w = send("GET", "/api/task?tree=true", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var tree struct {
+		Data []model.Task `json:"data"`
+	}
+	json.Unmarshal([]byte(w.Body.String()), &tree)
+	assert.Len(t, tree.Data, 2)
+	assert.Equal(t, "Release", tree.Data[0].Name)
+	assert.Len(t, tree.Data[0].Children, 2)
+	assert.Equal(t, "Unit", tree.Data[0].Children[0].Children[0].Name)
+
+	// a task can not be moved under its descendant
+	w = send("PUT", "/api/task/"+ids["Release"], map[string]interface{}{"name": "Release", "parent_id": ids["Unit"]})
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Equal(t, "task_parent_cycle", errorCode(w))
+
+	w = send("PUT", "/api/task/"+ids["Release"], map[string]interface{}{"name": "Release", "parent_id": ids["Release"]})
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Equal(t, "task_parent_cycle", errorCode(w))
+
+	w = send("POST", "/api/task", map[string]interface{}{"name": "Orphan", "parent_id": "9999"})
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Equal(t, "task_invalid_parent", errorCode(w))
+
+	// deleted before the parent, so it is not restored with it
+	w = send("DELETE", "/api/task/"+ids["Build"], nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = send("DELETE", "/api/task/"+ids["Release"], nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, model.StatusDeleted, getTask("Unit").Status)
+
+	w = send("PUT", "/api/task/"+ids["Unit"]+"/restore", nil)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Equal(t, "task_parent_deleted", errorCode(w))
+
+	w = send("POST", "/api/task", map[string]interface{}{"name": "Late", "parent_id": ids["Release"]})
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+
+	w = send("PUT", "/api/task/"+ids["Release"]+"/restore", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, model.StatusCreated, getTask("Unit").Status)
+	assert.Equal(t, model.StatusDeleted, getTask("Build").Status)
+	assert.Equal(t, model.TaskProgress{Done: 0, Total: 1}, getTask("Release").Progress)
+
+	w = send("DELETE", "/api/task/"+ids["Release"]+"/completely", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	for _, name := range []string{"Release", "Build", "Test", "Unit"} {
+		w = send("GET", "/api/task/"+ids[name], nil)
+		assert.Equal(t, http.StatusNotFound, w.Code, name)
+	}
+	assert.Equal(t, http.StatusOK, send("GET", "/api/task/"+ids["Docs"], nil).Code)
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"todo/internal/model"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWorkspaces(t *testing.T) {
+	ownerToken, _ := registerAndLogin("moses", "malone2")
+	editorToken, _ := registerAndLogin("julius", "erving6")
+	viewerToken, _ := registerAndLogin("bobby", "jones24")
+	strangerToken, _ := registerAndLogin("andrew", "toney22")
+
+	send := func(accessToken, workspace, method, path string, body map[string]interface{}) *httptest.ResponseRecorder {
+		jsonValue, _ := json.Marshal(body)
+		req, _ := http.NewRequest(method, path, bytes.NewBuffer(jsonValue))
+		req.Header.Add("Authorization", "Bearer "+accessToken)
+		if workspace != "" {
+			req.Header.Add("X-Workspace", workspace)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	ownId := func(accessToken string) string {
+		var list []*model.Workspace
+		json.Unmarshal([]byte(send(accessToken, "", "GET", "/api/workspace", nil).Body.String()), &list)
+		return strconv.Itoa(int(list[0].Id))
+	}
+
+	workspace := ownId(ownerToken)
+	editorId := ownId(editorToken)
+	viewerId := ownId(viewerToken)
+	membersPath := "/api/workspace/" + workspace + "/member"
+
+	w := send(ownerToken, "", "POST", "/api/task", map[string]interface{}{"name": "Scouting report"})
+	var created model.Task
+	json.Unmarshal([]byte(w.Body.String()), &created)
+	taskPath := "/api/task/" + strconv.FormatInt(created.Id, 10)
+
+	w = send(ownerToken, "", "POST", membersPath, map[string]interface{}{"login": "julius", "role": "editor"})
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Contains(t, w.Body.String(), `"accepted_at":null`)
+
+	send(ownerToken, "", "POST", membersPath, map[string]interface{}{"login": "bobby", "role": "viewer"})
+
+	w = send(ownerToken, "", "POST", membersPath, map[string]interface{}{"login": "bobby", "role": "editor"})
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Equal(t, "workspace_member_exists", errorCode(w))
+
+	w = send(ownerToken, "", "POST", membersPath, map[string]interface{}{"login": "moses", "role": "editor"})
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	w = send(ownerToken, "", "POST", membersPath, map[string]interface{}{"login": "nobody", "role": "editor"})
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Equal(t, "workspace_member_login_not_found", errorCode(w))
+
+	w = send(ownerToken, "", "POST", membersPath, map[string]interface{}{"login": "andrew", "role": "coach"})
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Equal(t, "workspace_member_invalid_role", errorCode(w))
+
+	// invitation gives no access until it is accepted
+	w = send(viewerToken, workspace, "GET", "/api/task", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, "workspace_not_found", errorCode(w))
+
+	w = send(viewerToken, "", "GET", "/api/workspace/invitation", nil)
+	assert.Contains(t, w.Body.String(), `"owner_login":"moses"`)
+
+	w = send(viewerToken, "", "PUT", "/api/workspace/"+workspace+"/accept", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	send(editorToken, "", "PUT", "/api/workspace/"+workspace+"/accept", nil)
+
+	w = send(viewerToken, "", "GET", "/api/workspace", nil)
+	var list []*model.Workspace
+	json.Unmarshal([]byte(w.Body.String()), &list)
+	assert.Len(t, list, 2)
+	assert.Equal(t, "moses", list[1].OwnerLogin)
+	assert.Equal(t, "viewer", list[1].Role)
+
+	// viewer reads and changes nothing
+	w = send(viewerToken, workspace, "GET", "/api/task", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"name":"Scouting report"`)
+
+	w = send(viewerToken, workspace, "GET", taskPath, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = send(viewerToken, workspace, "PUT", taskPath, map[string]interface{}{"name": "Changed"})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Equal(t, "workspace_action_forbidden", errorCode(w))
+	assert.Contains(t, w.Body.String(), `"role":"viewer"`)
+
+	w = send(viewerToken, workspace, "DELETE", taskPath, nil)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w = send(viewerToken, workspace, "DELETE", "/api/task/free_trash", nil)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	// own workspace of the viewer does not have the task
+	w = send(viewerToken, "", "GET", taskPath, nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	// editor changes tasks, the history tells who did it
+	w = send(editorToken, workspace, "PUT", taskPath+"/start_progress", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = send(ownerToken, "", "GET", taskPath+"/history", nil)
+	assert.Contains(t, w.Body.String(), `"to_status":"in_progress","changed_by":`+editorId)
+
+	w = send(editorToken, workspace, "DELETE", taskPath, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = send(editorToken, workspace, "DELETE", "/api/task/free_trash", nil)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w = send(editorToken, "", "POST", membersPath, map[string]interface{}{"login": "andrew", "role": "viewer"})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w = send(ownerToken, "", "PUT", membersPath+"/"+viewerId, map[string]interface{}{"role": "editor"})
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = send(viewerToken, workspace, "PUT", taskPath+"/restore", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = send(ownerToken, "", "GET", membersPath, nil)
+	var members []*model.WorkspaceMember
+	json.Unmarshal([]byte(w.Body.String()), &members)
+	assert.Len(t, members, 2)
+
+	// strangers and malformed ids
+	w = send(strangerToken, workspace, "GET", "/api/task", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = send(strangerToken, "", "GET", membersPath, nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = send(ownerToken, "abc", "GET", "/api/task", nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "invalid_workspace_id", errorCode(w))
+
+	// member leaves the workspace
+	w = send(viewerToken, "", "DELETE", membersPath+"/"+viewerId, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = send(viewerToken, workspace, "GET", "/api/task", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
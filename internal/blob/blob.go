@@ -0,0 +1,37 @@
+// Package blob stores contents of task attachments, metadata of them is kept by the model
+package blob
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+)
+
+// ErrNotFound is returned by Get when there is no blob with the key
+var ErrNotFound = errors.New("blob not found")
+
+// Store keeps blobs by slash separated keys, e.g. attachments/0b7e7dee-87b0-4b8e-9f1a-2f5c2c7c3e1a
+type Store interface {
+	// Put writes the blob of the given size, replacing the one with the same key
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Get returns reader of the blob, the caller closes it
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete does nothing if there is no blob with the key
+	Delete(ctx context.Context, key string) error
+}
+
+// validKey rejects keys which could point outside of the store
+func validKey(key string) bool {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, `\`) {
+		return false
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == "" || part == "." || part == ".." {
+			return false
+		}
+	}
+	return true
+}
+
+var errInvalidKey = errors.New("blob key is invalid")
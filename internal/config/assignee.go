@@ -0,0 +1,12 @@
+package config
+
+var autoAssign bool // default value
+
+func SetAutoAssign(assign bool) {
+	autoAssign = assign
+}
+
+// AutoAssign assigns the user starting a task without assignees to it
+func AutoAssign() bool {
+	return autoAssign
+}
@@ -0,0 +1,31 @@
+package config
+
+var attachmentMaxSize int64 = 10 << 20 // default value, 10 MiB
+
+// attachmentTypes are allowed content types of attachments, type/* allows every subtype
+var attachmentTypes = []string{ // default value
+	"image/*",
+	"text/plain",
+	"text/csv",
+	"application/pdf",
+	"application/zip",
+	"application/json",
+}
+
+func SetAttachmentMaxSize(size int64) {
+	attachmentMaxSize = size
+}
+
+// AttachmentMaxSize is the largest attachment size in bytes
+func AttachmentMaxSize() int64 {
+	return attachmentMaxSize
+}
+
+func SetAttachmentTypes(types []string) {
+	attachmentTypes = types
+}
+
+// AttachmentTypes are allowed content types of attachments, empty allows any type
+func AttachmentTypes() []string {
+	return attachmentTypes
+}
@@ -1,7 +1,12 @@
 package config
 
+import "time"
+
 var jwtSecret string
 
+var accessTokenTTL = 15 * time.Minute     // default value
+var refreshTokenTTL = 30 * 24 * time.Hour // default value
+
 func SetJwtSecret(secret string) {
 	jwtSecret = secret
 }
@@ -9,3 +14,19 @@ func SetJwtSecret(secret string) {
 func JwtSecret() string {
 	return jwtSecret
 }
+
+func SetAccessTokenTTL(ttl time.Duration) {
+	accessTokenTTL = ttl
+}
+
+func AccessTokenTTL() time.Duration {
+	return accessTokenTTL
+}
+
+func SetRefreshTokenTTL(ttl time.Duration) {
+	refreshTokenTTL = ttl
+}
+
+func RefreshTokenTTL() time.Duration {
+	return refreshTokenTTL
+}
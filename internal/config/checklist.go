@@ -0,0 +1,12 @@
+package config
+
+var strictChecklist bool // default value
+
+func SetStrictChecklist(strict bool) {
+	strictChecklist = strict
+}
+
+// StrictChecklist forbids completing a task while some of its checklist items are unchecked
+func StrictChecklist() bool {
+	return strictChecklist
+}
@@ -0,0 +1,24 @@
+package config
+
+import "time"
+
+var reminderLead = time.Hour       // default value
+var reminderInterval = time.Minute // default value
+
+func SetReminderLead(lead time.Duration) {
+	reminderLead = lead
+}
+
+// ReminderLead is how long before due date the reminder is sent
+func ReminderLead() time.Duration {
+	return reminderLead
+}
+
+func SetReminderInterval(interval time.Duration) {
+	reminderInterval = interval
+}
+
+// ReminderInterval is how often the scheduler looks for due tasks, not positive disables reminders
+func ReminderInterval() time.Duration {
+	return reminderInterval
+}
@@ -0,0 +1,36 @@
+package controller
+
+import (
+	"sort"
+)
+
+// SetTaskAssignees takes raw user ids, duplicates are ignored and empty list unassigns everybody
+func (ctrl *Controller) SetTaskAssignees(userId uint16, taskId int64, assignees []string) ([]uint16, error) {
+	userIds := []uint16{}
+	for _, assignee := range assignees {
+		id, err := parseUserId(assignee)
+		if err != nil {
+			return nil, ErrInvalidTaskAssignee.withCause(err)
+		}
+
+		if !containsUserId(userIds, id) {
+			userIds = append(userIds, id)
+		}
+	}
+
+	sort.Slice(userIds, func(i, j int) bool {
+		return userIds[i] < userIds[j]
+	})
+
+	result, err := ctrl.store.SetTaskAssignees(userId, taskId, userIds)
+	return result, taskError(err)
+}
+
+func containsUserId(list []uint16, id uint16) bool {
+	for _, item := range list {
+		if item == id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,245 @@
+package controller
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+	"todo/internal/config"
+	"todo/internal/model"
+
+	"github.com/google/uuid"
+)
+
+const maxAttachmentNameLength = 255
+
+// ParseAttachmentId parses attachment id of the path
+func ParseAttachmentId(param string) (int64, error) {
+	id, err := StringToId(param)
+	if err != nil {
+		return 0, ErrInvalidAttachmentId.withCause(err)
+	}
+
+	return id, nil
+}
+
+// attachmentName keeps the base name only, browsers of some systems send the full path
+func attachmentName(name string) (string, error) {
+	name = strings.TrimSpace(path.Base(strings.ReplaceAll(name, `\`, "/")))
+	if name == "" || name == "." || name == "/" {
+		return "", ErrAttachmentNameRequired
+	}
+	if len([]rune(name)) > maxAttachmentNameLength {
+		return "", ErrAttachmentNameTooLong
+	}
+
+	return name, nil
+}
+
+// attachmentTypeAllowed matches media type against config.AttachmentTypes, type/* allows every subtype
+func attachmentTypeAllowed(mediaType string) bool {
+	allowed := config.AttachmentTypes()
+	if len(allowed) == 0 {
+		return true
+	}
+
+	for _, item := range allowed {
+		item = strings.ToLower(item)
+		if item == mediaType || (strings.HasSuffix(item, "/*") && strings.HasPrefix(mediaType, strings.TrimSuffix(item, "*"))) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// attachmentTypeKind groups media types into the kinds sniffing tells apart reliably, html, text,
+// media, or the top level type of the others. Unknown binary content is sniffed as application
+func attachmentTypeKind(mediaType string) string {
+	switch {
+	case mediaType == "text/html":
+		return "html"
+	case strings.HasPrefix(mediaType, "text/"), mediaType == "application/json", mediaType == "application/xml",
+		mediaType == "application/javascript", strings.HasSuffix(mediaType, "+json"), strings.HasSuffix(mediaType, "+xml"):
+		return "text"
+	case strings.HasPrefix(mediaType, "audio/"), strings.HasPrefix(mediaType, "video/"), mediaType == "application/ogg":
+		return "media"
+	}
+
+	return strings.SplitN(mediaType, "/", 2)[0]
+}
+
+// attachmentContentType normalizes the declared content type. The content is always sniffed,
+// the sniffed type is used when the client does not know the type and it must not contradict the declared one
+func attachmentContentType(declared string, r *bufio.Reader) (string, error) {
+	head, _ := r.Peek(512)
+	sniffed, sniffedParams, err := mime.ParseMediaType(http.DetectContentType(head))
+	if err != nil {
+		return "", err
+	}
+
+	mediaType, params, err := mime.ParseMediaType(declared)
+	if err != nil || mediaType == "application/octet-stream" {
+		mediaType, params = sniffed, sniffedParams
+	}
+
+	if !attachmentTypeAllowed(mediaType) {
+		return "", attachmentTypeNotAllowed(mediaType)
+	}
+	if attachmentTypeKind(mediaType) != attachmentTypeKind(sniffed) {
+		e := ErrAttachmentTypeMismatch.withCause(fmt.Errorf("content type %s, sniffed %s", mediaType, sniffed))
+		e.Details = map[string]interface{}{
+			"content_type": mediaType,
+			"sniffed_type": sniffed,
+		}
+		return "", e
+	}
+	// plain text and unknown binary say nothing more than the declared type does
+	if sniffed != "text/plain" && sniffed != "application/octet-stream" && !attachmentTypeAllowed(sniffed) {
+		return "", attachmentTypeNotAllowed(sniffed)
+	}
+
+	return mime.FormatMediaType(mediaType, params), nil
+}
+
+func attachmentTypeNotAllowed(mediaType string) error {
+	e := ErrAttachmentType.withCause(fmt.Errorf("content type %s", mediaType))
+	e.Details = map[string]interface{}{
+		"content_type":  mediaType,
+		"allowed_types": config.AttachmentTypes(),
+	}
+	return e
+}
+
+func attachmentTooLarge(size int64) error {
+	e := ErrAttachmentTooLarge.withCause(fmt.Errorf("size %d", size))
+	e.Details = map[string]interface{}{
+		"max_size": config.AttachmentMaxSize(),
+	}
+	return e
+}
+
+// AddTaskAttachment writes the content to blob store before the metadata is saved,
+// the blob is deleted again if saving fails
+func (ctrl *Controller) AddTaskAttachment(userId uint16, taskId int64, uploaderId uint16, name, contentType string, size int64, r io.Reader) (*model.TaskAttachment, error) {
+	name, err := attachmentName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if size > config.AttachmentMaxSize() {
+		return nil, attachmentTooLarge(size)
+	}
+
+	content := bufio.NewReaderSize(r, 512)
+	contentType, err = attachmentContentType(contentType, content)
+	if err != nil {
+		return nil, err
+	}
+
+	// fail early instead of uploading the blob of a task which does not exist
+	_, err = ctrl.store.GetTaskAttachments(userId, taskId)
+	if err != nil {
+		return nil, attachmentError(err)
+	}
+
+	ctx := context.Background()
+	hash := sha256.New()
+	counted := &countingReader{r: io.LimitReader(content, size+1)}
+	key := "attachments/" + uuid.NewString()
+
+	err = ctrl.blobs.Put(ctx, key, io.TeeReader(counted, hash), size, contentType)
+	if err == nil && counted.n != size {
+		err = fmt.Errorf("attachment content is %d bytes instead of %d", counted.n, size)
+	}
+	if err != nil {
+		ctrl.deleteBlobs([]string{key})
+		return nil, InternalError(err)
+	}
+
+	attachment, err := ctrl.store.AddTaskAttachment(userId, model.TaskAttachment{
+		TaskId:      taskId,
+		UploaderId:  uploaderId,
+		Name:        name,
+		ContentType: contentType,
+		Size:        size,
+		Checksum:    hex.EncodeToString(hash.Sum(nil)),
+		BlobKey:     key,
+	})
+	if err != nil {
+		ctrl.deleteBlobs([]string{key})
+		return nil, attachmentError(err)
+	}
+
+	return attachment, nil
+}
+
+func (ctrl *Controller) GetTaskAttachments(userId uint16, taskId int64) ([]*model.TaskAttachment, error) {
+	attachments, err := ctrl.store.GetTaskAttachments(userId, taskId)
+	return attachments, attachmentError(err)
+}
+
+// GetTaskAttachmentContent returns the attachment with reader of its content, the caller closes it
+func (ctrl *Controller) GetTaskAttachmentContent(userId uint16, taskId, id int64) (*model.TaskAttachment, io.ReadCloser, error) {
+	attachment, err := ctrl.store.GetTaskAttachment(userId, taskId, id)
+	if err != nil {
+		return nil, nil, attachmentError(err)
+	}
+
+	content, err := ctrl.blobs.Get(context.Background(), attachment.BlobKey)
+	if err != nil {
+		return nil, nil, InternalError(err)
+	}
+
+	return attachment, content, nil
+}
+
+func (ctrl *Controller) DeleteTaskAttachment(userId uint16, taskId, id int64) error {
+	attachment, err := ctrl.store.DeleteTaskAttachment(userId, taskId, id)
+	if err != nil {
+		return attachmentError(err)
+	}
+
+	ctrl.deleteBlobs([]string{attachment.BlobKey})
+
+	return nil
+}
+
+// deleteBlobs removes blobs whose metadata is already deleted, failures only leave orphan blobs,
+// so they are logged instead of failing the request
+func (ctrl *Controller) deleteBlobs(keys []string) {
+	for _, key := range keys {
+		err := ctrl.blobs.Delete(context.Background(), key)
+		if err != nil {
+			log.Printf("delete blob %s failed: %v", key, err)
+		}
+	}
+}
+
+// attachmentError translates model errors of attachment store into controller errors
+func attachmentError(err error) error {
+	if errors.Is(err, model.ErrAttachmentNotFound) {
+		return ErrAttachmentNotFound.withCause(err)
+	}
+
+	return taskError(err)
+}
+
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,57 @@
+package controller
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAttachmentName(t *testing.T) {
+	name, err := attachmentName(`C:\Users\karl\report.pdf`)
+	assert.NoError(t, err)
+	assert.Equal(t, "report.pdf", name)
+
+	name, err = attachmentName(" ../notes.txt ")
+	assert.NoError(t, err)
+	assert.Equal(t, "notes.txt", name)
+
+	for _, name := range []string{"", " ", "/", "."} {
+		_, err = attachmentName(name)
+		assert.ErrorIs(t, err, ErrAttachmentNameRequired, name)
+	}
+
+	_, err = attachmentName(strings.Repeat("ж", maxAttachmentNameLength+1))
+	assert.ErrorIs(t, err, ErrAttachmentNameTooLong)
+}
+
+func TestAttachmentContentType(t *testing.T) {
+	png := "\x89PNG\x0D\x0A\x1A\x0A" + strings.Repeat("\x00", 16)
+	contentType := func(declared, content string) (string, error) {
+		return attachmentContentType(declared, bufio.NewReader(strings.NewReader(content)))
+	}
+
+	// unknown type is sniffed
+	mediaType, err := contentType("", png)
+	assert.NoError(t, err)
+	assert.Equal(t, "image/png", mediaType)
+
+	mediaType, err = contentType("application/octet-stream", "plain notes")
+	assert.NoError(t, err)
+	assert.Equal(t, "text/plain; charset=utf-8", mediaType)
+
+	// declared type wins over a sniffed one of the same kind
+	mediaType, err = contentType("text/csv; charset=utf-8", "a,b\n1,2\n")
+	assert.NoError(t, err)
+	assert.Equal(t, "text/csv; charset=utf-8", mediaType)
+
+	_, err = contentType("image/png", "<html><body>hi</body></html>")
+	assert.ErrorIs(t, err, ErrAttachmentTypeMismatch)
+
+	_, err = contentType("text/plain", png)
+	assert.ErrorIs(t, err, ErrAttachmentTypeMismatch)
+
+	_, err = contentType("", "<html><body>hi</body></html>")
+	assert.ErrorIs(t, err, ErrAttachmentType)
+}
@@ -0,0 +1,117 @@
+package controller
+
+import (
+	"errors"
+	"strings"
+	"todo/internal/model"
+)
+
+const maxChecklistTextLength = 1000
+
+// ChecklistItemInput holds fields of checklist item requests, nil keeps the current value on edit
+type ChecklistItemInput struct {
+	Text    *string
+	Checked *bool
+}
+
+// ChecklistMoveInput holds raw neighbour ids of checklist item move, empty means none
+type ChecklistMoveInput struct {
+	After  string
+	Before string
+}
+
+// ParseChecklistItemId parses checklist item id of the path
+func ParseChecklistItemId(param string) (int64, error) {
+	id, err := StringToId(param)
+	if err != nil {
+		return 0, ErrInvalidChecklistItemId.withCause(err)
+	}
+
+	return id, nil
+}
+
+func validateChecklistText(text string) (string, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return "", ErrChecklistTextRequired
+	}
+	if len([]rune(text)) > maxChecklistTextLength {
+		return "", ErrChecklistTextTooLong
+	}
+
+	return text, nil
+}
+
+func (ctrl *Controller) GetChecklist(userId uint16, taskId int64) ([]*model.ChecklistItem, error) {
+	items, err := ctrl.store.GetChecklist(userId, taskId)
+	return items, checklistError(err)
+}
+
+// AddChecklistItem puts the unchecked item to the end of the checklist
+func (ctrl *Controller) AddChecklistItem(userId uint16, taskId int64, text string) (*model.ChecklistItem, error) {
+	text, err := validateChecklistText(text)
+	if err != nil {
+		return nil, err
+	}
+
+	item, err := ctrl.store.AddChecklistItem(userId, taskId, text)
+	return item, checklistError(err)
+}
+
+// EditChecklistItem changes text or checked state of the item, checking and unchecking is done by it too
+func (ctrl *Controller) EditChecklistItem(userId uint16, taskId, id int64, input ChecklistItemInput) (*model.ChecklistItem, error) {
+	if input.Text == nil && input.Checked == nil {
+		return nil, ErrInvalidBodyParams
+	}
+
+	fields := model.ChecklistItemFields{Checked: input.Checked}
+	if input.Text != nil {
+		text, err := validateChecklistText(*input.Text)
+		if err != nil {
+			return nil, err
+		}
+		fields.Text = &text
+	}
+
+	item, err := ctrl.store.EditChecklistItem(userId, taskId, id, fields)
+	return item, checklistError(err)
+}
+
+// MoveChecklistItem puts the item between neighbours given by their ids, without them to the end of the checklist
+func (ctrl *Controller) MoveChecklistItem(userId uint16, taskId, id int64, input ChecklistMoveInput) (*model.ChecklistItem, error) {
+	var move model.ChecklistMove
+
+	var err error
+	if input.After != "" {
+		move.After, err = StringToId(input.After)
+		if err != nil {
+			return nil, ErrChecklistNeighbour.withCause(err)
+		}
+	}
+	if input.Before != "" {
+		move.Before, err = StringToId(input.Before)
+		if err != nil {
+			return nil, ErrChecklistNeighbour.withCause(err)
+		}
+	}
+
+	item, err := ctrl.store.MoveChecklistItem(userId, taskId, id, move)
+	return item, checklistError(err)
+}
+
+func (ctrl *Controller) RemoveChecklistItem(userId uint16, taskId, id int64) error {
+	return checklistError(ctrl.store.RemoveChecklistItem(userId, taskId, id))
+}
+
+// checklistError translates model errors of checklist store into controller errors
+func checklistError(err error) error {
+	if errors.Is(err, model.ErrChecklistItemNotFound) {
+		return ErrChecklistItemNotFound.withCause(err)
+	}
+
+	if errors.Is(err, model.ErrChecklistNeighbourItem) {
+		return ErrChecklistNeighbour.withCause(err)
+	}
+
+	return taskError(err)
+}
@@ -0,0 +1,108 @@
+package controller
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"todo/internal/model"
+)
+
+const (
+	DefaultCommentListLimit = 50
+	MaxCommentListLimit     = 200
+	maxCommentBodyLength    = 10000
+)
+
+// ParseCommentId parses comment id of the path
+func ParseCommentId(param string) (int64, error) {
+	id, err := StringToId(param)
+	if err != nil {
+		return 0, ErrInvalidCommentId.withCause(err)
+	}
+
+	return id, nil
+}
+
+func validateCommentBody(body string) (string, error) {
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return "", ErrCommentBodyRequired
+	}
+	if len([]rune(body)) > maxCommentBodyLength {
+		return "", ErrCommentBodyTooLong
+	}
+
+	return body, nil
+}
+
+// GetTaskComments takes raw limit and cursor query params, cursor is next_cursor of the previous page
+func (ctrl *Controller) GetTaskComments(userId uint16, taskId int64, limit, cursor string) (*model.TaskCommentPage, error) {
+	n := DefaultCommentListLimit
+	if len(limit) > 0 {
+		var err error
+		n, err = strconv.Atoi(limit)
+		if err != nil || n < 1 {
+			return nil, ErrInvalidLimit
+		}
+		if n > MaxCommentListLimit {
+			n = MaxCommentListLimit
+		}
+	}
+
+	var after int64
+	if len(cursor) > 0 {
+		var err error
+		after, err = StringToId(cursor)
+		if err != nil {
+			return nil, ErrInvalidCursor.withCause(err)
+		}
+	}
+
+	page, err := ctrl.store.GetTaskComments(userId, taskId, after, n)
+	if err != nil {
+		return nil, commentError(err)
+	}
+
+	if page.Next != 0 {
+		page.NextCursor = strconv.FormatInt(page.Next, 10)
+	}
+
+	return page, nil
+}
+
+func (ctrl *Controller) AddTaskComment(userId uint16, taskId int64, authorId uint16, body string) (*model.TaskComment, error) {
+	body, err := validateCommentBody(body)
+	if err != nil {
+		return nil, err
+	}
+
+	comment, err := ctrl.store.AddTaskComment(userId, taskId, authorId, body)
+	return comment, commentError(err)
+}
+
+func (ctrl *Controller) EditTaskComment(userId uint16, taskId, id int64, authorId uint16, body string) (*model.TaskComment, error) {
+	body, err := validateCommentBody(body)
+	if err != nil {
+		return nil, err
+	}
+
+	comment, err := ctrl.store.EditTaskComment(userId, taskId, id, authorId, body)
+	return comment, commentError(err)
+}
+
+func (ctrl *Controller) DeleteTaskComment(userId uint16, taskId, id int64, authorId uint16) error {
+	return commentError(ctrl.store.DeleteTaskComment(userId, taskId, id, authorId))
+}
+
+// commentError translates model errors of comment store into controller errors
+func commentError(err error) error {
+	if errors.Is(err, model.ErrCommentNotFound) {
+		return ErrCommentNotFound.withCause(err)
+	}
+
+	if errors.Is(err, model.ErrCommentNotAuthor) {
+		return ErrCommentNotAuthor.withCause(err)
+	}
+
+	return taskError(err)
+}
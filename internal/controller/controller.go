@@ -0,0 +1,15 @@
+package controller
+
+import (
+	"todo/internal/blob"
+	"todo/internal/model"
+)
+
+type Controller struct {
+	store model.Store
+	blobs blob.Store // contents of task attachments
+}
+
+func New(store model.Store, blobs blob.Store) *Controller {
+	return &Controller{store: store, blobs: blobs}
+}
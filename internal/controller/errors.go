@@ -0,0 +1,256 @@
+package controller
+
+import (
+	"errors"
+
+	"todo/internal/model"
+)
+
+// Kind classifies Error, api layer maps it to http status
+type Kind int
+
+const (
+	KindInternal Kind = iota
+	KindBadRequest
+	KindUnauthorized
+	KindNotFound
+	KindForbidden
+	KindConflict
+	KindUnprocessable
+	KindTooLarge
+	KindUnsupportedType
+)
+
+// Error is returned by every controller method, so handlers never compare error strings
+type Error struct {
+	Kind    Kind
+	Code    string                 // stable machine-readable code, e.g. create_task_failure_name_is_required
+	Message string                 // human readable message, safe to show to client
+	Details map[string]interface{} // optional extra members of the response
+	cause   error
+}
+
+func (e *Error) Error() string {
+	if e.cause != nil {
+		return e.Code + ": " + e.cause.Error()
+	}
+	return e.Code
+}
+
+func (e *Error) Unwrap() error {
+	return e.cause
+}
+
+// Is makes errors.Is match by code, so errors with details or cause still match the predefined ones
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+func (e *Error) withCause(cause error) *Error {
+	err := *e
+	err.cause = cause
+	return &err
+}
+
+var (
+	ErrInternal          = &Error{Kind: KindInternal, Code: "internal_error", Message: "internal server error"}
+	ErrInvalidBodyParams = &Error{Kind: KindBadRequest, Code: "invalid_body_params", Message: "request body is not valid"}
+	ErrInvalidToken      = &Error{Kind: KindUnauthorized, Code: "invalid_token", Message: "access token is missing, invalid, expired or revoked"}
+
+	ErrLoginIncorrectCredentials = &Error{Kind: KindUnprocessable, Code: "login_incorrect_credentials", Message: "login or password is incorrect"}
+	ErrRegisterLoginRequired     = &Error{Kind: KindUnprocessable, Code: "register_failure_login_is_required", Message: "login is required"}
+	ErrRegisterLoginTooLong      = &Error{Kind: KindUnprocessable, Code: "register_failure_login_is_too_long", Message: "login is longer than 120 characters"}
+	ErrRegisterPasswordTooShort  = &Error{Kind: KindUnprocessable, Code: "register_failure_password_is_too_short", Message: "password is too short"}
+	ErrRegisterPasswordTooLong   = &Error{Kind: KindUnprocessable, Code: "register_failure_password_is_too_long", Message: "password is longer than 72 bytes"}
+	ErrRegisterLoginTaken        = &Error{Kind: KindUnprocessable, Code: "register_failure_login_is_taken", Message: "login is already taken"}
+	ErrRefreshTokenInvalid       = &Error{Kind: KindUnauthorized, Code: "refresh_token_invalid", Message: "refresh token is invalid, expired or already used"}
+
+	ErrInvalidSort      = &Error{Kind: KindBadRequest, Code: "invalid_sort", Message: "sort must be one of id, name, status, created_at, updated_at, due_at, priority, position with optional - prefix"}
+	ErrInvalidLimit     = &Error{Kind: KindBadRequest, Code: "invalid_limit", Message: "limit must be a positive integer"}
+	ErrInvalidCursor    = &Error{Kind: KindBadRequest, Code: "invalid_cursor", Message: "cursor is malformed or belongs to another sort"}
+	ErrInvalidDueBefore = &Error{Kind: KindBadRequest, Code: "invalid_due_before", Message: "due_before must be RFC 3339 date time"}
+	ErrInvalidTree      = &Error{Kind: KindBadRequest, Code: "invalid_tree", Message: "tree must be true or false"}
+	ErrInvalidOverdue   = &Error{Kind: KindBadRequest, Code: "invalid_overdue", Message: "overdue must be true or false"}
+	ErrInvalidBlocked   = &Error{Kind: KindBadRequest, Code: "invalid_blocked", Message: "blocked must be true or false"}
+	ErrInvalidAssignee  = &Error{Kind: KindBadRequest, Code: "invalid_assignee", Message: "assignee must be me or a user id"}
+	ErrInvalidArchived  = &Error{Kind: KindBadRequest, Code: "invalid_archived", Message: "archived must be true or false"}
+
+	ErrInvalidOccurrenceCount = &Error{Kind: KindBadRequest, Code: "invalid_count", Message: "count must be an integer from 1 to 100"}
+
+	ErrInvalidTaskId          = &Error{Kind: KindBadRequest, Code: "invalid_task_id", Message: "task id must be a positive 64-bit integer or UUID"}
+	ErrTaskNotFound           = &Error{Kind: KindNotFound, Code: "task_not_found", Message: "task does not exist"}
+	ErrTaskStatusTransition   = &Error{Kind: KindConflict, Code: "task_status_transition_not_allowed", Message: "task status can not be changed"}
+	ErrCreateTaskNameRequired = &Error{Kind: KindUnprocessable, Code: "create_task_failure_name_is_required", Message: "task name is required"}
+	ErrEditTaskNameRequired   = &Error{Kind: KindUnprocessable, Code: "edit_task_failure_name_is_required", Message: "task name is required"}
+	ErrInvalidDueAt           = &Error{Kind: KindUnprocessable, Code: "task_invalid_due_at", Message: "due_at must be RFC 3339 date time or date time without offset in due_timezone"}
+	ErrInvalidPriority        = &Error{Kind: KindUnprocessable, Code: "task_invalid_priority", Message: "priority must be one of low, normal, high, urgent"}
+	ErrMoveTaskInvalidStatus  = &Error{Kind: KindUnprocessable, Code: "move_task_invalid_status", Message: "status is not a task status"}
+	ErrMoveTaskNeighbour      = &Error{Kind: KindUnprocessable, Code: "move_task_invalid_neighbour", Message: "before and after must be tasks of the target status, after placed above before"}
+	ErrInvalidTimezone        = &Error{Kind: KindUnprocessable, Code: "task_invalid_due_timezone", Message: "due_timezone must be IANA time zone name, e.g. Europe/Amsterdam"}
+	ErrInvalidTaskParent      = &Error{Kind: KindUnprocessable, Code: "task_invalid_parent", Message: "parent task does not exist or is deleted"}
+	ErrTaskParentCycle        = &Error{Kind: KindUnprocessable, Code: "task_parent_cycle", Message: "task can not be moved under itself or its descendant"}
+	ErrTaskParentDeleted      = &Error{Kind: KindConflict, Code: "task_parent_deleted", Message: "parent task is deleted, restore it first"}
+	ErrTaskBlockerNotFound    = &Error{Kind: KindNotFound, Code: "task_blocker_not_found", Message: "blocker task does not exist"}
+	ErrTaskDependencyCycle    = &Error{Kind: KindUnprocessable, Code: "task_dependency_cycle", Message: "task can not be blocked by itself or by a task it blocks"}
+	ErrTaskBlocked            = &Error{Kind: KindConflict, Code: "task_blocked", Message: "task can not be started until all its blockers are done"}
+	ErrInvalidRecurrence      = &Error{Kind: KindUnprocessable, Code: "task_invalid_recurrence", Message: "recurrence must be FREQ=DAILY, WEEKLY or MONTHLY with optional INTERVAL, BYDAY, BYMONTHDAY and UNTIL"}
+	ErrRecurrenceWithoutDueAt = &Error{Kind: KindUnprocessable, Code: "task_recurrence_without_due_at", Message: "recurrence needs due_at of the first occurrence"}
+	ErrTaskNotRecurring       = &Error{Kind: KindUnprocessable, Code: "task_not_recurring", Message: "task has no recurrence"}
+	ErrInvalidTaskAssignee    = &Error{Kind: KindUnprocessable, Code: "task_invalid_assignee", Message: "assignees must be a list of user ids"}
+	ErrTaskAssigneeNotMember  = &Error{Kind: KindUnprocessable, Code: "task_assignee_not_member", Message: "assignee must be the owner or a member of the workspace"}
+	ErrInvalidEstimate        = &Error{Kind: KindUnprocessable, Code: "task_invalid_estimate", Message: "estimate_minutes must be a positive whole number of minutes"}
+	ErrInvalidTaskProject     = &Error{Kind: KindUnprocessable, Code: "task_invalid_project", Message: "project does not exist"}
+	ErrFreeTrashSubtasks      = &Error{Kind: KindConflict, Code: "free_trash_failure_subtasks_in_other_projects", Message: "trashed tasks of the project have subtasks in other projects, free the whole trash or delete them first"}
+	ErrTaskProjectArchived    = &Error{Kind: KindConflict, Code: "task_project_archived", Message: "project is archived and takes no new tasks"}
+	ErrChecklistIncomplete    = &Error{Kind: KindConflict, Code: "task_checklist_incomplete", Message: "task can not be done until all its checklist items are checked"}
+
+	ErrInvalidLabelId    = &Error{Kind: KindBadRequest, Code: "invalid_label_id", Message: "label id must be a positive 64-bit integer"}
+	ErrInvalidLabelMatch = &Error{Kind: KindBadRequest, Code: "invalid_label_match", Message: "label_match must be any or all"}
+	ErrLabelNotFound     = &Error{Kind: KindNotFound, Code: "label_not_found", Message: "label does not exist"}
+	ErrLabelNameRequired = &Error{Kind: KindUnprocessable, Code: "label_failure_name_is_required", Message: "label name is required"}
+	ErrLabelNameTooLong  = &Error{Kind: KindUnprocessable, Code: "label_failure_name_is_too_long", Message: "label name is longer than 64 characters"}
+	ErrLabelNameTaken    = &Error{Kind: KindUnprocessable, Code: "label_failure_name_is_taken", Message: "label name is already taken"}
+	ErrInvalidLabelColor = &Error{Kind: KindUnprocessable, Code: "label_failure_invalid_color", Message: "label color must be empty or #rrggbb"}
+
+	ErrInvalidProjectId    = &Error{Kind: KindBadRequest, Code: "invalid_project_id", Message: "project id must be a positive 64-bit integer"}
+	ErrProjectNotFound     = &Error{Kind: KindNotFound, Code: "project_not_found", Message: "project does not exist"}
+	ErrProjectNameRequired = &Error{Kind: KindUnprocessable, Code: "project_failure_name_is_required", Message: "project name is required"}
+	ErrProjectNameTooLong  = &Error{Kind: KindUnprocessable, Code: "project_failure_name_is_too_long", Message: "project name is longer than 128 characters"}
+	ErrProjectNameTaken    = &Error{Kind: KindUnprocessable, Code: "project_failure_name_is_taken", Message: "project name is already taken"}
+	ErrInvalidProjectColor = &Error{Kind: KindUnprocessable, Code: "project_failure_invalid_color", Message: "project color must be empty or #rrggbb"}
+
+	ErrInvalidWorkspaceId  = &Error{Kind: KindBadRequest, Code: "invalid_workspace_id", Message: "workspace id must be a positive 16-bit integer"}
+	ErrInvalidMemberId     = &Error{Kind: KindBadRequest, Code: "invalid_member_id", Message: "member id must be a positive 16-bit integer"}
+	ErrWorkspaceNotFound   = &Error{Kind: KindNotFound, Code: "workspace_not_found", Message: "workspace does not exist or you are not its member"}
+	ErrWorkspaceForbidden  = &Error{Kind: KindForbidden, Code: "workspace_action_forbidden", Message: "your role in the workspace does not allow the action"}
+	ErrMemberNotFound      = &Error{Kind: KindNotFound, Code: "workspace_member_not_found", Message: "workspace member does not exist"}
+	ErrInvitationNotFound  = &Error{Kind: KindNotFound, Code: "workspace_invitation_not_found", Message: "workspace invitation does not exist"}
+	ErrMemberLoginRequired = &Error{Kind: KindUnprocessable, Code: "workspace_member_login_is_required", Message: "login of the invited user is required"}
+	ErrMemberLoginNotFound = &Error{Kind: KindUnprocessable, Code: "workspace_member_login_not_found", Message: "user with the login does not exist"}
+	ErrInvalidMemberRole   = &Error{Kind: KindUnprocessable, Code: "workspace_member_invalid_role", Message: "role must be one of owner, editor, viewer"}
+	ErrMemberExists        = &Error{Kind: KindConflict, Code: "workspace_member_exists", Message: "user is already a member of the workspace or invited to it"}
+
+	ErrInvalidCommentId    = &Error{Kind: KindBadRequest, Code: "invalid_comment_id", Message: "comment id must be a positive 64-bit integer"}
+	ErrCommentNotFound     = &Error{Kind: KindNotFound, Code: "comment_not_found", Message: "comment does not exist"}
+	ErrCommentNotAuthor    = &Error{Kind: KindForbidden, Code: "comment_not_author", Message: "only author of the comment can change it"}
+	ErrCommentBodyRequired = &Error{Kind: KindUnprocessable, Code: "comment_failure_body_is_required", Message: "comment body is required"}
+	ErrCommentBodyTooLong  = &Error{Kind: KindUnprocessable, Code: "comment_failure_body_is_too_long", Message: "comment body is longer than 10000 characters"}
+
+	ErrInvalidChecklistItemId = &Error{Kind: KindBadRequest, Code: "invalid_checklist_item_id", Message: "checklist item id must be a positive 64-bit integer"}
+	ErrChecklistItemNotFound  = &Error{Kind: KindNotFound, Code: "checklist_item_not_found", Message: "checklist item does not exist"}
+	ErrChecklistTextRequired  = &Error{Kind: KindUnprocessable, Code: "checklist_item_failure_text_is_required", Message: "checklist item text is required"}
+	ErrChecklistTextTooLong   = &Error{Kind: KindUnprocessable, Code: "checklist_item_failure_text_is_too_long", Message: "checklist item text is longer than 1000 characters"}
+	ErrChecklistNeighbour     = &Error{Kind: KindUnprocessable, Code: "checklist_item_invalid_neighbour", Message: "after and before must be items of the checklist, after placed above before"}
+
+	ErrInvalidReportRange    = &Error{Kind: KindBadRequest, Code: "invalid_report_range", Message: "from and to must be dates YYYY-MM-DD, from not after to, to not after today and at most 366 days apart"}
+	ErrInvalidReportInterval = &Error{Kind: KindBadRequest, Code: "invalid_report_interval", Message: "interval must be day or week"}
+	ErrInvalidReportTimezone = &Error{Kind: KindBadRequest, Code: "invalid_report_timezone", Message: "timezone must be IANA time zone name, e.g. Europe/Amsterdam"}
+	ErrInvalidReportFormat   = &Error{Kind: KindBadRequest, Code: "invalid_report_format", Message: "format must be json or csv"}
+
+	ErrInvalidTimeEntryStart = &Error{Kind: KindUnprocessable, Code: "time_entry_invalid_started_at", Message: "started_at must be RFC 3339 date time"}
+	ErrInvalidTimeEntryEnd   = &Error{Kind: KindUnprocessable, Code: "time_entry_invalid_ended_at", Message: "ended_at must be RFC 3339 date time after started_at and not in the future"}
+	ErrTimeEntryNoteTooLong  = &Error{Kind: KindUnprocessable, Code: "time_entry_failure_note_is_too_long", Message: "time entry note is longer than 1000 characters"}
+
+	ErrInvalidAttachmentId    = &Error{Kind: KindBadRequest, Code: "invalid_attachment_id", Message: "attachment id must be a positive 64-bit integer"}
+	ErrAttachmentFileRequired = &Error{Kind: KindBadRequest, Code: "attachment_failure_file_is_required", Message: "multipart form field file is required"}
+	ErrAttachmentNotFound     = &Error{Kind: KindNotFound, Code: "attachment_not_found", Message: "attachment does not exist"}
+	ErrAttachmentNameRequired = &Error{Kind: KindUnprocessable, Code: "attachment_failure_name_is_required", Message: "attachment file name is required"}
+	ErrAttachmentNameTooLong  = &Error{Kind: KindUnprocessable, Code: "attachment_failure_name_is_too_long", Message: "attachment file name is longer than 255 characters"}
+	ErrAttachmentTooLarge     = &Error{Kind: KindTooLarge, Code: "attachment_failure_too_large", Message: "attachment is larger than allowed"}
+	ErrAttachmentType         = &Error{Kind: KindUnsupportedType, Code: "attachment_failure_type_not_allowed", Message: "attachment content type is not allowed"}
+	ErrAttachmentTypeMismatch = &Error{Kind: KindUnsupportedType, Code: "attachment_failure_type_mismatch", Message: "attachment content does not match its content type"}
+)
+
+// InternalError hides err from client, it is only logged
+func InternalError(err error) *Error {
+	return ErrInternal.withCause(err)
+}
+
+// taskError translates model errors of task store into controller errors
+func taskError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	// already translated, e.g. by the edit merged while the task is locked
+	var ctrlErr *Error
+	if errors.As(err, &ctrlErr) {
+		return err
+	}
+
+	if errors.Is(err, model.ErrTaskNotFound) {
+		return ErrTaskNotFound.withCause(err)
+	}
+
+	if errors.Is(err, model.ErrTaskParentNotFound) {
+		return ErrInvalidTaskParent.withCause(err)
+	}
+
+	if errors.Is(err, model.ErrTaskParentCycle) {
+		return ErrTaskParentCycle.withCause(err)
+	}
+
+	if errors.Is(err, model.ErrTaskParentDeleted) {
+		return ErrTaskParentDeleted.withCause(err)
+	}
+
+	if errors.Is(err, model.ErrProjectNotFound) {
+		return ErrInvalidTaskProject.withCause(err)
+	}
+
+	if errors.Is(err, model.ErrAssigneeNotMember) {
+		return ErrTaskAssigneeNotMember.withCause(err)
+	}
+
+	if errors.Is(err, model.ErrProjectArchived) {
+		return ErrTaskProjectArchived.withCause(err)
+	}
+
+	if errors.Is(err, model.ErrTrashSubtaskOutsideProject) {
+		return ErrFreeTrashSubtasks.withCause(err)
+	}
+
+	if errors.Is(err, model.ErrTaskNeighbourInvalid) {
+		return ErrMoveTaskNeighbour.withCause(err)
+	}
+
+	if errors.Is(err, model.ErrTaskBlockerNotFound) {
+		return ErrTaskBlockerNotFound.withCause(err)
+	}
+
+	if errors.Is(err, model.ErrTaskDependencyCycle) {
+		return ErrTaskDependencyCycle.withCause(err)
+	}
+
+	var blockedErr *model.TaskBlockedError
+	if errors.As(err, &blockedErr) {
+		e := ErrTaskBlocked.withCause(err)
+		e.Details = map[string]interface{}{
+			"blockers": blockedErr.Blockers,
+		}
+		return e
+	}
+
+	var checklistErr *model.ChecklistIncompleteError
+	if errors.As(err, &checklistErr) {
+		e := ErrChecklistIncomplete.withCause(err)
+		e.Details = map[string]interface{}{
+			"unchecked": checklistErr.Unchecked,
+		}
+		return e
+	}
+
+	var transitionErr *model.StatusTransitionError
+	if errors.As(err, &transitionErr) {
+		e := ErrTaskStatusTransition.withCause(err)
+		e.Message = transitionErr.Error()
+		e.Details = map[string]interface{}{
+			"current_status":   transitionErr.Current,
+			"requested_status": transitionErr.Requested,
+		}
+		return e
+	}
+
+	return InternalError(err)
+}
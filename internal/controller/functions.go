@@ -1,12 +1,29 @@
 package controller
 
-import "strconv"
+import (
+	"errors"
+	"strconv"
+)
 
-func StringToUint16(s string) (uint16, error) {
-	i, err := strconv.ParseUint(s, 10, 64)
+// StringToId parses positive 64-bit id, out of range values are rejected instead of truncated
+func StringToId(s string) (int64, error) {
+	i, err := strconv.ParseInt(s, 10, 64)
 	if err != nil {
 		return 0, err
 	}
 
-	return uint16(i), nil
+	if i < 1 {
+		return 0, errors.New("id must be positive")
+	}
+
+	return i, nil
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,91 @@
+package controller
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"todo/internal/model"
+)
+
+const maxLabelNameLength = 64
+
+var labelColorPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// ParseLabelId parses label id of the path
+func ParseLabelId(param string) (int64, error) {
+	id, err := StringToId(param)
+	if err != nil {
+		return 0, ErrInvalidLabelId.withCause(err)
+	}
+
+	return id, nil
+}
+
+func validateLabel(name, color string) (string, error) {
+	name = strings.Trim(name, " ")
+	if name == "" {
+		return "", ErrLabelNameRequired
+	}
+	if len([]rune(name)) > maxLabelNameLength {
+		return "", ErrLabelNameTooLong
+	}
+	if color != "" && !labelColorPattern.MatchString(color) {
+		return "", ErrInvalidLabelColor
+	}
+
+	return name, nil
+}
+
+func (ctrl *Controller) GetLabelList(userId uint16) ([]*model.Label, error) {
+	list, err := ctrl.store.GetLabelList(userId)
+	return list, labelError(err)
+}
+
+func (ctrl *Controller) CreateLabel(userId uint16, name, color string) (*model.Label, error) {
+	name, err := validateLabel(name, color)
+	if err != nil {
+		return nil, err
+	}
+
+	label, err := ctrl.store.CreateLabel(userId, name, color)
+	return label, labelError(err)
+}
+
+func (ctrl *Controller) GetLabel(userId uint16, id int64) (*model.Label, error) {
+	label, err := ctrl.store.GetLabel(userId, id)
+	return label, labelError(err)
+}
+
+func (ctrl *Controller) EditLabel(userId uint16, id int64, name, color string) error {
+	name, err := validateLabel(name, color)
+	if err != nil {
+		return err
+	}
+
+	return labelError(ctrl.store.EditLabel(userId, id, name, color))
+}
+
+func (ctrl *Controller) DeleteLabel(userId uint16, id int64) error {
+	return labelError(ctrl.store.DeleteLabel(userId, id))
+}
+
+func (ctrl *Controller) AddTaskLabel(userId uint16, taskId, labelId int64) error {
+	return labelError(ctrl.store.AddTaskLabel(userId, taskId, labelId))
+}
+
+func (ctrl *Controller) RemoveTaskLabel(userId uint16, taskId, labelId int64) error {
+	return labelError(ctrl.store.RemoveTaskLabel(userId, taskId, labelId))
+}
+
+// labelError translates model errors of label store into controller errors
+func labelError(err error) error {
+	if errors.Is(err, model.ErrLabelNotFound) {
+		return ErrLabelNotFound.withCause(err)
+	}
+
+	if errors.Is(err, model.ErrLabelNameTaken) {
+		return ErrLabelNameTaken.withCause(err)
+	}
+
+	return taskError(err)
+}
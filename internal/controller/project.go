@@ -0,0 +1,116 @@
+package controller
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"todo/internal/model"
+)
+
+const maxProjectNameLength = 128
+
+// ProjectInput holds fields of create and edit project requests
+type ProjectInput struct {
+	Name        string
+	Description string
+	Color       string // empty or #rrggbb
+	Archived    bool
+}
+
+// ParseProjectId parses project id of the path or query
+func ParseProjectId(param string) (int64, error) {
+	id, err := StringToId(param)
+	if err != nil {
+		return 0, ErrInvalidProjectId.withCause(err)
+	}
+
+	return id, nil
+}
+
+func projectFields(input ProjectInput) (model.ProjectFields, error) {
+	fields := model.ProjectFields{
+		Name:        strings.Trim(input.Name, " "),
+		Description: input.Description,
+		Color:       input.Color,
+		Archived:    input.Archived,
+	}
+
+	if fields.Name == "" {
+		return fields, ErrProjectNameRequired
+	}
+	if len([]rune(fields.Name)) > maxProjectNameLength {
+		return fields, ErrProjectNameTooLong
+	}
+	if fields.Color != "" && !labelColorPattern.MatchString(fields.Color) {
+		return fields, ErrInvalidProjectColor
+	}
+
+	return fields, nil
+}
+
+// GetProjectList takes raw archived query param, empty means both archived and active projects
+func (ctrl *Controller) GetProjectList(userId uint16, archived string) ([]*model.Project, error) {
+	var filter *bool
+	if len(archived) > 0 {
+		value, err := strconv.ParseBool(archived)
+		if err != nil {
+			return nil, ErrInvalidArchived.withCause(err)
+		}
+		filter = &value
+	}
+
+	list, err := ctrl.store.GetProjectList(userId, filter)
+	return list, projectError(err)
+}
+
+func (ctrl *Controller) CreateProject(userId uint16, input ProjectInput) (*model.Project, error) {
+	fields, err := projectFields(input)
+	if err != nil {
+		return nil, err
+	}
+
+	project, err := ctrl.store.CreateProject(userId, fields)
+	return project, projectError(err)
+}
+
+func (ctrl *Controller) GetProject(userId uint16, id int64) (*model.Project, error) {
+	project, err := ctrl.store.GetProject(userId, id)
+	return project, projectError(err)
+}
+
+func (ctrl *Controller) EditProject(userId uint16, id int64, input ProjectInput) error {
+	fields, err := projectFields(input)
+	if err != nil {
+		return err
+	}
+
+	return projectError(ctrl.store.EditProject(userId, id, fields))
+}
+
+func (ctrl *Controller) DeleteProject(userId uint16, id int64) error {
+	return projectError(ctrl.store.DeleteProject(userId, id))
+}
+
+// GetProjectTaskList is GetTaskList of the project tasks, missing project is reported instead of an empty list
+func (ctrl *Controller) GetProjectTaskList(userId uint16, id int64, query TaskListQuery) (*model.TaskPage, error) {
+	_, err := ctrl.store.GetProject(userId, id)
+	if err != nil {
+		return nil, projectError(err)
+	}
+
+	query.Project = strconv.FormatInt(id, 10)
+	return ctrl.GetTaskList(userId, query)
+}
+
+// projectError translates model errors of project store into controller errors
+func projectError(err error) error {
+	if errors.Is(err, model.ErrProjectNotFound) {
+		return ErrProjectNotFound.withCause(err)
+	}
+
+	if errors.Is(err, model.ErrProjectNameTaken) {
+		return ErrProjectNameTaken.withCause(err)
+	}
+
+	return taskError(err)
+}
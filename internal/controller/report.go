@@ -0,0 +1,81 @@
+package controller
+
+import (
+	"time"
+	"todo/internal/model"
+)
+
+const (
+	dateLayout        = "2006-01-02"
+	maxReportDays     = 366
+	defaultReportDays = 30
+)
+
+// ReportQuery holds raw query params of the report
+type ReportQuery struct {
+	From     string // date, defaults to 30 days before to
+	To       string // date, today by default
+	Interval string // day or week, day by default
+	Timezone string // IANA name of the dates, empty means UTC
+}
+
+// GetReportSummary reports the range from the start of the period of from up to the end of the period of to,
+// weeks start on Monday
+func (ctrl *Controller) GetReportSummary(userId uint16, query ReportQuery) (*model.ReportSummary, error) {
+	interval := query.Interval
+	if interval == "" {
+		interval = model.ReportDay
+	}
+	if interval != model.ReportDay && interval != model.ReportWeek {
+		return nil, ErrInvalidReportInterval
+	}
+
+	loc := time.UTC
+	if query.Timezone != "" {
+		var err error
+		loc, err = time.LoadLocation(query.Timezone)
+		if err != nil || query.Timezone == "Local" {
+			return nil, ErrInvalidReportTimezone.withCause(err)
+		}
+	}
+
+	now := time.Now().In(loc)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+
+	to := today
+	if query.To != "" {
+		var err error
+		to, err = time.ParseInLocation(dateLayout, query.To, loc)
+		if err != nil {
+			return nil, ErrInvalidReportRange.withCause(err)
+		}
+	}
+
+	from := to.AddDate(0, 0, 1-defaultReportDays)
+	if query.From != "" {
+		var err error
+		from, err = time.ParseInLocation(dateLayout, query.From, loc)
+		if err != nil {
+			return nil, ErrInvalidReportRange.withCause(err)
+		}
+	}
+
+	if from.After(to) || to.After(today) || from.AddDate(0, 0, maxReportDays).Before(to) {
+		return nil, ErrInvalidReportRange
+	}
+
+	if interval == model.ReportWeek {
+		from = from.AddDate(0, 0, -((int(from.Weekday()) + 6) % 7))
+	}
+
+	end := from
+	for !end.After(to) {
+		end = model.NextReportPeriod(end, interval)
+	}
+
+	return ctrl.store.GetReportSummary(userId, model.ReportFilter{
+		From:     from,
+		To:       end,
+		Interval: interval,
+	})
+}
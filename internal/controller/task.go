@@ -1,85 +1,585 @@
 package controller
 
 import (
+	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"log"
+	"strconv"
 	"strings"
+	"time"
 	"todo/internal/config"
 	"todo/internal/model"
+	"todo/internal/recurrence"
+
+	"github.com/google/uuid"
+)
+
+// TaskListQuery holds raw query params of task list, they are validated by GetTaskList
+type TaskListQuery struct {
+	Statuses   []string
+	Search     string
+	DueBefore  string // RFC 3339
+	Overdue    string // bool
+	Blocked    string // bool
+	Labels     []string
+	LabelMatch string // any or all, any by default
+	Tree       string // bool
+	Project    string // project id, empty means tasks of every project and without one
+	Assignee   string // user id or me
+	Me         uint16 // requesting user, the one me assignee stands for
+	Sort       string // one of model.TaskSortColumns, "-" prefix sorts descending
+	Limit      string
+	Cursor     string
+}
+
+// TaskInput holds raw task fields of create and edit requests
+type TaskInput struct {
+	Name        string
+	Description string
+	Priority    string // one of model priorities, empty means normal
+	DueAt       string // RFC 3339 or date time without offset in DueTimezone, empty means no due date
+	DueTimezone string // IANA name, empty means UTC
+	ParentId    string // id or public id of parent task, empty means top level task
+	ProjectId   string // empty means no project
+	Recurrence  string // RRULE subset, empty means the task does not recur
+	Estimate    string // whole minutes, empty means no estimate
+}
+
+// TaskEditInput holds raw task fields of edit request, nil fields are not in the body and keep their value,
+// empty ones clear it
+type TaskEditInput struct {
+	Name        *string
+	Description *string
+	Priority    *string
+	DueAt       *string
+	DueTimezone *string
+	ParentId    *string
+	ProjectId   *string
+	Recurrence  *string
+	Estimate    *string
+}
+
+const (
+	DefaultOccurrenceCount = 5
+	MaxOccurrenceCount     = 100
 )
 
-func GetTaskList(status string) (interface{}, error) {
-	if len(status) > 0 {
-		if !(status == model.StatusCreated || status == model.StatusInProgress || status == model.StatusPaused || status == model.StatusDone || status == model.StatusDeleted) {
+// localTimeLayouts are accepted for due date without offset
+var localTimeLayouts = []string{"2006-01-02T15:04:05", "2006-01-02T15:04"}
+
+func parseTime(s string, loc *time.Location) (time.Time, error) {
+	t, err := time.Parse(time.RFC3339, s)
+	if err == nil {
+		return t, nil
+	}
+
+	for _, layout := range localTimeLayouts {
+		if t, err := time.ParseInLocation(layout, s, loc); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, err
+}
+
+func (ctrl *Controller) taskFields(userId uint16, input TaskInput) (model.TaskFields, error) {
+	fields := model.TaskFields{
+		Name:        input.Name,
+		Description: input.Description,
+		Priority:    input.Priority,
+	}
+
+	if fields.Priority == "" {
+		fields.Priority = model.PriorityNormal
+	}
+	if !model.IsPriority(fields.Priority) {
+		return fields, ErrInvalidPriority
+	}
+
+	loc := time.UTC
+	if input.DueTimezone != "" {
+		var err error
+		loc, err = time.LoadLocation(input.DueTimezone)
+		if err != nil || input.DueTimezone == "Local" {
+			return fields, ErrInvalidTimezone.withCause(err)
+		}
+	}
+
+	if input.ParentId != "" {
+		parentId, err := ctrl.ResolveTaskId(userId, input.ParentId)
+		if err != nil {
+			return fields, ErrInvalidTaskParent.withCause(err)
+		}
+		fields.ParentId = &parentId
+	}
+
+	if input.ProjectId != "" {
+		projectId, err := StringToId(input.ProjectId)
+		if err != nil {
+			return fields, ErrInvalidTaskProject.withCause(err)
+		}
+		fields.ProjectId = &projectId
+	}
+
+	if input.Estimate != "" {
+		estimate, err := strconv.ParseInt(input.Estimate, 10, 32)
+		if err != nil {
+			return fields, ErrInvalidEstimate.withCause(err)
+		}
+		if estimate <= 0 {
+			return fields, ErrInvalidEstimate.withCause(errors.New("estimate is not positive"))
+		}
+		minutes := int32(estimate)
+		fields.EstimateMinutes = &minutes
+	}
+
+	if input.DueAt == "" {
+		if input.Recurrence != "" {
+			return fields, ErrRecurrenceWithoutDueAt
+		}
+		return fields, nil
+	}
+
+	due, err := parseTime(input.DueAt, loc)
+	if err != nil {
+		return fields, ErrInvalidDueAt.withCause(err)
+	}
+	due = due.In(loc)
+
+	fields.DueAt = &due
+	fields.DueTimezone = input.DueTimezone
+
+	if input.Recurrence != "" {
+		rule, err := recurrence.Parse(input.Recurrence)
+		if err != nil {
+			e := ErrInvalidRecurrence.withCause(err)
+			e.Message += ": " + err.Error()
+			return fields, e
+		}
+		fields.Recurrence = rule.String()
+	}
+
+	return fields, nil
+}
+
+// taskListCursor is encoded into opaque next_cursor, sort is kept to reject cursor of another order
+type taskListCursor struct {
+	Sort string `json:"s"`
+	model.TaskCursor
+}
+
+func encodeTaskListCursor(sort string, cursor *model.TaskCursor) string {
+	value, _ := json.Marshal(taskListCursor{
+		Sort:       sort,
+		TaskCursor: *cursor,
+	})
+	return base64.RawURLEncoding.EncodeToString(value)
+}
+
+func decodeTaskListCursor(sort, s string) (*model.TaskCursor, error) {
+	value, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor.withCause(err)
+	}
+
+	var cursor taskListCursor
+	err = json.Unmarshal(value, &cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor.withCause(err)
+	}
+
+	if cursor.Sort != sort || !cursor.Valid(strings.TrimPrefix(sort, "-")) {
+		return nil, ErrInvalidCursor
+	}
+
+	return &cursor.TaskCursor, nil
+}
+
+func (ctrl *Controller) GetTaskList(userId uint16, query TaskListQuery) (*model.TaskPage, error) {
+	filter := model.TaskListFilter{
+		Search: strings.Trim(query.Search, " "),
+		Sort:   "id",
+		Limit:  model.DefaultTaskListLimit,
+	}
+
+	for _, status := range query.Statuses {
+		if !model.IsStatus(status) {
 			// it is not one of our statuses, user just mistyped something else
 			if config.DebugLog() {
 				log.Printf("task offset incorrect status: %s", status)
 			}
-			status = ""
+			continue
+		}
+		filter.Statuses = append(filter.Statuses, status)
+	}
+
+	if len(query.DueBefore) > 0 {
+		dueBefore, err := parseTime(query.DueBefore, time.UTC)
+		if err != nil {
+			return nil, ErrInvalidDueBefore.withCause(err)
+		}
+		filter.DueBefore = &dueBefore
+	}
+
+	if len(query.Overdue) > 0 {
+		overdue, err := strconv.ParseBool(query.Overdue)
+		if err != nil {
+			return nil, ErrInvalidOverdue.withCause(err)
+		}
+		filter.Overdue = overdue
+	}
+
+	if len(query.Project) > 0 {
+		projectId, err := ParseProjectId(query.Project)
+		if err != nil {
+			return nil, err
+		}
+		filter.ProjectId = &projectId
+	}
+
+	if query.Assignee == "me" {
+		filter.Assignee = query.Me
+	} else if len(query.Assignee) > 0 {
+		assignee, err := parseUserId(query.Assignee)
+		if err != nil {
+			return nil, ErrInvalidAssignee.withCause(err)
+		}
+		filter.Assignee = assignee
+	}
+
+	if len(query.Blocked) > 0 {
+		blocked, err := strconv.ParseBool(query.Blocked)
+		if err != nil {
+			return nil, ErrInvalidBlocked.withCause(err)
+		}
+		filter.Blocked = blocked
+	}
+
+	for _, label := range query.Labels {
+		label = strings.Trim(label, " ")
+		if label != "" && !containsString(filter.Labels, label) {
+			filter.Labels = append(filter.Labels, label)
+		}
+	}
+
+	if len(query.Tree) > 0 {
+		tree, err := strconv.ParseBool(query.Tree)
+		if err != nil {
+			return nil, ErrInvalidTree.withCause(err)
+		}
+		filter.Tree = tree
+	}
+
+	switch query.LabelMatch {
+	case "", "any":
+	case "all":
+		filter.AllLabels = true
+	default:
+		return nil, ErrInvalidLabelMatch
+	}
+
+	sort := query.Sort
+	if len(sort) > 0 {
+		filter.Desc = strings.HasPrefix(sort, "-")
+		filter.Sort = strings.TrimPrefix(sort, "-")
+		if _, ok := model.TaskSortColumns[filter.Sort]; !ok {
+			return nil, ErrInvalidSort
 		}
 	}
 
-	list, err := model.GetTaskList(status)
+	if len(query.Limit) > 0 {
+		limit, err := strconv.Atoi(query.Limit)
+		if err != nil || limit < 1 {
+			return nil, ErrInvalidLimit
+		}
+		if limit > model.MaxTaskListLimit {
+			limit = model.MaxTaskListLimit
+		}
+		filter.Limit = limit
+	}
+
+	if len(query.Cursor) > 0 {
+		after, err := decodeTaskListCursor(sort, query.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		filter.After = after
+	}
+
+	page, err := ctrl.store.GetTaskList(userId, filter)
 	if err != nil {
-		return nil, err
+		return nil, taskError(err)
+	}
+
+	if page.Next != nil {
+		page.NextCursor = encodeTaskListCursor(sort, page.Next)
+	}
+
+	return page, nil
+}
+
+func (ctrl *Controller) GetTaskStatusList() (interface{}, error) {
+	list, err := ctrl.store.GetStatusList()
+	if err != nil {
+		return nil, InternalError(err)
 	}
 
 	return list, nil
 }
 
-func GetTaskStatusList() (interface{}, error) {
-	list, err := model.GetStatusList()
+func (ctrl *Controller) CreateTask(userId, actorId uint16, input TaskInput) (*model.Task, error) {
+	if strings.Trim(input.Name, " ") == "" {
+		return nil, ErrCreateTaskNameRequired
+	}
+
+	fields, err := ctrl.taskFields(userId, input)
 	if err != nil {
 		return nil, err
 	}
 
-	return list, nil
+	task, err := ctrl.store.CreateTask(userId, actorId, fields)
+	return task, taskError(err)
 }
 
-func CreateTask(name, description string) (uint16, error) {
-	if strings.Trim(name, " ") == "" {
-		return uint16(0), errors.New("create_task_failure_name_is_required")
+// ResolveTaskId accepts either numeric task id or its public UUID
+func (ctrl *Controller) ResolveTaskId(userId uint16, param string) (int64, error) {
+	if id, err := StringToId(param); err == nil {
+		return id, nil
 	}
 
-	return model.CreateTask(name, description)
+	publicId, err := uuid.Parse(param)
+	if err != nil {
+		return 0, ErrInvalidTaskId.withCause(err)
+	}
+
+	id, err := ctrl.store.GetTaskIdByPublicId(userId, publicId.String())
+	return id, taskError(err)
+}
+
+func (ctrl *Controller) GetTask(userId uint16, id int64) (*model.Task, error) {
+	task, err := ctrl.store.GetTask(userId, id)
+	return task, taskError(err)
 }
 
-func GetTask(id uint16) (*model.Task, error) {
-	return model.GetTask(id)
+func (ctrl *Controller) GetTaskHistory(userId uint16, id int64) ([]*model.TaskStatusChange, error) {
+	history, err := ctrl.store.GetTaskStatusHistory(userId, id)
+	return history, taskError(err)
 }
 
-func EditTask(id uint16, name, description string) error {
-	if strings.Trim(name, " ") == "" {
-		return errors.New("edit_task_failure_name_is_required")
+// GetTaskOccurrences previews due dates of the next count occurrences of a recurring task
+func (ctrl *Controller) GetTaskOccurrences(userId uint16, id int64, count string) ([]time.Time, error) {
+	n := DefaultOccurrenceCount
+	if count != "" {
+		var err error
+		n, err = strconv.Atoi(count)
+		if err != nil || n < 1 || n > MaxOccurrenceCount {
+			return nil, ErrInvalidOccurrenceCount
+		}
+	}
+
+	task, err := ctrl.store.GetTask(userId, id)
+	if err != nil {
+		return nil, taskError(err)
+	}
+
+	if task.Recurrence == "" {
+		return nil, ErrTaskNotRecurring
 	}
 
-	return model.EditTask(id, name, description)
+	occurrences, err := task.NextOccurrences(time.Now(), n)
+	if err != nil {
+		return nil, InternalError(err)
+	}
+
+	return occurrences, nil
 }
 
-func StartTaskProgress(id uint16) error {
-	return model.StartTaskProgress(id)
+func (ctrl *Controller) GetTaskChildren(userId uint16, id int64) ([]*model.Task, error) {
+	children, err := ctrl.store.GetTaskChildren(userId, id)
+	return children, taskError(err)
 }
 
-func PauseTask(id uint16) error {
-	return model.PauseTask(id)
+// taskInputOf returns the current fields of the task as input, so they pass the same validation as the edited ones
+func taskInputOf(task *model.Task) TaskInput {
+	input := TaskInput{
+		Name:        task.Name,
+		Description: task.Description,
+		Priority:    task.Priority,
+		DueTimezone: task.DueTimezone,
+		Recurrence:  task.Recurrence,
+	}
+
+	if task.DueAt != nil {
+		input.DueAt = task.DueAt.Format(time.RFC3339Nano)
+	}
+	if task.ParentId != nil {
+		input.ParentId = strconv.FormatInt(*task.ParentId, 10)
+	}
+	if task.ProjectId != nil {
+		input.ProjectId = strconv.FormatInt(*task.ProjectId, 10)
+	}
+	if task.EstimateMinutes != nil {
+		input.Estimate = strconv.FormatInt(int64(*task.EstimateMinutes), 10)
+	}
+
+	return input
 }
 
-func DoneTask(id uint16) error {
-	return model.DoneTask(id)
+// EditTask changes only the fields of the body, the rest are taken from the current task
+func (ctrl *Controller) EditTask(userId uint16, id int64, edit TaskEditInput) error {
+	// the store must not be called while the task is locked, so public id of the parent is resolved before
+	if edit.ParentId != nil && *edit.ParentId != "" {
+		parentId, err := ctrl.ResolveTaskId(userId, *edit.ParentId)
+		if err != nil {
+			return ErrInvalidTaskParent.withCause(err)
+		}
+		resolved := strconv.FormatInt(parentId, 10)
+		edit.ParentId = &resolved
+	}
+
+	err := ctrl.store.EditTask(userId, id, func(task *model.Task) (model.TaskFields, error) {
+		input := taskInputOf(task)
+		for _, field := range []struct {
+			value  *string
+			target *string
+		}{
+			{edit.Name, &input.Name},
+			{edit.Description, &input.Description},
+			{edit.Priority, &input.Priority},
+			{edit.DueAt, &input.DueAt},
+			{edit.DueTimezone, &input.DueTimezone},
+			{edit.ParentId, &input.ParentId},
+			{edit.ProjectId, &input.ProjectId},
+			{edit.Recurrence, &input.Recurrence},
+			{edit.Estimate, &input.Estimate},
+		} {
+			if field.value != nil {
+				*field.target = *field.value
+			}
+		}
+
+		if strings.Trim(input.Name, " ") == "" {
+			return model.TaskFields{}, ErrEditTaskNameRequired
+		}
+
+		return ctrl.taskFields(userId, input)
+	})
+
+	return taskError(err)
+}
+
+// TaskMoveInput holds raw params of move request, neighbours are task ids or public ids
+type TaskMoveInput struct {
+	Status string // empty keeps the current status
+	After  string
+	Before string
+}
+
+func (ctrl *Controller) MoveTask(userId uint16, id int64, actorId uint16, input TaskMoveInput) (*model.Task, error) {
+	if input.Status != "" && !model.IsStatus(input.Status) {
+		return nil, ErrMoveTaskInvalidStatus
+	}
+
+	move := model.TaskMove{Status: input.Status}
+
+	var err error
+	if input.After != "" {
+		move.After, err = ctrl.resolveNeighbour(userId, input.After)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if input.Before != "" {
+		move.Before, err = ctrl.resolveNeighbour(userId, input.Before)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	task, err := ctrl.store.MoveTask(userId, id, actorId, move)
+	return task, taskError(err)
+}
+
+// resolveNeighbour is ResolveTaskId for task given in request body, missing one is not a missing resource
+func (ctrl *Controller) resolveNeighbour(userId uint16, param string) (int64, error) {
+	id, err := ctrl.ResolveTaskId(userId, param)
+	if errors.Is(err, ErrTaskNotFound) {
+		return 0, ErrMoveTaskNeighbour.withCause(err)
+	}
+	return id, err
+}
+
+// ResolveBlockerId is ResolveTaskId for blocker of the path, missing one is reported as missing blocker
+func (ctrl *Controller) ResolveBlockerId(userId uint16, param string) (int64, error) {
+	id, err := ctrl.ResolveTaskId(userId, param)
+	if errors.Is(err, ErrTaskNotFound) {
+		return 0, ErrTaskBlockerNotFound.withCause(err)
+	}
+	return id, err
+}
+
+func (ctrl *Controller) AddTaskBlocker(userId uint16, taskId, blockerId int64) error {
+	return taskError(ctrl.store.AddTaskBlocker(userId, taskId, blockerId))
+}
+
+func (ctrl *Controller) RemoveTaskBlocker(userId uint16, taskId, blockerId int64) error {
+	return taskError(ctrl.store.RemoveTaskBlocker(userId, taskId, blockerId))
 }
 
-func DeleteTask(id uint16) error {
-	return model.DeleteTask(id)
+func (ctrl *Controller) StartTaskProgress(userId uint16, id int64, actorId uint16) error {
+	return taskError(ctrl.store.StartTaskProgress(userId, id, actorId))
 }
 
-func RestoreTask(id uint16) error {
-	return model.RestoreTask(id)
+func (ctrl *Controller) PauseTask(userId uint16, id int64, actorId uint16) error {
+	return taskError(ctrl.store.PauseTask(userId, id, actorId))
 }
 
-func DeleteTaskCompletely(id uint16) error {
-	return model.DeleteTaskCompletely(id)
+func (ctrl *Controller) DoneTask(userId uint16, id int64, actorId uint16) error {
+	return taskError(ctrl.store.DoneTask(userId, id, actorId))
+}
+
+func (ctrl *Controller) DeleteTask(userId uint16, id int64, actorId uint16) error {
+	return taskError(ctrl.store.DeleteTask(userId, id, actorId))
+}
+
+func (ctrl *Controller) RestoreTask(userId uint16, id int64, actorId uint16) error {
+	return taskError(ctrl.store.RestoreTask(userId, id, actorId))
+}
+
+func (ctrl *Controller) DeleteTaskCompletely(userId uint16, id int64) error {
+	keys, err := ctrl.store.DeleteTaskCompletely(userId, id)
+	if err != nil {
+		return taskError(err)
+	}
+
+	ctrl.deleteBlobs(keys)
+
+	return nil
 }
 
-func FreeTaskTrash() error {
-	return model.FreeTaskTrash()
+// FreeTaskTrash takes raw project id, empty frees the whole trash
+func (ctrl *Controller) FreeTaskTrash(userId uint16, project string) error {
+	var projectId *int64
+	if project != "" {
+		id, err := ParseProjectId(project)
+		if err != nil {
+			return err
+		}
+
+		_, err = ctrl.store.GetProject(userId, id)
+		if err != nil {
+			return projectError(err)
+		}
+		projectId = &id
+	}
+
+	keys, err := ctrl.store.FreeTaskTrash(userId, projectId)
+	if err != nil {
+		return taskError(err)
+	}
+
+	ctrl.deleteBlobs(keys)
+
+	return nil
 }
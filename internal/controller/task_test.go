@@ -0,0 +1,27 @@
+package controller
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"todo/internal/model"
+)
+
+func TestTaskListCursor(t *testing.T) {
+	cursor := &model.TaskCursor{Id: 7, Value: "2"}
+
+	decoded, err := decodeTaskListCursor("-priority", encodeTaskListCursor("-priority", cursor))
+	assert.NoError(t, err)
+	assert.Equal(t, cursor, decoded)
+
+	// cursor of another order, malformed value of the sort column and garbage are rejected
+	_, err = decodeTaskListCursor("priority", encodeTaskListCursor("-priority", cursor))
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+
+	_, err = decodeTaskListCursor("created_at", encodeTaskListCursor("created_at", cursor))
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+
+	_, err = decodeTaskListCursor("id", "not a cursor!")
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+}
@@ -0,0 +1,49 @@
+package controller
+
+import (
+	"strings"
+	"time"
+	"todo/internal/model"
+)
+
+const maxTimeEntryNoteLength = 1000
+
+// TimeEntryInput holds raw fields of manual time entry request
+type TimeEntryInput struct {
+	StartedAt string // RFC 3339
+	EndedAt   string // RFC 3339, after StartedAt and not in the future
+	Note      string
+}
+
+func (ctrl *Controller) GetTaskTime(userId uint16, taskId int64) (*model.TaskTime, error) {
+	result, err := ctrl.store.GetTaskTime(userId, taskId)
+	return result, taskError(err)
+}
+
+// AddTimeEntry adds manual entry of the actor, tracked ones are added by status changes
+func (ctrl *Controller) AddTimeEntry(userId uint16, taskId int64, actorId uint16, input TimeEntryInput) (*model.TimeEntry, error) {
+	startedAt, err := time.Parse(time.RFC3339, input.StartedAt)
+	if err != nil {
+		return nil, ErrInvalidTimeEntryStart.withCause(err)
+	}
+
+	endedAt, err := time.Parse(time.RFC3339, input.EndedAt)
+	if err != nil {
+		return nil, ErrInvalidTimeEntryEnd.withCause(err)
+	}
+	if endedAt.Before(startedAt) || endedAt.After(time.Now()) {
+		return nil, ErrInvalidTimeEntryEnd
+	}
+
+	note := strings.TrimSpace(input.Note)
+	if len([]rune(note)) > maxTimeEntryNoteLength {
+		return nil, ErrTimeEntryNoteTooLong
+	}
+
+	entry, err := ctrl.store.AddTimeEntry(userId, taskId, actorId, model.TimeEntryFields{
+		StartedAt: startedAt,
+		EndedAt:   endedAt,
+		Note:      note,
+	})
+	return entry, taskError(err)
+}
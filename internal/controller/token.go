@@ -0,0 +1,188 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"todo/internal/config"
+	"todo/internal/model"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/google/uuid"
+)
+
+const (
+	tokenTypeAccess  = "access"
+	tokenTypeRefresh = "refresh"
+)
+
+type Tokens struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type" example:"Bearer"`
+	ExpiresIn    int64  `json:"expires_in" example:"900"` // access token lifetime in seconds
+}
+
+type tokenClaims struct {
+	userId    uint16
+	tokenId   string
+	expiresAt time.Time
+}
+
+func signToken(userId uint16, tokenType string, ttl time.Duration) (string, string, time.Time, error) {
+	tokenId, err := uuid.NewRandom()
+	if err != nil {
+		return "", "", time.Time{}, err
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(ttl)
+
+	payload := jwt.MapClaims{}
+	payload["_time"] = now.UnixMilli()
+	payload["_content"] = userId
+	payload["_token_id"] = tokenId
+	payload["_type"] = tokenType
+	payload["exp"] = expiresAt.Unix()
+
+	jwtToken := jwt.NewWithClaims(jwt.SigningMethodHS256, payload)
+	token, err := jwtToken.SignedString([]byte(config.JwtSecret()))
+	if err != nil {
+		return "", "", time.Time{}, err
+	}
+
+	return token, tokenId.String(), expiresAt, nil
+}
+
+func parseToken(tokenString, tokenType string) (*tokenClaims, error) {
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return []byte(config.JwtSecret()), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// tokens without exp were issued before expiry was introduced and are never valid
+	if !claims.VerifyExpiresAt(time.Now().Unix(), true) {
+		return nil, errors.New("token is expired")
+	}
+
+	if claims["_type"] != tokenType {
+		return nil, errors.New("wrong token type")
+	}
+
+	content, ok := claims["_content"].(float64)
+	if !ok {
+		return nil, errors.New("token has no user")
+	}
+
+	tokenId, ok := claims["_token_id"].(string)
+	if !ok {
+		return nil, errors.New("token has no id")
+	}
+
+	exp, _ := claims["exp"].(float64)
+
+	return &tokenClaims{
+		userId:    uint16(content),
+		tokenId:   tokenId,
+		expiresAt: time.Unix(int64(exp), 0),
+	}, nil
+}
+
+func (ctrl *Controller) issueTokens(userId uint16) (*Tokens, error) {
+	accessToken, _, _, err := signToken(userId, tokenTypeAccess, config.AccessTokenTTL())
+	if err != nil {
+		return nil, InternalError(err)
+	}
+
+	refreshToken, refreshTokenId, refreshExpiresAt, err := signToken(userId, tokenTypeRefresh, config.RefreshTokenTTL())
+	if err != nil {
+		return nil, InternalError(err)
+	}
+
+	err = ctrl.store.SaveRefreshToken(refreshTokenId, userId, refreshExpiresAt)
+	if err != nil {
+		return nil, InternalError(err)
+	}
+
+	return &Tokens{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(config.AccessTokenTTL().Seconds()),
+	}, nil
+}
+
+// Authorize validates access token and returns authorized user id
+func (ctrl *Controller) Authorize(accessToken string) (uint16, error) {
+	claims, err := parseToken(accessToken, tokenTypeAccess)
+	if err != nil {
+		return 0, ErrInvalidToken.withCause(err)
+	}
+
+	revoked, err := ctrl.store.IsTokenRevoked(claims.tokenId)
+	if err != nil {
+		return 0, InternalError(err)
+	}
+	if revoked {
+		return 0, ErrInvalidToken.withCause(errors.New("token is revoked"))
+	}
+
+	return claims.userId, nil
+}
+
+// Refresh exchanges refresh token for a new pair of tokens, old refresh token becomes unusable
+func (ctrl *Controller) Refresh(refreshToken string) (*Tokens, error) {
+	claims, err := parseToken(refreshToken, tokenTypeRefresh)
+	if err != nil {
+		return nil, ErrRefreshTokenInvalid.withCause(err)
+	}
+
+	userId, err := ctrl.store.ConsumeRefreshToken(claims.tokenId)
+	if err != nil {
+		if errors.Is(err, model.ErrRefreshTokenNotFound) {
+			return nil, ErrRefreshTokenInvalid.withCause(err)
+		}
+		return nil, InternalError(err)
+	}
+
+	return ctrl.issueTokens(userId)
+}
+
+// Logout revokes access token and, if given, refresh token of the session
+func (ctrl *Controller) Logout(accessToken, refreshToken string) error {
+	claims, err := parseToken(accessToken, tokenTypeAccess)
+	if err != nil {
+		return ErrInvalidToken.withCause(err)
+	}
+
+	err = ctrl.store.RevokeToken(claims.tokenId, claims.expiresAt)
+	if err != nil {
+		return InternalError(err)
+	}
+
+	if refreshToken == "" {
+		return nil
+	}
+
+	refreshClaims, err := parseToken(refreshToken, tokenTypeRefresh)
+	if err != nil {
+		return ErrRefreshTokenInvalid.withCause(err)
+	}
+	if refreshClaims.userId != claims.userId {
+		return ErrRefreshTokenInvalid
+	}
+
+	_, err = ctrl.store.ConsumeRefreshToken(refreshClaims.tokenId)
+	if err != nil && !errors.Is(err, model.ErrRefreshTokenNotFound) {
+		return InternalError(err)
+	}
+
+	return nil
+}
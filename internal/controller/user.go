@@ -1,32 +1,67 @@
 package controller
 
 import (
-	"time"
+	"errors"
+	"strings"
 
-	"todo/internal/config"
+	"todo/internal/model"
 
-	"github.com/golang-jwt/jwt"
-	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
 )
 
-func Authenticate(login string, password string) (string, error) {
-	id := 1
+const (
+	minPasswordLength = 6
+	maxPasswordLength = 72 // bytes, bcrypt does not take longer passwords
+	maxLoginLength    = 120
+)
+
+func (ctrl *Controller) Register(login, password string) (uint16, error) {
+	login = strings.Trim(login, " ")
+	if login == "" {
+		return uint16(0), ErrRegisterLoginRequired
+	}
+
+	if len([]rune(login)) > maxLoginLength {
+		return uint16(0), ErrRegisterLoginTooLong
+	}
+
+	if len(password) < minPasswordLength {
+		return uint16(0), ErrRegisterPasswordTooShort
+	}
+
+	if len(password) > maxPasswordLength {
+		return uint16(0), ErrRegisterPasswordTooLong
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return uint16(0), InternalError(err)
+	}
 
-	tokenId, err := uuid.NewRandom()
+	id, err := ctrl.store.CreateUser(login, string(hash))
 	if err != nil {
-		return "", err
+		if errors.Is(err, model.ErrUserLoginTaken) {
+			return uint16(0), ErrRegisterLoginTaken
+		}
+		return uint16(0), InternalError(err)
 	}
 
-	payload := jwt.MapClaims{}
-	payload["_time"] = time.Now().UnixMilli()
-	payload["_content"] = id
-	payload["_token_id"] = tokenId
+	return id, nil
+}
+
+func (ctrl *Controller) Authenticate(login string, password string) (*Tokens, error) {
+	user, err := ctrl.store.GetUserByLogin(strings.Trim(login, " "))
+	if err != nil {
+		if errors.Is(err, model.ErrUserNotFound) {
+			return nil, ErrLoginIncorrectCredentials
+		}
+		return nil, InternalError(err)
+	}
 
-	jwtToken := jwt.NewWithClaims(jwt.SigningMethodHS256, payload)
-	token, err := jwtToken.SignedString([]byte(config.JwtSecret()))
+	err = bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password))
 	if err != nil {
-		return "", err
+		return nil, ErrLoginIncorrectCredentials
 	}
 
-	return token, nil
+	return ctrl.issueTokens(user.Id)
 }
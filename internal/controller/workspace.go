@@ -0,0 +1,190 @@
+package controller
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"todo/internal/model"
+)
+
+// Action is what a request does in a workspace, each action needs a role
+type Action int
+
+const (
+	ActionView   Action = iota // read tasks, labels, projects and members
+	ActionEdit                 // create and change them, moving tasks to trash too
+	ActionPurge                // delete tasks completely and free trash
+	ActionManage               // invite, change and remove members
+)
+
+var actionRoles = map[Action][]string{
+	ActionView:   {model.RoleOwner, model.RoleEditor, model.RoleViewer},
+	ActionEdit:   {model.RoleOwner, model.RoleEditor},
+	ActionPurge:  {model.RoleOwner},
+	ActionManage: {model.RoleOwner},
+}
+
+// parseUserId parses 16-bit user id, workspace id is the id of its owner
+func parseUserId(param string) (uint16, error) {
+	id, err := strconv.ParseUint(param, 10, 16)
+	if err != nil {
+		return 0, err
+	}
+	if id == 0 {
+		return 0, errors.New("id must be positive")
+	}
+
+	return uint16(id), nil
+}
+
+func ParseWorkspaceId(param string) (uint16, error) {
+	id, err := parseUserId(param)
+	if err != nil {
+		return 0, ErrInvalidWorkspaceId.withCause(err)
+	}
+
+	return id, nil
+}
+
+func ParseMemberId(param string) (uint16, error) {
+	id, err := parseUserId(param)
+	if err != nil {
+		return 0, ErrInvalidMemberId.withCause(err)
+	}
+
+	return id, nil
+}
+
+// AuthorizeWorkspace checks role of the user in the workspace allows the action and returns the workspace id,
+// which is the owner id every store method is scoped to. Empty workspace is own workspace of the user
+func (ctrl *Controller) AuthorizeWorkspace(userId uint16, workspace string, action Action) (uint16, error) {
+	if workspace == "" {
+		return userId, nil
+	}
+
+	workspaceId, err := ParseWorkspaceId(workspace)
+	if err != nil {
+		return 0, err
+	}
+
+	role, err := ctrl.store.GetWorkspaceRole(workspaceId, userId)
+	if err != nil {
+		return 0, workspaceError(err)
+	}
+
+	if !containsString(actionRoles[action], role) {
+		e := ErrWorkspaceForbidden.withCause(errors.New(role + " role"))
+		e.Details = map[string]interface{}{
+			"role": role,
+		}
+		return 0, e
+	}
+
+	return workspaceId, nil
+}
+
+func (ctrl *Controller) GetWorkspaceList(userId uint16) ([]*model.Workspace, error) {
+	list, err := ctrl.store.GetWorkspaceList(userId)
+	return list, workspaceError(err)
+}
+
+func (ctrl *Controller) GetWorkspaceInvitations(userId uint16) ([]*model.Workspace, error) {
+	list, err := ctrl.store.GetWorkspaceInvitations(userId)
+	return list, workspaceError(err)
+}
+
+func (ctrl *Controller) AcceptWorkspaceInvitation(userId uint16, workspace string) error {
+	workspaceId, err := ParseWorkspaceId(workspace)
+	if err != nil {
+		return err
+	}
+
+	return workspaceError(ctrl.store.AcceptWorkspaceInvitation(workspaceId, userId))
+}
+
+func (ctrl *Controller) GetWorkspaceMembers(userId uint16, workspace string) ([]*model.WorkspaceMember, error) {
+	workspaceId, err := ctrl.AuthorizeWorkspace(userId, workspace, ActionView)
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := ctrl.store.GetWorkspaceMembers(workspaceId)
+	return list, workspaceError(err)
+}
+
+func (ctrl *Controller) InviteWorkspaceMember(userId uint16, workspace, login, role string) (*model.WorkspaceMember, error) {
+	workspaceId, err := ctrl.AuthorizeWorkspace(userId, workspace, ActionManage)
+	if err != nil {
+		return nil, err
+	}
+
+	login = strings.Trim(login, " ")
+	if login == "" {
+		return nil, ErrMemberLoginRequired
+	}
+	if !containsString(model.Roles, role) {
+		return nil, ErrInvalidMemberRole
+	}
+
+	member, err := ctrl.store.InviteWorkspaceMember(workspaceId, login, role)
+	if errors.Is(err, model.ErrUserNotFound) {
+		return nil, ErrMemberLoginNotFound.withCause(err)
+	}
+	return member, workspaceError(err)
+}
+
+func (ctrl *Controller) EditWorkspaceMember(userId uint16, workspace string, memberId uint16, role string) error {
+	workspaceId, err := ctrl.AuthorizeWorkspace(userId, workspace, ActionManage)
+	if err != nil {
+		return err
+	}
+
+	if !containsString(model.Roles, role) {
+		return ErrInvalidMemberRole
+	}
+
+	return workspaceError(ctrl.store.EditWorkspaceMember(workspaceId, memberId, role))
+}
+
+// RemoveWorkspaceMember needs manage action unless users remove themselves, that is leaving
+// the workspace or declining the invitation
+func (ctrl *Controller) RemoveWorkspaceMember(userId uint16, workspace string, memberId uint16) error {
+	workspaceId, err := ParseWorkspaceId(workspace)
+	if err != nil {
+		return err
+	}
+
+	if memberId != userId {
+		_, err = ctrl.AuthorizeWorkspace(userId, workspace, ActionManage)
+		if err != nil {
+			return err
+		}
+	}
+
+	return workspaceError(ctrl.store.RemoveWorkspaceMember(workspaceId, memberId))
+}
+
+// workspaceError translates model errors of workspace store into controller errors
+func workspaceError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	if errors.Is(err, model.ErrWorkspaceNotFound) {
+		return ErrWorkspaceNotFound.withCause(err)
+	}
+
+	if errors.Is(err, model.ErrMemberNotFound) {
+		return ErrMemberNotFound.withCause(err)
+	}
+
+	if errors.Is(err, model.ErrInvitationNotFound) {
+		return ErrInvitationNotFound.withCause(err)
+	}
+
+	if errors.Is(err, model.ErrMemberExists) {
+		return ErrMemberExists.withCause(err)
+	}
+
+	return InternalError(err)
+}
@@ -0,0 +1,289 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"log"
+	"todo/internal/config"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+)
+
+type Label struct {
+	Id    int64  `json:"id"`
+	Name  string `json:"name" example:"backend"`
+	Color string `json:"color" example:"#1d76db"`
+}
+
+var (
+	ErrLabelNotFound  = errors.New("label not found")
+	ErrLabelNameTaken = errors.New("label name is already taken")
+)
+
+// LabelStore methods are scoped to the owner the same way TaskStore ones are
+type LabelStore interface {
+	GetLabelList(ownerId uint16) ([]*Label, error)
+	CreateLabel(ownerId uint16, name, color string) (*Label, error)
+	GetLabel(ownerId uint16, id int64) (*Label, error)
+	EditLabel(ownerId uint16, id int64, name, color string) error
+	DeleteLabel(ownerId uint16, id int64) error
+	// AddTaskLabel does nothing if the task already has the label
+	AddTaskLabel(ownerId uint16, taskId, labelId int64) error
+	// RemoveTaskLabel does nothing if the task does not have the label
+	RemoveTaskLabel(ownerId uint16, taskId, labelId int64) error
+}
+
+func labelError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation {
+		return ErrLabelNameTaken
+	}
+	return err
+}
+
+func (s *PgStore) GetLabelList(ownerId uint16) ([]*Label, error) {
+	var result []*Label = []*Label{}
+
+	rows, err := s.pool.Query(context.Background(), `
+		SELECT id, name, color
+		FROM label
+		WHERE owner_id = $1
+		ORDER BY name
+	`, ownerId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var item Label
+
+		err = rows.Scan(&item.Id, &item.Name, &item.Color)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, &item)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	if config.DebugLog() {
+		log.Println("label list: successfully retrieved data from db")
+	}
+
+	return result, nil
+}
+
+func (s *PgStore) CreateLabel(ownerId uint16, name, color string) (*Label, error) {
+	item := Label{Name: name, Color: color}
+
+	err := s.pool.QueryRow(context.Background(), `
+		INSERT INTO label(owner_id, name, color)
+		VALUES ($1, $2, $3) RETURNING id`,
+		ownerId,
+		name,
+		color,
+	).Scan(&item.Id)
+	if err != nil {
+		return nil, labelError(err)
+	}
+
+	if config.DebugLog() {
+		log.Println("label create: successfully created data in db")
+	}
+
+	return &item, nil
+}
+
+func (s *PgStore) GetLabel(ownerId uint16, id int64) (*Label, error) {
+	var item Label
+
+	err := s.pool.QueryRow(context.Background(), `
+		SELECT id, name, color
+		FROM label
+		WHERE id = $1 AND owner_id = $2
+	`, id, ownerId).Scan(&item.Id, &item.Name, &item.Color)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrLabelNotFound
+		}
+		return nil, err
+	}
+
+	if config.DebugLog() {
+		log.Println("get label: successfully retrieved data in db")
+	}
+
+	return &item, nil
+}
+
+func (s *PgStore) EditLabel(ownerId uint16, id int64, name, color string) error {
+	tag, err := s.pool.Exec(context.Background(), `
+		UPDATE label
+		SET name = $1,
+			color = $2
+		WHERE id = $3 AND owner_id = $4`,
+		name,
+		color,
+		id,
+		ownerId,
+	)
+	if err != nil {
+		return labelError(err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return ErrLabelNotFound
+	}
+
+	if config.DebugLog() {
+		log.Println("label edit: successfully edited data in db")
+	}
+
+	return nil
+}
+
+func (s *PgStore) DeleteLabel(ownerId uint16, id int64) error {
+	tag, err := s.pool.Exec(context.Background(), `
+		DELETE FROM label
+		WHERE id = $1 AND owner_id = $2`,
+		id,
+		ownerId,
+	)
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return ErrLabelNotFound
+	}
+
+	if config.DebugLog() {
+		log.Println("label delete: successfully deleted data in db")
+	}
+
+	return nil
+}
+
+// ownedTaskAndLabel checks both task and label belong to the owner
+func (s *PgStore) ownedTaskAndLabel(ctx context.Context, ownerId uint16, taskId, labelId int64) error {
+	var taskFound, labelFound bool
+
+	err := s.pool.QueryRow(ctx, `
+		SELECT
+			EXISTS (SELECT 1 FROM task WHERE id = $1 AND owner_id = $3),
+			EXISTS (SELECT 1 FROM label WHERE id = $2 AND owner_id = $3)
+	`, taskId, labelId, ownerId).Scan(&taskFound, &labelFound)
+	if err != nil {
+		return err
+	}
+
+	if !taskFound {
+		return ErrTaskNotFound
+	}
+	if !labelFound {
+		return ErrLabelNotFound
+	}
+
+	return nil
+}
+
+func (s *PgStore) AddTaskLabel(ownerId uint16, taskId, labelId int64) error {
+	ctx := context.Background()
+
+	_, err := s.pool.Exec(ctx, `
+		INSERT INTO task_label(task_id, label_id)
+		SELECT t.id, l.id
+		FROM task t, label l
+		WHERE t.id = $1 AND t.owner_id = $3 AND l.id = $2 AND l.owner_id = $3
+		ON CONFLICT DO NOTHING`,
+		taskId,
+		labelId,
+		ownerId,
+	)
+	if err != nil {
+		return err
+	}
+
+	// nothing inserted either because of missing task or label or because it is already there
+	err = s.ownedTaskAndLabel(ctx, ownerId, taskId, labelId)
+	if err != nil {
+		return err
+	}
+
+	if config.DebugLog() {
+		log.Println("add task label: successfully created data in db")
+	}
+
+	return nil
+}
+
+func (s *PgStore) RemoveTaskLabel(ownerId uint16, taskId, labelId int64) error {
+	ctx := context.Background()
+
+	err := s.ownedTaskAndLabel(ctx, ownerId, taskId, labelId)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.pool.Exec(ctx, `
+		DELETE FROM task_label
+		WHERE task_id = $1 AND label_id = $2`,
+		taskId,
+		labelId,
+	)
+	if err != nil {
+		return err
+	}
+
+	if config.DebugLog() {
+		log.Println("remove task label: successfully deleted data in db")
+	}
+
+	return nil
+}
+
+// loadTaskLabels fills labels of all tasks by a single query
+func (s *PgStore) loadTaskLabels(ctx context.Context, tasks []*Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	byId := make(map[int64]*Task, len(tasks))
+	ids := make([]int64, 0, len(tasks))
+	for _, task := range tasks {
+		byId[task.Id] = task
+		ids = append(ids, task.Id)
+	}
+
+	rows, err := s.pool.Query(ctx, `
+		SELECT tl.task_id, l.id, l.name, l.color
+		FROM task_label tl
+		JOIN label l ON l.id = tl.label_id
+		WHERE tl.task_id = ANY($1)
+		ORDER BY l.name
+	`, ids)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var taskId int64
+		var item Label
+
+		err = rows.Scan(&taskId, &item.Id, &item.Name, &item.Color)
+		if err != nil {
+			return err
+		}
+
+		task := byId[taskId]
+		task.Labels = append(task.Labels, &item)
+	}
+
+	return rows.Err()
+}
@@ -0,0 +1,178 @@
+package model
+
+import (
+	"sort"
+	"strings"
+)
+
+type memoryLabel struct {
+	Label
+	ownerId uint16
+}
+
+// ownedLabel must be called with s.mu held
+func (s *MemoryStore) ownedLabel(ownerId uint16, id int64) (*memoryLabel, error) {
+	label, ok := s.labels[id]
+	if !ok || label.ownerId != ownerId {
+		return nil, ErrLabelNotFound
+	}
+
+	return label, nil
+}
+
+// labelNameTaken must be called with s.mu held
+func (s *MemoryStore) labelNameTaken(ownerId uint16, name string, exceptId int64) bool {
+	for _, label := range s.labels {
+		if label.ownerId == ownerId && label.Name == name && label.Id != exceptId {
+			return true
+		}
+	}
+	return false
+}
+
+// sortLabels orders labels by name the same way postgres does
+func sortLabels(labels []*Label) {
+	sort.Slice(labels, func(i, j int) bool {
+		return strings.Compare(labels[i].Name, labels[j].Name) < 0
+	})
+}
+
+func (s *MemoryStore) GetLabelList(ownerId uint16) ([]*Label, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var result []*Label = []*Label{}
+	for _, label := range s.labels {
+		if label.ownerId == ownerId {
+			item := label.Label
+			result = append(result, &item)
+		}
+	}
+	sortLabels(result)
+
+	return result, nil
+}
+
+func (s *MemoryStore) CreateLabel(ownerId uint16, name, color string) (*Label, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.labelNameTaken(ownerId, name, 0) {
+		return nil, ErrLabelNameTaken
+	}
+
+	s.lastLabelId++
+	label := &memoryLabel{
+		Label: Label{
+			Id:    s.lastLabelId,
+			Name:  name,
+			Color: color,
+		},
+		ownerId: ownerId,
+	}
+	s.labels[label.Id] = label
+
+	item := label.Label
+	return &item, nil
+}
+
+func (s *MemoryStore) GetLabel(ownerId uint16, id int64) (*Label, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	label, err := s.ownedLabel(ownerId, id)
+	if err != nil {
+		return nil, err
+	}
+
+	item := label.Label
+	return &item, nil
+}
+
+func (s *MemoryStore) EditLabel(ownerId uint16, id int64, name, color string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	label, err := s.ownedLabel(ownerId, id)
+	if err != nil {
+		return err
+	}
+
+	if s.labelNameTaken(ownerId, name, id) {
+		return ErrLabelNameTaken
+	}
+
+	label.Name = name
+	label.Color = color
+
+	return nil
+}
+
+func (s *MemoryStore) DeleteLabel(ownerId uint16, id int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.ownedLabel(ownerId, id); err != nil {
+		return err
+	}
+
+	delete(s.labels, id)
+	for _, labels := range s.taskLabels {
+		delete(labels, id)
+	}
+
+	return nil
+}
+
+func (s *MemoryStore) AddTaskLabel(ownerId uint16, taskId, labelId int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.ownedTask(ownerId, taskId); err != nil {
+		return err
+	}
+	if _, err := s.ownedLabel(ownerId, labelId); err != nil {
+		return err
+	}
+
+	if s.taskLabels[taskId] == nil {
+		s.taskLabels[taskId] = map[int64]bool{}
+	}
+	s.taskLabels[taskId][labelId] = true
+
+	return nil
+}
+
+func (s *MemoryStore) RemoveTaskLabel(ownerId uint16, taskId, labelId int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.ownedTask(ownerId, taskId); err != nil {
+		return err
+	}
+	if _, err := s.ownedLabel(ownerId, labelId); err != nil {
+		return err
+	}
+
+	delete(s.taskLabels[taskId], labelId)
+
+	return nil
+}
+
+// hasLabels reports whether the task has any or all of the named labels, must be called with s.mu held
+func (s *MemoryStore) hasLabels(task *Task, names []string, all bool) bool {
+	matched := 0
+	for _, name := range names {
+		for labelId := range s.taskLabels[task.Id] {
+			if s.labels[labelId].Name == name {
+				matched++
+				break
+			}
+		}
+	}
+
+	if all {
+		return matched == len(names)
+	}
+	return matched > 0
+}
@@ -0,0 +1,69 @@
+package model
+
+import (
+	"errors"
+	"strings"
+)
+
+// positionDigits are ordered the same way by byte and by postgres "C" collation.
+// Position keys are fractions in this base without the leading "0.", so a key
+// fits between any two others without renumbering
+const positionDigits = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+var errPositionOrder = errors.New("position keys are not ordered")
+
+// positionBetween returns a key greater than a and less than b, empty a is the start
+// and empty b is the end of the column. Keys never end with "0", so there is always
+// a key before any other one
+func positionBetween(a, b string) (string, error) {
+	if b != "" && a >= b {
+		return "", errPositionOrder
+	}
+
+	if b != "" {
+		n := 0
+		for n < len(b) && positionDigit(a, n) == b[n] {
+			n++
+		}
+		if n > 0 {
+			var rest string
+			if n < len(a) {
+				rest = a[n:]
+			}
+			mid, err := positionBetween(rest, b[n:])
+			return b[:n] + mid, err
+		}
+	}
+
+	digitA := 0
+	if a != "" {
+		digitA = strings.IndexByte(positionDigits, a[0])
+	}
+	digitB := len(positionDigits)
+	if b != "" {
+		digitB = strings.IndexByte(positionDigits, b[0])
+	}
+
+	if digitB-digitA > 1 {
+		return string(positionDigits[(digitA+digitB+1)/2]), nil
+	}
+
+	if len(b) > 1 {
+		return b[:1], nil
+	}
+
+	var rest string
+	if a != "" {
+		rest = a[1:]
+	}
+	mid, err := positionBetween(rest, "")
+	return string(positionDigits[digitA]) + mid, err
+}
+
+// positionDigit pads key with zeros
+func positionDigit(key string, i int) byte {
+	if i < len(key) {
+		return key[i]
+	}
+	return positionDigits[0]
+}
@@ -0,0 +1,42 @@
+package model
+
+import (
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPositionBetween(t *testing.T) {
+	first, err := positionBetween("", "")
+	assert.NoError(t, err)
+
+	// keep inserting at the start, into the middle and at the end
+	keys := []string{first}
+	for i := 0; i < 300; i++ {
+		var lower, upper string
+		switch i % 3 {
+		case 0:
+			upper = keys[0]
+		case 1:
+			lower, upper = keys[len(keys)/2-1], keys[len(keys)/2]
+		case 2:
+			lower = keys[len(keys)-1]
+		}
+
+		key, err := positionBetween(lower, upper)
+		assert.NoError(t, err)
+		assert.Greater(t, key, lower)
+		if upper != "" {
+			assert.Less(t, key, upper)
+		}
+		assert.False(t, strings.HasSuffix(key, "0"), key)
+
+		keys = append(keys, key)
+		sort.Strings(keys)
+	}
+
+	_, err = positionBetween("b", "a")
+	assert.Error(t, err)
+}
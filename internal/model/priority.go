@@ -0,0 +1,37 @@
+package model
+
+const (
+	PriorityLow    = "low"
+	PriorityNormal = "normal"
+	PriorityHigh   = "high"
+	PriorityUrgent = "urgent"
+)
+
+// priorities from the lowest, index is the rank stored in task.priority
+var priorities = []string{
+	PriorityLow,
+	PriorityNormal,
+	PriorityHigh,
+	PriorityUrgent,
+}
+
+func IsPriority(priority string) bool {
+	return priorityRank(priority) >= 0
+}
+
+// priorityRank returns -1 for unknown priority
+func priorityRank(priority string) int16 {
+	for i, p := range priorities {
+		if p == priority {
+			return int16(i)
+		}
+	}
+	return -1
+}
+
+func priorityName(rank int16) string {
+	if rank < 0 || int(rank) >= len(priorities) {
+		return PriorityNormal
+	}
+	return priorities[rank]
+}
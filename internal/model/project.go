@@ -0,0 +1,222 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+	"todo/internal/config"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+)
+
+// Project groups tasks, a task belongs to at most one project
+type Project struct {
+	Id          int64     `json:"id"`
+	Name        string    `json:"name" example:"Mobile app"`
+	Description string    `json:"description"`
+	Color       string    `json:"color" example:"#1d76db"`
+	Archived    bool      `json:"archived"` // archived project takes no new tasks
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// ProjectFields are the user editable fields of a project
+type ProjectFields struct {
+	Name        string
+	Description string
+	Color       string
+	Archived    bool
+}
+
+var (
+	ErrProjectNotFound  = errors.New("project not found")
+	ErrProjectNameTaken = errors.New("project name is already taken")
+	ErrProjectArchived  = errors.New("project is archived")
+)
+
+// ProjectStore methods are scoped to the owner the same way TaskStore ones are
+type ProjectStore interface {
+	// GetProjectList returns projects ordered by name, archived nil means both archived and active ones
+	GetProjectList(ownerId uint16, archived *bool) ([]*Project, error)
+	CreateProject(ownerId uint16, fields ProjectFields) (*Project, error)
+	GetProject(ownerId uint16, id int64) (*Project, error)
+	EditProject(ownerId uint16, id int64, fields ProjectFields) error
+	// DeleteProject keeps tasks of the project, they are left without project
+	DeleteProject(ownerId uint16, id int64) error
+}
+
+const projectColumns = "id, name, description, color, archived, created_at, updated_at"
+
+func scanProject(row pgx.Row) (*Project, error) {
+	var item Project
+
+	err := row.Scan(&item.Id, &item.Name, &item.Description, &item.Color, &item.Archived, &item.CreatedAt, &item.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &item, nil
+}
+
+func projectError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation {
+		return ErrProjectNameTaken
+	}
+	return err
+}
+
+func (s *PgStore) GetProjectList(ownerId uint16, archived *bool) ([]*Project, error) {
+	var result []*Project = []*Project{}
+
+	rows, err := s.pool.Query(context.Background(), `
+		SELECT `+projectColumns+`
+		FROM project
+		WHERE owner_id = $1 AND ($2::boolean IS NULL OR archived = $2)
+		ORDER BY name
+	`, ownerId, archived)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		item, err := scanProject(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, item)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	if config.DebugLog() {
+		log.Println("project list: successfully retrieved data from db")
+	}
+
+	return result, nil
+}
+
+func (s *PgStore) CreateProject(ownerId uint16, fields ProjectFields) (*Project, error) {
+	item, err := scanProject(s.pool.QueryRow(context.Background(), `
+		INSERT INTO project(owner_id, name, description, color, archived)
+		VALUES ($1, $2, $3, $4, $5) RETURNING `+projectColumns,
+		ownerId,
+		fields.Name,
+		fields.Description,
+		fields.Color,
+		fields.Archived,
+	))
+	if err != nil {
+		return nil, projectError(err)
+	}
+
+	if config.DebugLog() {
+		log.Println("project create: successfully created data in db")
+	}
+
+	return item, nil
+}
+
+func (s *PgStore) GetProject(ownerId uint16, id int64) (*Project, error) {
+	item, err := scanProject(s.pool.QueryRow(context.Background(), `
+		SELECT `+projectColumns+`
+		FROM project
+		WHERE id = $1 AND owner_id = $2
+	`, id, ownerId))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrProjectNotFound
+		}
+		return nil, err
+	}
+
+	if config.DebugLog() {
+		log.Println("get project: successfully retrieved data in db")
+	}
+
+	return item, nil
+}
+
+func (s *PgStore) EditProject(ownerId uint16, id int64, fields ProjectFields) error {
+	tag, err := s.pool.Exec(context.Background(), `
+		UPDATE project
+		SET name = $1,
+			description = $2,
+			color = $3,
+			archived = $4,
+			updated_at = now()
+		WHERE id = $5 AND owner_id = $6`,
+		fields.Name,
+		fields.Description,
+		fields.Color,
+		fields.Archived,
+		id,
+		ownerId,
+	)
+	if err != nil {
+		return projectError(err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return ErrProjectNotFound
+	}
+
+	if config.DebugLog() {
+		log.Println("project edit: successfully edited data in db")
+	}
+
+	return nil
+}
+
+func (s *PgStore) DeleteProject(ownerId uint16, id int64) error {
+	tag, err := s.pool.Exec(context.Background(), `
+		DELETE FROM project
+		WHERE id = $1 AND owner_id = $2`,
+		id,
+		ownerId,
+	)
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return ErrProjectNotFound
+	}
+
+	if config.DebugLog() {
+		log.Println("project delete: successfully deleted data in db")
+	}
+
+	return nil
+}
+
+// checkTaskProject checks the project belongs to the owner and takes the task, taskId is 0 for a new task.
+// Archived project takes no new tasks, the ones already in it stay there
+func checkTaskProject(ctx context.Context, tx pgx.Tx, ownerId uint16, taskId, projectId int64) error {
+	var archived, inProject bool
+
+	err := tx.QueryRow(ctx, `
+		SELECT p.archived, EXISTS (SELECT 1 FROM task WHERE id = $3 AND project_id = p.id)
+		FROM project p
+		WHERE p.id = $1 AND p.owner_id = $2
+		FOR SHARE
+	`, projectId, ownerId, taskId).Scan(&archived, &inProject)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrProjectNotFound
+		}
+		return err
+	}
+
+	if archived && !inProject {
+		return ErrProjectArchived
+	}
+
+	return nil
+}
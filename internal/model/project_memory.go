@@ -0,0 +1,153 @@
+package model
+
+import (
+	"sort"
+	"time"
+)
+
+// memoryProject keeps the owner next to the project, Project itself does not expose it
+type memoryProject struct {
+	Project
+	ownerId uint16
+}
+
+// ownedProject must be called with s.mu held
+func (s *MemoryStore) ownedProject(ownerId uint16, id int64) (*memoryProject, error) {
+	project, ok := s.projects[id]
+	if !ok || project.ownerId != ownerId {
+		return nil, ErrProjectNotFound
+	}
+
+	return project, nil
+}
+
+// projectNameTaken must be called with s.mu held
+func (s *MemoryStore) projectNameTaken(ownerId uint16, name string, exceptId int64) bool {
+	for _, project := range s.projects {
+		if project.ownerId == ownerId && project.Name == name && project.Id != exceptId {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *MemoryStore) GetProjectList(ownerId uint16, archived *bool) ([]*Project, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var result []*Project = []*Project{}
+	for _, project := range s.projects {
+		if project.ownerId != ownerId || (archived != nil && project.Archived != *archived) {
+			continue
+		}
+
+		item := project.Project
+		result = append(result, &item)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+
+	return result, nil
+}
+
+func (s *MemoryStore) CreateProject(ownerId uint16, fields ProjectFields) (*Project, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.projectNameTaken(ownerId, fields.Name, 0) {
+		return nil, ErrProjectNameTaken
+	}
+
+	now := time.Now()
+
+	s.lastProjectId++
+	project := &memoryProject{
+		Project: Project{
+			Id:          s.lastProjectId,
+			Name:        fields.Name,
+			Description: fields.Description,
+			Color:       fields.Color,
+			Archived:    fields.Archived,
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		},
+		ownerId: ownerId,
+	}
+	s.projects[project.Id] = project
+
+	item := project.Project
+	return &item, nil
+}
+
+func (s *MemoryStore) GetProject(ownerId uint16, id int64) (*Project, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	project, err := s.ownedProject(ownerId, id)
+	if err != nil {
+		return nil, err
+	}
+
+	item := project.Project
+	return &item, nil
+}
+
+func (s *MemoryStore) EditProject(ownerId uint16, id int64, fields ProjectFields) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	project, err := s.ownedProject(ownerId, id)
+	if err != nil {
+		return err
+	}
+
+	if s.projectNameTaken(ownerId, fields.Name, id) {
+		return ErrProjectNameTaken
+	}
+
+	project.Name = fields.Name
+	project.Description = fields.Description
+	project.Color = fields.Color
+	project.Archived = fields.Archived
+	project.UpdatedAt = time.Now()
+
+	return nil
+}
+
+func (s *MemoryStore) DeleteProject(ownerId uint16, id int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.ownedProject(ownerId, id); err != nil {
+		return err
+	}
+
+	delete(s.projects, id)
+
+	for _, task := range s.tasks {
+		if task.ProjectId != nil && *task.ProjectId == id {
+			task.ProjectId = nil
+		}
+	}
+
+	return nil
+}
+
+// checkTaskProject is the same check as the postgres one, must be called with s.mu held
+func (s *MemoryStore) checkTaskProject(ownerId uint16, taskId, projectId int64) error {
+	project, err := s.ownedProject(ownerId, projectId)
+	if err != nil {
+		return err
+	}
+
+	if project.Archived {
+		task, ok := s.tasks[taskId]
+		if !ok || task.ProjectId == nil || *task.ProjectId != projectId {
+			return ErrProjectArchived
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,326 @@
+package model
+
+import (
+	"context"
+	"log"
+	"math"
+	"sort"
+	"time"
+	"todo/internal/config"
+)
+
+const (
+	ReportDay  = "day"
+	ReportWeek = "week"
+)
+
+// ReportFilter is the date range of the report split into periods, From and To are period
+// boundaries in the location of the report, so days start at its midnight and weeks on its Monday
+type ReportFilter struct {
+	From     time.Time
+	To       time.Time // exclusive
+	Interval string    // ReportDay or ReportWeek
+}
+
+// ReportSummary holds flow metrics of the owner tasks built from the status history
+type ReportSummary struct {
+	From         time.Time       `json:"from"`
+	To           time.Time       `json:"to"`
+	Interval     string          `json:"interval" example:"day"`
+	StatusCounts []*StatusCount  `json:"status_counts"` // current number of tasks in every status of the status table
+	CycleTime    CycleTime       `json:"cycle_time"`
+	Periods      []*ReportPeriod `json:"periods"`
+}
+
+type StatusCount struct {
+	Status string `json:"status"`
+	Count  int    `json:"count"`
+}
+
+// CycleTime is the time from the first move to in_progress to done of tasks completed in the range,
+// tasks done without being in progress are not counted. Percentiles are nearest-rank ones
+type CycleTime struct {
+	Count          int   `json:"count"`
+	AverageSeconds int64 `json:"average_seconds"`
+	P50Seconds     int64 `json:"p50_seconds"`
+	P85Seconds     int64 `json:"p85_seconds"`
+	P95Seconds     int64 `json:"p95_seconds"`
+}
+
+// ReportPeriod is a day or a week of the report
+type ReportPeriod struct {
+	Start     time.Time      `json:"start"`
+	Completed int            `json:"completed"` // tasks moved to done during the period, restores from trash are not counted
+	Flow      map[string]int `json:"flow"`      // tasks in every status at the end of the period, cumulative flow diagram
+}
+
+// ReportStore methods are scoped to the owner the same way TaskStore ones are
+type ReportStore interface {
+	GetReportSummary(ownerId uint16, filter ReportFilter) (*ReportSummary, error)
+}
+
+// statusTransition is a row of the status history of any owner task, from is empty for task creation
+type statusTransition struct {
+	taskId int64
+	from   string
+	to     string
+	at     time.Time
+}
+
+// reportTransitions keeps the part of a task history ordered by time summarize needs, the same one
+// PgStore loads: the last change before the range, the first move to in_progress of a task done
+// in the range and the changes in the range
+func reportTransitions(filter ReportFilter, history []*statusTransition) []*statusTransition {
+	var result []*statusTransition
+
+	last, started := -1, -1
+	doneInRange := false
+	for i, change := range history {
+		if !change.at.Before(filter.To) {
+			break
+		}
+
+		if change.at.Before(filter.From) {
+			last = i
+			if change.to == StatusInProgress && started < 0 {
+				started = i
+			}
+			continue
+		}
+
+		if change.to == StatusDone {
+			doneInRange = true
+		}
+		result = append(result, change)
+	}
+
+	if last >= 0 {
+		result = append([]*statusTransition{history[last]}, result...)
+	}
+	if started >= 0 && started != last && doneInRange {
+		result = append([]*statusTransition{history[started]}, result...)
+	}
+
+	return result
+}
+
+// sortStatusCounts orders counts the same way statuses are, statuses unknown to the model go last
+func sortStatusCounts(counts []*StatusCount) {
+	sort.SliceStable(counts, func(i, j int) bool {
+		ri, rj := statusRank(counts[i].Status), statusRank(counts[j].Status)
+		if ri != rj {
+			return ri < rj
+		}
+		return counts[i].Status < counts[j].Status
+	})
+}
+
+// NextReportPeriod returns start of the period after the one starting at start
+func NextReportPeriod(start time.Time, interval string) time.Time {
+	if interval == ReportWeek {
+		return start.AddDate(0, 0, 7)
+	}
+	return start.AddDate(0, 0, 1)
+}
+
+// summarize builds the report of the status counts and transitions ordered by task and time,
+// flow of the periods not over yet is counted at now
+func summarize(filter ReportFilter, counts []*StatusCount, transitions []*statusTransition, now time.Time) *ReportSummary {
+	sortStatusCounts(counts)
+
+	result := &ReportSummary{
+		From:         filter.From,
+		To:           filter.To,
+		Interval:     filter.Interval,
+		StatusCounts: counts,
+		Periods:      []*ReportPeriod{},
+	}
+
+	var ends []time.Time
+	for start := filter.From; start.Before(filter.To); start = NextReportPeriod(start, filter.Interval) {
+		flow := make(map[string]int, len(counts))
+		for _, count := range counts {
+			flow[count.Status] = 0
+		}
+
+		result.Periods = append(result.Periods, &ReportPeriod{Start: start, Flow: flow})
+
+		end := NextReportPeriod(start, filter.Interval)
+		if end.After(now) {
+			end = now
+		}
+		ends = append(ends, end)
+	}
+
+	periodOf := func(at time.Time) int {
+		i := sort.Search(len(result.Periods), func(i int) bool {
+			return result.Periods[i].Start.After(at)
+		})
+		return i - 1
+	}
+
+	var cycles []time.Duration
+
+	for first := 0; first < len(transitions); {
+		last := first
+		for last < len(transitions) && transitions[last].taskId == transitions[first].taskId {
+			last++
+		}
+		history := transitions[first:last]
+		first = last
+
+		var startedAt *time.Time
+		for _, change := range history {
+			if change.to == StatusInProgress && startedAt == nil {
+				at := change.at
+				startedAt = &at
+			}
+
+			if change.to != StatusDone || change.from == StatusDeleted || change.at.Before(filter.From) || !change.at.Before(filter.To) {
+				continue
+			}
+
+			if i := periodOf(change.at); i >= 0 {
+				result.Periods[i].Completed++
+			}
+			if startedAt != nil {
+				cycles = append(cycles, change.at.Sub(*startedAt))
+			}
+		}
+
+		// status of the task at the end of every period is the one of its last change before it
+		current := -1
+		for i, end := range ends {
+			for current+1 < len(history) && history[current+1].at.Before(end) {
+				current++
+			}
+			if current >= 0 {
+				result.Periods[i].Flow[history[current].to]++
+			}
+		}
+	}
+
+	result.CycleTime = cycleTime(cycles)
+
+	return result
+}
+
+func cycleTime(cycles []time.Duration) CycleTime {
+	result := CycleTime{Count: len(cycles)}
+	if len(cycles) == 0 {
+		return result
+	}
+
+	sort.Slice(cycles, func(i, j int) bool {
+		return cycles[i] < cycles[j]
+	})
+
+	var total time.Duration
+	for _, cycle := range cycles {
+		total += cycle
+	}
+
+	percentile := func(p float64) int64 {
+		i := int(math.Ceil(p/100*float64(len(cycles)))) - 1
+		if i < 0 {
+			i = 0
+		}
+		return int64(cycles[i] / time.Second)
+	}
+
+	result.AverageSeconds = int64(total / time.Duration(len(cycles)) / time.Second)
+	result.P50Seconds = percentile(50)
+	result.P85Seconds = percentile(85)
+	result.P95Seconds = percentile(95)
+
+	return result
+}
+
+func (s *PgStore) GetReportSummary(ownerId uint16, filter ReportFilter) (*ReportSummary, error) {
+	ctx := context.Background()
+
+	rows, err := s.pool.Query(ctx, `
+		SELECT s.name, COUNT(t.id)
+		FROM status s
+		LEFT JOIN task t ON t.status = s.name AND t.owner_id = $1
+		GROUP BY s.name
+	`, ownerId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := []*StatusCount{}
+	for rows.Next() {
+		var item StatusCount
+
+		err = rows.Scan(&item.Status, &item.Count)
+		if err != nil {
+			return nil, err
+		}
+
+		counts = append(counts, &item)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	// history before the range is needed only for the status every task had at its start
+	// and for the start of the cycle of tasks done in the range
+	rows, err = s.pool.Query(ctx, `
+		SELECT task_id, COALESCE(from_status, ''), to_status, changed_at
+		FROM (
+			(
+				SELECT DISTINCT ON (h.task_id) h.id, h.task_id, h.from_status, h.to_status, h.changed_at
+				FROM task_status_history h
+				JOIN task t ON t.id = h.task_id
+				WHERE t.owner_id = $1 AND h.changed_at < $2
+				ORDER BY h.task_id, h.changed_at DESC, h.id DESC
+			)
+			UNION
+			(
+				SELECT DISTINCT ON (h.task_id) h.id, h.task_id, h.from_status, h.to_status, h.changed_at
+				FROM task_status_history h
+				JOIN task t ON t.id = h.task_id
+				WHERE t.owner_id = $1 AND h.changed_at < $2 AND h.to_status = $4 AND EXISTS (
+					SELECT 1 FROM task_status_history d
+					WHERE d.task_id = h.task_id AND d.to_status = $5 AND d.changed_at >= $2 AND d.changed_at < $3
+				)
+				ORDER BY h.task_id, h.changed_at, h.id
+			)
+			UNION
+			SELECT h.id, h.task_id, h.from_status, h.to_status, h.changed_at
+			FROM task_status_history h
+			JOIN task t ON t.id = h.task_id
+			WHERE t.owner_id = $1 AND h.changed_at >= $2 AND h.changed_at < $3
+		) h
+		ORDER BY task_id, changed_at, id
+	`, ownerId, filter.From, filter.To, StatusInProgress, StatusDone)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var transitions []*statusTransition
+	for rows.Next() {
+		var item statusTransition
+
+		err = rows.Scan(&item.taskId, &item.from, &item.to, &item.at)
+		if err != nil {
+			return nil, err
+		}
+
+		transitions = append(transitions, &item)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	if config.DebugLog() {
+		log.Println("report summary: successfully retrieved data from db")
+	}
+
+	return summarize(filter, counts, transitions, time.Now()), nil
+}
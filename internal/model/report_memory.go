@@ -0,0 +1,48 @@
+package model
+
+import (
+	"sort"
+	"time"
+)
+
+func (s *MemoryStore) GetReportSummary(ownerId uint16, filter ReportFilter) (*ReportSummary, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	byStatus := make(map[string]int, len(statuses))
+	var ids []int64
+	for _, task := range s.tasks {
+		if task.OwnerId != ownerId {
+			continue
+		}
+
+		byStatus[task.Status]++
+		ids = append(ids, task.Id)
+	}
+
+	counts := make([]*StatusCount, 0, len(statuses))
+	for _, status := range statuses {
+		counts = append(counts, &StatusCount{Status: status, Count: byStatus[status]})
+	}
+
+	sort.Slice(ids, func(i, j int) bool {
+		return ids[i] < ids[j]
+	})
+
+	var transitions []*statusTransition
+	for _, id := range ids {
+		var history []*statusTransition
+		for _, change := range s.taskHistory[id] {
+			history = append(history, &statusTransition{
+				taskId: id,
+				from:   change.FromStatus,
+				to:     change.ToStatus,
+				at:     change.ChangedAt,
+			})
+		}
+
+		transitions = append(transitions, reportTransitions(filter, history)...)
+	}
+
+	return summarize(filter, counts, transitions, time.Now()), nil
+}
@@ -0,0 +1,93 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSummarize(t *testing.T) {
+	day := func(d, hour int) time.Time {
+		return time.Date(2024, 3, d, hour, 0, 0, 0, time.UTC)
+	}
+	counts := []*StatusCount{
+		{Status: StatusDone, Count: 2}, {Status: StatusDeleted}, {Status: StatusCreated, Count: 1}, {Status: StatusInProgress}, {Status: StatusPaused},
+	}
+	transitions := []*statusTransition{
+		// started before the range, done on the second day
+		{taskId: 1, to: StatusCreated, at: day(1, 9)},
+		{taskId: 1, from: StatusCreated, to: StatusInProgress, at: day(1, 10)},
+		{taskId: 1, from: StatusInProgress, to: StatusPaused, at: day(2, 10)},
+		{taskId: 1, from: StatusPaused, to: StatusDone, at: day(3, 10)},
+		// done without being in progress
+		{taskId: 2, to: StatusCreated, at: day(2, 9)},
+		{taskId: 2, from: StatusCreated, to: StatusDone, at: day(2, 12)},
+		// restored to done is not completed again
+		{taskId: 3, to: StatusCreated, at: day(2, 8)},
+		{taskId: 3, from: StatusCreated, to: StatusInProgress, at: day(2, 9)},
+		{taskId: 3, from: StatusInProgress, to: StatusDone, at: day(2, 21)},
+		{taskId: 3, from: StatusDone, to: StatusDeleted, at: day(3, 8)},
+		{taskId: 3, from: StatusDeleted, to: StatusDone, at: day(3, 9)},
+		// created on the last day
+		{taskId: 4, to: StatusCreated, at: day(4, 9)},
+	}
+
+	result := summarize(ReportFilter{From: day(2, 0), To: day(5, 0), Interval: ReportDay}, counts, transitions, day(4, 12))
+
+	// counts follow the order of statuses
+	assert.Equal(t, StatusCreated, result.StatusCounts[0].Status)
+	assert.Equal(t, StatusDone, result.StatusCounts[3].Status)
+
+	assert.Len(t, result.Periods, 3)
+	assert.Equal(t, day(3, 0), result.Periods[1].Start)
+	assert.Equal(t, 2, result.Periods[0].Completed)
+	assert.Equal(t, 1, result.Periods[1].Completed)
+	assert.Equal(t, 0, result.Periods[2].Completed)
+
+	assert.Equal(t, map[string]int{StatusCreated: 0, StatusInProgress: 0, StatusDone: 2, StatusPaused: 1, StatusDeleted: 0}, result.Periods[0].Flow)
+	assert.Equal(t, 3, result.Periods[1].Flow[StatusDone])
+	assert.Equal(t, 1, result.Periods[2].Flow[StatusCreated]) // counted up to now
+
+	// 12 hours of task 3 and 2 days of task 1
+	assert.Equal(t, 2, result.CycleTime.Count)
+	assert.Equal(t, int64(30*3600), result.CycleTime.AverageSeconds)
+	assert.Equal(t, int64(12*3600), result.CycleTime.P50Seconds)
+	assert.Equal(t, int64(48*3600), result.CycleTime.P95Seconds)
+
+	week := summarize(ReportFilter{From: day(4, 0), To: day(18, 0), Interval: ReportWeek}, counts, transitions, day(20, 0))
+	assert.Len(t, week.Periods, 2)
+	assert.Equal(t, day(11, 0), week.Periods[1].Start)
+	assert.Equal(t, 0, week.CycleTime.Count)
+}
+
+func TestReportTransitions(t *testing.T) {
+	day := func(d, hour int) time.Time {
+		return time.Date(2024, 3, d, hour, 0, 0, 0, time.UTC)
+	}
+	filter := ReportFilter{From: day(2, 0), To: day(4, 0), Interval: ReportDay}
+
+	done := []*statusTransition{
+		{taskId: 1, to: StatusCreated, at: day(1, 8)},
+		{taskId: 1, from: StatusCreated, to: StatusInProgress, at: day(1, 9)},
+		{taskId: 1, from: StatusInProgress, to: StatusPaused, at: day(1, 10)},
+		{taskId: 1, from: StatusPaused, to: StatusInProgress, at: day(1, 11)},
+		{taskId: 1, from: StatusInProgress, to: StatusPaused, at: day(1, 12)},
+		{taskId: 1, from: StatusPaused, to: StatusDone, at: day(2, 10)},
+		{taskId: 1, from: StatusDone, to: StatusDeleted, at: day(5, 10)},
+	}
+	// first start, status at the start of the range and the range
+	assert.Equal(t, []*statusTransition{done[1], done[4], done[5]}, reportTransitions(filter, done))
+
+	paused := []*statusTransition{
+		{taskId: 2, to: StatusCreated, at: day(1, 8)},
+		{taskId: 2, from: StatusCreated, to: StatusInProgress, at: day(1, 9)},
+		{taskId: 2, from: StatusInProgress, to: StatusPaused, at: day(1, 10)},
+	}
+	assert.Equal(t, []*statusTransition{paused[2]}, reportTransitions(filter, paused))
+
+	// report of the kept part is the same as the one of the whole history
+	counts := []*StatusCount{{Status: StatusCreated}, {Status: StatusInProgress}, {Status: StatusPaused}, {Status: StatusDone}, {Status: StatusDeleted}}
+	kept := append(reportTransitions(filter, done), reportTransitions(filter, paused)...)
+	assert.Equal(t, summarize(filter, counts, append(done, paused...), day(6, 0)), summarize(filter, counts, kept, day(6, 0)))
+}
@@ -0,0 +1,73 @@
+package model
+
+import "fmt"
+
+// statuses in the same order as they are seeded in status table
+var statuses = []string{
+	StatusCreated,
+	StatusInProgress,
+	StatusPaused,
+	StatusDone,
+	StatusDeleted,
+}
+
+// StatusTransitions is the task status graph, key is current status and value is
+// the list of statuses task is allowed to move to. Unfinished tasks move between the board columns freely,
+// done is final since completing a recurring task creates its next occurrence. Trash is left only by RestoreTask,
+// which takes care of the descendants deleted with the task, so deleted has no transitions
+var StatusTransitions = map[string][]string{
+	StatusCreated:    {StatusInProgress, StatusPaused, StatusDone, StatusDeleted},
+	StatusInProgress: {StatusCreated, StatusPaused, StatusDone, StatusDeleted},
+	StatusPaused:     {StatusCreated, StatusInProgress, StatusDone, StatusDeleted},
+	StatusDone:       {StatusDeleted},
+	StatusDeleted:    {},
+}
+
+func IsStatus(status string) bool {
+	for _, s := range statuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+func CanChangeStatus(from, to string) bool {
+	for _, s := range StatusTransitions[from] {
+		if s == to {
+			return true
+		}
+	}
+	return false
+}
+
+// statusRank returns index of the status in statuses, statuses unknown to the model go last
+func statusRank(status string) int {
+	for i, s := range statuses {
+		if s == status {
+			return i
+		}
+	}
+	return len(statuses)
+}
+
+// statusSources returns statuses from which task can be moved to the given status, never deleted
+func statusSources(to string) []string {
+	var result []string
+	for _, from := range statuses {
+		if from != StatusDeleted && CanChangeStatus(from, to) {
+			result = append(result, from)
+		}
+	}
+	return result
+}
+
+// StatusTransitionError is returned when requested status change is not allowed by StatusTransitions
+type StatusTransitionError struct {
+	Current   string
+	Requested string
+}
+
+func (e *StatusTransitionError) Error() string {
+	return fmt.Sprintf("task status can not be changed from %s to %s", e.Current, e.Requested)
+}
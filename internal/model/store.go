@@ -0,0 +1,115 @@
+package model
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+// Store is everything the controller needs from the persistence layer
+type Store interface {
+	TaskStore
+	TaskReminderStore
+	LabelStore
+	ProjectStore
+	TaskDependencyStore
+	TaskCommentStore
+	TaskAttachmentStore
+	TaskChecklistStore
+	TaskAssigneeStore
+	TaskTimeStore
+	ReportStore
+	UserStore
+	WorkspaceStore
+	TokenStore
+}
+
+// PgStore is postgres implementation of Store
+type PgStore struct {
+	pool *pgxpool.Pool
+}
+
+// querier is satisfied by both pool and transaction
+type querier interface {
+	Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row
+}
+
+func NewPgStore(pool *pgxpool.Pool) *PgStore {
+	return &PgStore{pool: pool}
+}
+
+// MemoryStore is thread-safe in-memory implementation of Store,
+// used in tests and when there is no database at hand
+type MemoryStore struct {
+	mu sync.RWMutex
+
+	tasks       map[int64]*Task
+	lastTaskId  int64
+	taskHistory map[int64][]*TaskStatusChange
+
+	labels      map[int64]*memoryLabel
+	lastLabelId int64
+	taskLabels  map[int64]map[int64]bool // task id to set of label ids
+
+	projects      map[int64]*memoryProject
+	lastProjectId int64
+
+	taskBlockers map[int64]map[int64]bool // task id to set of blocker ids
+
+	comments      map[int64]*TaskComment
+	lastCommentId int64
+
+	attachments      map[int64]*TaskAttachment
+	lastAttachmentId int64
+
+	checklistItems      map[int64]*ChecklistItem
+	lastChecklistItemId int64
+
+	taskAssignees map[int64]map[uint16]bool // task id to set of user ids
+
+	timeEntries     map[int64]*TimeEntry
+	lastTimeEntryId int64
+
+	users      map[uint16]*User
+	lastUserId uint16
+
+	workspaceMembers map[uint16]map[uint16]*WorkspaceMember // workspace id to members by user id
+
+	refreshTokens map[string]memoryRefreshToken
+	revokedTokens map[string]time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		tasks:       map[int64]*Task{},
+		taskHistory: map[int64][]*TaskStatusChange{},
+		labels:      map[int64]*memoryLabel{},
+		taskLabels:  map[int64]map[int64]bool{},
+		users:       map[uint16]*User{},
+
+		taskBlockers: map[int64]map[int64]bool{},
+		comments:     map[int64]*TaskComment{},
+		attachments:  map[int64]*TaskAttachment{},
+
+		checklistItems: map[int64]*ChecklistItem{},
+		projects:       map[int64]*memoryProject{},
+
+		workspaceMembers: map[uint16]map[uint16]*WorkspaceMember{},
+		taskAssignees:    map[int64]map[uint16]bool{},
+		timeEntries:      map[int64]*TimeEntry{},
+
+		refreshTokens: map[string]memoryRefreshToken{},
+		revokedTokens: map[string]time.Time{},
+	}
+}
+
+var (
+	_ Store = (*PgStore)(nil)
+	_ Store = (*MemoryStore)(nil)
+)
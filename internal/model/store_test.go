@@ -0,0 +1,212 @@
+package model
+
+import (
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"todo/pkg/db"
+)
+
+// forEachStore runs the test against MemoryStore and, when TEST_DATABASE_URL is set, against PgStore.
+// Every run gets a new owner, so the database may keep the rows of previous runs
+func forEachStore(t *testing.T, test func(t *testing.T, store Store, ownerId uint16)) {
+	t.Run("memory", func(t *testing.T) {
+		store := NewMemoryStore()
+		ownerId, err := store.CreateUser("owner", "hash")
+		require.NoError(t, err)
+		test(t, store, ownerId)
+	})
+
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		return
+	}
+
+	t.Run("pg", func(t *testing.T) {
+		pool, err := db.Connect(url)
+		require.NoError(t, err)
+		defer pool.Close()
+
+		_, err = db.MigrateUp(pool)
+		require.NoError(t, err)
+
+		store := NewPgStore(pool)
+		ownerId, err := store.CreateUser(fmt.Sprintf("owner-%d", time.Now().UnixNano()), "hash")
+		require.NoError(t, err)
+		test(t, store, ownerId)
+	})
+}
+
+func createTask(t *testing.T, store Store, ownerId uint16, name string, parentId *int64) *Task {
+	task, err := store.CreateTask(ownerId, ownerId, TaskFields{Name: name, Priority: PriorityNormal, ParentId: parentId})
+	require.NoError(t, err)
+	return task
+}
+
+func taskStatus(t *testing.T, store Store, ownerId uint16, id int64) string {
+	task, err := store.GetTask(ownerId, id)
+	require.NoError(t, err)
+	return task.Status
+}
+
+func TestStoreStatusGuard(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store, ownerId uint16) {
+		task := createTask(t, store, ownerId, "guarded", nil)
+
+		require.NoError(t, store.StartTaskProgress(ownerId, task.Id, ownerId))
+		require.NoError(t, store.DoneTask(ownerId, task.Id, ownerId))
+
+		err := store.PauseTask(ownerId, task.Id, ownerId)
+		assert.Equal(t, &StatusTransitionError{Current: StatusDone, Requested: StatusPaused}, err)
+
+		// only restore takes a task out of trash
+		require.NoError(t, store.DeleteTask(ownerId, task.Id, ownerId))
+		for _, change := range []func(uint16, int64, uint16) error{store.DoneTask, store.StartTaskProgress, store.PauseTask} {
+			var transition *StatusTransitionError
+			assert.ErrorAs(t, change(ownerId, task.Id, ownerId), &transition)
+			assert.Equal(t, StatusDeleted, taskStatus(t, store, ownerId, task.Id))
+		}
+		require.NoError(t, store.RestoreTask(ownerId, task.Id, ownerId))
+		assert.Equal(t, StatusDone, taskStatus(t, store, ownerId, task.Id))
+
+		history, err := store.GetTaskStatusHistory(ownerId, task.Id)
+		require.NoError(t, err)
+		var changes []string
+		for _, change := range history {
+			changes = append(changes, change.FromStatus+">"+change.ToStatus)
+		}
+		assert.Equal(t, []string{">created", "created>in_progress", "in_progress>done", "done>deleted", "deleted>done"}, changes)
+	})
+}
+
+func TestStoreTaskListCursor(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store, ownerId uint16) {
+		for _, name := range []string{"charlie", "alpha", "echo", "bravo", "delta"} {
+			createTask(t, store, ownerId, name, nil)
+		}
+
+		var pages [][]string
+		filter := TaskListFilter{Sort: "name", Desc: true, Limit: 2}
+		for {
+			page, err := store.GetTaskList(ownerId, filter)
+			require.NoError(t, err)
+			assert.Equal(t, 5, page.Total)
+
+			var names []string
+			for _, task := range page.Tasks {
+				names = append(names, task.Name)
+			}
+			pages = append(pages, names)
+
+			if page.Next == nil {
+				break
+			}
+			require.Less(t, len(pages), 4)
+			filter.After = page.Next
+		}
+
+		assert.Equal(t, [][]string{{"echo", "delta"}, {"charlie", "bravo"}, {"alpha"}}, pages)
+	})
+}
+
+func TestStoreDeletedWith(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store, ownerId uint16) {
+		parent := createTask(t, store, ownerId, "parent", nil)
+		child := createTask(t, store, ownerId, "child", &parent.Id)
+		grandchild := createTask(t, store, ownerId, "grandchild", &child.Id)
+		trashed := createTask(t, store, ownerId, "trashed before", &parent.Id)
+
+		require.NoError(t, store.StartTaskProgress(ownerId, child.Id, ownerId))
+		require.NoError(t, store.DeleteTask(ownerId, trashed.Id, ownerId))
+		require.NoError(t, store.DeleteTask(ownerId, parent.Id, ownerId))
+
+		for _, id := range []int64{parent.Id, child.Id, grandchild.Id, trashed.Id} {
+			assert.Equal(t, StatusDeleted, taskStatus(t, store, ownerId, id))
+		}
+
+		assert.Equal(t, ErrTaskParentDeleted, store.RestoreTask(ownerId, child.Id, ownerId))
+
+		// restore brings back the tasks deleted with the parent, each one to its own status
+		require.NoError(t, store.RestoreTask(ownerId, parent.Id, ownerId))
+		assert.Equal(t, StatusCreated, taskStatus(t, store, ownerId, parent.Id))
+		assert.Equal(t, StatusInProgress, taskStatus(t, store, ownerId, child.Id))
+		assert.Equal(t, StatusCreated, taskStatus(t, store, ownerId, grandchild.Id))
+		assert.Equal(t, StatusDeleted, taskStatus(t, store, ownerId, trashed.Id))
+	})
+}
+
+func TestStoreFreeProjectTrash(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store, ownerId uint16) {
+		project, err := store.CreateProject(ownerId, ProjectFields{Name: "docs"})
+		require.NoError(t, err)
+
+		parent, err := store.CreateTask(ownerId, ownerId, TaskFields{Name: "parent", Priority: PriorityNormal, ProjectId: &project.Id})
+		require.NoError(t, err)
+		createTask(t, store, ownerId, "outside", &parent.Id)
+		require.NoError(t, store.DeleteTask(ownerId, parent.Id, ownerId))
+
+		_, err = store.FreeTaskTrash(ownerId, &project.Id)
+		assert.Equal(t, ErrTrashSubtaskOutsideProject, err)
+		assert.Equal(t, StatusDeleted, taskStatus(t, store, ownerId, parent.Id))
+
+		_, err = store.FreeTaskTrash(ownerId, nil)
+		require.NoError(t, err)
+		_, err = store.GetTask(ownerId, parent.Id)
+		assert.Equal(t, ErrTaskNotFound, err)
+	})
+}
+
+func TestStoreReportSummary(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store, ownerId uint16) {
+		started := createTask(t, store, ownerId, "started", nil)
+		require.NoError(t, store.StartTaskProgress(ownerId, started.Id, ownerId))
+		require.NoError(t, store.DoneTask(ownerId, started.Id, ownerId))
+
+		// done without being in progress has no cycle time
+		skipped := createTask(t, store, ownerId, "skipped", nil)
+		require.NoError(t, store.DoneTask(ownerId, skipped.Id, ownerId))
+
+		createTask(t, store, ownerId, "open", nil)
+
+		today := time.Now().UTC().Truncate(24 * time.Hour)
+		summary, err := store.GetReportSummary(ownerId, ReportFilter{From: today, To: today.AddDate(0, 0, 1), Interval: ReportDay})
+		require.NoError(t, err)
+		require.Len(t, summary.Periods, 1)
+		assert.Equal(t, 2, summary.Periods[0].Completed)
+		assert.Equal(t, 1, summary.CycleTime.Count)
+		assert.Equal(t, 2, summary.Periods[0].Flow[StatusDone])
+		assert.Equal(t, 1, summary.Periods[0].Flow[StatusCreated])
+
+		// before the tasks were created there is nothing to report
+		summary, err = store.GetReportSummary(ownerId, ReportFilter{From: today.AddDate(0, 0, -2), To: today.AddDate(0, 0, -1), Interval: ReportDay})
+		require.NoError(t, err)
+		require.Len(t, summary.Periods, 1)
+		assert.Zero(t, summary.Periods[0].Completed)
+		assert.Zero(t, summary.CycleTime.Count)
+		assert.Zero(t, summary.Periods[0].Flow[StatusCreated])
+	})
+}
+
+func TestStoreDependencyCycle(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store, ownerId uint16) {
+		a := createTask(t, store, ownerId, "a", nil)
+		b := createTask(t, store, ownerId, "b", nil)
+		c := createTask(t, store, ownerId, "c", nil)
+
+		assert.Equal(t, ErrTaskDependencyCycle, store.AddTaskBlocker(ownerId, a.Id, a.Id))
+
+		require.NoError(t, store.AddTaskBlocker(ownerId, a.Id, b.Id))
+		assert.Equal(t, ErrTaskDependencyCycle, store.AddTaskBlocker(ownerId, b.Id, a.Id))
+
+		require.NoError(t, store.AddTaskBlocker(ownerId, b.Id, c.Id))
+		assert.Equal(t, ErrTaskDependencyCycle, store.AddTaskBlocker(ownerId, c.Id, a.Id))
+
+		// a shared blocker is not a cycle
+		require.NoError(t, store.AddTaskBlocker(ownerId, a.Id, c.Id))
+	})
+}
@@ -3,16 +3,87 @@ package model
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"log"
+	"strconv"
+	"strings"
+	"time"
 	"todo/internal/config"
-	"todo/pkg/db"
+
+	"github.com/jackc/pgx/v4"
 )
 
 type Task struct {
-	Id          uint16 `json:"id"`
+	Id          int64  `json:"id"`
+	PublicId    string `json:"public_id" example:"0b7e7dee-87b0-4b8e-9f1a-2f5c2c7c3e1a"`
+	OwnerId     uint16 `json:"owner_id"`
 	Name        string `json:"name" example:"New Task"`
 	Status      string `json:"status"`
 	Description string `json:"description" example:"Lorum ipsum"`
+	Priority    string `json:"priority" example:"normal"`
+	Position    string `json:"position" example:"V"` // orders tasks of the same status, see MoveTask
+
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	StartedAt   *time.Time `json:"started_at"`   // first time task went in progress
+	CompletedAt *time.Time `json:"completed_at"` // last time task was done
+	DeletedAt   *time.Time `json:"deleted_at"`   // set while task is in trash
+
+	DueAt       *time.Time `json:"due_at"`                                    // in DueTimezone if it is set
+	DueTimezone string     `json:"due_timezone" example:"Europe/Amsterdam"`   // IANA name, empty means UTC
+	Recurrence  string     `json:"recurrence" example:"FREQ=WEEKLY;BYDAY=MO"` // RRULE subset, see package recurrence
+
+	EstimateMinutes *int32 `json:"estimate_minutes" example:"90"` // original estimate, compared with tracked time
+
+	Labels    []*Label `json:"labels"`
+	Assignees []uint16 `json:"assignees"` // user ids
+
+	ProjectId *int64 `json:"project_id"`
+
+	ParentId *int64       `json:"parent_id"`
+	Progress TaskProgress `json:"progress"`           // of direct children
+	Children []*Task      `json:"children,omitempty"` // only in tree of task list
+
+	Blocked    bool       `json:"blocked"`              // some blocker is not done yet
+	Blockers   []*TaskRef `json:"blockers,omitempty"`   // only in single task
+	Dependents []*TaskRef `json:"dependents,omitempty"` // only in single task, tasks blocked by this one
+
+	CommentCount int `json:"comment_count"`
+
+	Checklist ChecklistProgress `json:"checklist"`
+
+	statusBeforeDelete string     // used by MemoryStore only
+	remindedAt         *time.Time // used by MemoryStore only
+	deletedWith        int64      // used by MemoryStore only
+}
+
+// TaskFields are the user editable fields of a task
+type TaskFields struct {
+	Name        string
+	Description string
+	Priority    string // one of priorities
+	DueAt       *time.Time
+	DueTimezone string
+	Recurrence  string // needs DueAt, it is the first occurrence
+	ParentId    *int64
+	ProjectId   *int64
+	// EstimateMinutes is nil without estimate
+	EstimateMinutes *int32
+}
+
+// localizeDue converts due date into its timezone, database returns it in the session one
+func (t *Task) localizeDue() {
+	if t.DueAt == nil || t.DueTimezone == "" {
+		return
+	}
+
+	loc, err := time.LoadLocation(t.DueTimezone)
+	if err != nil {
+		return
+	}
+
+	due := t.DueAt.In(loc)
+	t.DueAt = &due
 }
 
 const (
@@ -27,72 +98,90 @@ type Status struct {
 	Name string `json:"name"`
 }
 
-func GetTaskList(status string) ([]*Task, error) {
-	conn, err := db.ConnectionPool()
-	if err != nil {
-		return nil, err
-	}
+// TaskStatusChange is a row of task status history, FromStatus is empty for task creation
+type TaskStatusChange struct {
+	FromStatus string    `json:"from_status"`
+	ToStatus   string    `json:"to_status"`
+	ChangedBy  uint16    `json:"changed_by"`
+	ChangedAt  time.Time `json:"changed_at"`
+}
 
-	var result []*Task = []*Task{}
+// TaskEditFunc merges an edit into the fields of the task, EditTask calls it while the task is locked,
+// so it must not call the store
+type TaskEditFunc func(task *Task) (TaskFields, error)
+
+// ErrTaskNotFound is returned when task does not exist or belongs to another user
+var ErrTaskNotFound = errors.New("task not found")
+
+// TaskStore methods are scoped to the owner, tasks of other users are never visible.
+// actorId is the user making the status change, the owner or a member of the owner workspace,
+// it goes to the status history
+type TaskStore interface {
+	GetTaskList(ownerId uint16, filter TaskListFilter) (*TaskPage, error)
+	GetStatusList() ([]*Status, error)
+	CreateTask(ownerId, actorId uint16, fields TaskFields) (*Task, error)
+	GetTaskIdByPublicId(ownerId uint16, publicId string) (int64, error)
+	GetTask(ownerId uint16, id int64) (*Task, error)
+	// EditTask saves the fields edit returns for the locked task, concurrent edits of different fields
+	// are merged one after another instead of overwriting each other
+	EditTask(ownerId uint16, id int64, edit TaskEditFunc) error
+	MoveTask(ownerId uint16, id int64, actorId uint16, move TaskMove) (*Task, error)
+	StartTaskProgress(ownerId uint16, id int64, actorId uint16) error
+	PauseTask(ownerId uint16, id int64, actorId uint16) error
+	DoneTask(ownerId uint16, id int64, actorId uint16) error
+	DeleteTask(ownerId uint16, id int64, actorId uint16) error
+	RestoreTask(ownerId uint16, id int64, actorId uint16) error
+	// DeleteTaskCompletely and FreeTaskTrash return blob keys of attachments of the deleted tasks
+	DeleteTaskCompletely(ownerId uint16, id int64) ([]string, error)
+	// FreeTaskTrash deletes only tasks of the project unless projectId is nil
+	FreeTaskTrash(ownerId uint16, projectId *int64) ([]string, error)
+	GetTaskStatusHistory(ownerId uint16, id int64) ([]*TaskStatusChange, error)
+	GetTaskChildren(ownerId uint16, id int64) ([]*Task, error)
+}
 
-	sqlQuery := `
-		SELECT id, name, description, status
-		FROM task
-	`
+const taskColumns = "id, public_id, owner_id, name, description, status, priority, position, created_at, updated_at, started_at, completed_at, deleted_at, due_at, due_timezone, parent_id, recurrence, project_id, estimate_minutes"
 
-	if len(status) > 0 {
-		sqlQuery += "WHERE status = $1"
-	} else {
-		sqlQuery += "WHERE status <> $1" // by default show all and ignore deleted
-		status = StatusDeleted
+// prefixedTaskColumns returns taskColumns of the table alias
+func prefixedTaskColumns(alias string) string {
+	columns := strings.Split(taskColumns, ", ")
+	for i, column := range columns {
+		columns[i] = alias + "." + column
 	}
+	return strings.Join(columns, ", ")
+}
 
-	sqlQuery += " ORDER BY id ASC"
+// scanTask reads row selected by taskColumns
+func scanTask(row pgx.Row) (*Task, error) {
+	item := Task{Labels: []*Label{}, Assignees: []uint16{}}
+	var description sql.NullString
+	var priority int16
 
-	rows, err := conn.Query(context.Background(), sqlQuery, status)
+	err := row.Scan(
+		&item.Id, &item.PublicId, &item.OwnerId, &item.Name, &description, &item.Status, &priority, &item.Position,
+		&item.CreatedAt, &item.UpdatedAt, &item.StartedAt, &item.CompletedAt, &item.DeletedAt,
+		&item.DueAt, &item.DueTimezone, &item.ParentId, &item.Recurrence, &item.ProjectId,
+		&item.EstimateMinutes,
+	)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
-
-	for rows.Next() {
-		var item Task
-		var description sql.NullString
-
-		err = rows.Scan(&item.Id, &item.Name, &description, &item.Status)
-		if err != nil {
-			return nil, err
-		}
 
-		if description.Valid {
-			if len(description.String) > 0 {
-				item.Description = description.String
-			}
+	if description.Valid {
+		if len(description.String) > 0 {
+			item.Description = description.String
 		}
-
-		result = append(result, &item)
-	}
-	err = rows.Err()
-	if err != nil {
-		return nil, err
 	}
 
-	if config.DebugLog() {
-		log.Println("task list offset: successfully retrived data from db")
-	}
+	item.Priority = priorityName(priority)
+	item.localizeDue()
 
-	return result, nil
+	return &item, nil
 }
 
-func GetStatusList() ([]*Status, error) {
-	conn, err := db.ConnectionPool()
-	if err != nil {
-		return nil, err
-	}
-
+func (s *PgStore) GetStatusList() ([]*Status, error) {
 	var result []*Status = []*Status{}
 
-	rows, err := conn.Query(context.Background(), `
+	rows, err := s.pool.Query(context.Background(), `
         SELECT name
         FROM status
     `)
@@ -124,83 +213,213 @@ func GetStatusList() ([]*Status, error) {
 
 }
 
-func CreateTask(name, description string) (uint16, error) {
-	var id uint16
+func (s *PgStore) CreateTask(ownerId, actorId uint16, fields TaskFields) (*Task, error) {
+	ctx := context.Background()
 
-	conn, err := db.ConnectionPool()
+	tx, err := s.pool.Begin(ctx)
 	if err != nil {
-		return id, err
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	if fields.ParentId != nil {
+		err = checkTaskParent(ctx, tx, ownerId, 0, *fields.ParentId)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if fields.ProjectId != nil {
+		err = checkTaskProject(ctx, tx, ownerId, 0, *fields.ProjectId)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	position, err := lastTaskPosition(ctx, tx, ownerId, StatusCreated, 0)
+	if err != nil {
+		return nil, err
 	}
 
-	err = conn.QueryRow(context.Background(), `
-		INSERT INTO task(name, description, status)
-		VALUES ($1, $2, $3) RETURNING id`,
-		name,
-		description,
+	position, err = positionBetween(position, "")
+	if err != nil {
+		return nil, err
+	}
+
+	item, err := scanTask(tx.QueryRow(ctx, `
+		INSERT INTO task(owner_id, name, description, status, priority, position, due_at, due_timezone, parent_id, recurrence, project_id, estimate_minutes)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12) RETURNING `+taskColumns,
+		ownerId,
+		fields.Name,
+		fields.Description,
 		StatusCreated,
-	).Scan(&id)
+		priorityRank(fields.Priority),
+		position,
+		fields.DueAt,
+		fields.DueTimezone,
+		fields.ParentId,
+		fields.Recurrence,
+		fields.ProjectId,
+		fields.EstimateMinutes,
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO task_status_history(task_id, from_status, to_status, changed_by, changed_at)
+		VALUES ($1, NULL, $2, $3, $4)`,
+		item.Id,
+		item.Status,
+		actorId,
+		item.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
 
+	err = tx.Commit(ctx)
 	if err != nil {
-		return id, err
+		return nil, err
 	}
 
 	if config.DebugLog() {
 		log.Println("task create: successfully created data in db")
 	}
 
-	return id, nil
+	return item, nil
 }
 
-func GetTask(id uint16) (*Task, error) {
-	conn, err := db.ConnectionPool()
+func (s *PgStore) GetTaskIdByPublicId(ownerId uint16, publicId string) (int64, error) {
+	var id int64
+
+	err := s.pool.QueryRow(context.Background(), `
+		SELECT id
+		FROM task
+		WHERE public_id = $1 AND owner_id = $2
+	`, publicId, ownerId).Scan(&id)
 	if err != nil {
-		return nil, err
+		if errors.Is(err, pgx.ErrNoRows) {
+			return id, ErrTaskNotFound
+		}
+		return id, err
 	}
 
-	var item Task
-	var description sql.NullString
+	return id, nil
+}
+
+func (s *PgStore) GetTask(ownerId uint16, id int64) (*Task, error) {
+	ctx := context.Background()
 
-	err = conn.QueryRow(context.Background(), `
-		SELECT id, name, description, status
+	item, err := scanTask(s.pool.QueryRow(ctx, `
+		SELECT `+taskColumns+`
 		FROM task
-		WHERE id = $1
-	`, id).Scan(&item.Id, &item.Name, &description, &item.Status)
+		WHERE id = $1 AND owner_id = $2
+	`, id, ownerId))
 	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrTaskNotFound
+		}
 		return nil, err
 	}
 
-	if description.Valid {
-		if len(description.String) > 0 {
-			item.Description = description.String
-		}
+	err = s.loadTaskDetails(ctx, []*Task{item})
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.loadTaskDependencies(ctx, item)
+	if err != nil {
+		return nil, err
 	}
 
 	if config.DebugLog() {
 		log.Println("get task: successfully retrieved data in db")
 	}
 
-	return &item, nil
+	return item, nil
 }
 
-func EditTask(id uint16, name, description string) error {
-	conn, err := db.ConnectionPool()
+func (s *PgStore) EditTask(ownerId uint16, id int64, edit TaskEditFunc) error {
+	ctx := context.Background()
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	task, err := scanTask(tx.QueryRow(ctx, `
+		SELECT `+taskColumns+`
+		FROM task
+		WHERE id = $1 AND owner_id = $2
+		FOR UPDATE
+	`, id, ownerId))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrTaskNotFound
+		}
+		return err
+	}
+
+	fields, err := edit(task)
 	if err != nil {
 		return err
 	}
 
-	_, err = conn.Exec(context.Background(), `
+	if fields.ParentId != nil {
+		err = checkTaskParent(ctx, tx, ownerId, id, *fields.ParentId)
+		if err != nil {
+			return err
+		}
+	}
+
+	if fields.ProjectId != nil {
+		err = checkTaskProject(ctx, tx, ownerId, id, *fields.ProjectId)
+		if err != nil {
+			return err
+		}
+	}
+
+	tag, err := tx.Exec(ctx, `
 		UPDATE task
 		SET name = $1,
-			description = $2
-		WHERE id = $3`,
-		name,
-		description,
+			description = $2,
+			reminded_at = CASE WHEN due_at IS DISTINCT FROM $3 THEN NULL ELSE reminded_at END,
+			due_at = $3,
+			due_timezone = $4,
+			priority = $5,
+			parent_id = $6,
+			recurrence = $7,
+			project_id = $8,
+			estimate_minutes = $9,
+			updated_at = now()
+		WHERE id = $10 AND owner_id = $11`,
+		fields.Name,
+		fields.Description,
+		fields.DueAt,
+		fields.DueTimezone,
+		priorityRank(fields.Priority),
+		fields.ParentId,
+		fields.Recurrence,
+		fields.ProjectId,
+		fields.EstimateMinutes,
 		id,
+		ownerId,
 	)
 	if err != nil {
 		return err
 	}
 
+	if tag.RowsAffected() == 0 {
+		return ErrTaskNotFound
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return err
+	}
+
 	if config.DebugLog() {
 		log.Println("task edit: successfully edited data in db")
 	}
@@ -208,145 +427,551 @@ func EditTask(id uint16, name, description string) error {
 	return nil
 }
 
-func StartTaskProgress(id uint16) error {
-	conn, err := db.ConnectionPool()
+// sqlPlaceholders returns "$start, $start+1, ..." list of n placeholders
+func sqlPlaceholders(start, n int) string {
+	placeholders := make([]string, n)
+	for i := range placeholders {
+		placeholders[i] = "$" + strconv.Itoa(start+i)
+	}
+	return strings.Join(placeholders, ", ")
+}
+
+// updateTaskStatus moves task to the status only if StatusTransitions allows it,
+// the check is done by the same UPDATE statement, so concurrent changes can not bypass it
+func updateTaskStatus(ctx context.Context, q querier, ownerId uint16, id int64, actorId uint16, status string) error {
+	condition := "true"
+	if status == StatusInProgress {
+		condition = "NOT " + taskBlockedCondition("task.id")
+	}
+
+	// the task goes to the end of its new column
+	position, err := lastTaskPosition(ctx, q, ownerId, status, id)
 	if err != nil {
 		return err
 	}
 
-	_, err = conn.Exec(context.Background(), `
-		UPDATE task
-		SET status = $1
-		WHERE id = $2`,
-		StatusInProgress,
-		id,
+	position, err = positionBetween(position, "")
+	if err != nil {
+		return err
+	}
+
+	changed, err := changeTaskStatus(ctx, q, ownerId, id, actorId, status, position, "status = $1, position = $5"+statusTimestampsSet(status), condition, statusSources(status))
+	if err != nil {
+		return err
+	}
+	if changed {
+		return trackTaskTime(ctx, q, id, actorId, status)
+	}
+
+	err = statusTransitionError(ctx, q, ownerId, id, status)
+
+	var transitionErr *StatusTransitionError
+	if errors.As(err, &transitionErr) && CanChangeStatus(transitionErr.Current, status) {
+		// the transition is fine, so it is the condition which did not hold
+		blockers, err := unfinishedBlockers(ctx, q, id)
+		if err != nil {
+			return err
+		}
+		if len(blockers) > 0 {
+			return &TaskBlockedError{Blockers: blockers}
+		}
+	}
+
+	return err
+}
+
+// statusTimestampsSet returns assignments of task timestamps changed by moving task to the status
+func statusTimestampsSet(status string) string {
+	switch status {
+	case StatusInProgress:
+		return ", started_at = COALESCE(task.started_at, now())"
+	case StatusDone:
+		return ", completed_at = now()"
+	}
+	return ""
+}
+
+// changeTaskStatus updates task by set clause if its status is one of sources and writes
+// the change into task_status_history, both in one statement. $1 is requested status and $5 is position,
+// condition is an extra one on the task row. It reports false if no row was changed
+func changeTaskStatus(ctx context.Context, q querier, ownerId uint16, id int64, actorId uint16, requested, position, set, condition string, sources []string) (bool, error) {
+	args := []interface{}{requested, id, ownerId, actorId, position}
+	for _, source := range sources {
+		args = append(args, source)
+	}
+
+	tag, err := q.Exec(ctx, `
+		WITH old AS (
+			SELECT id, status
+			FROM task
+			WHERE id = $2 AND owner_id = $3
+			FOR UPDATE
+		), updated AS (
+			UPDATE task
+			SET `+set+`, updated_at = now()
+			FROM old
+			WHERE task.id = old.id AND task.status IN (`+sqlPlaceholders(6, len(sources))+`) AND `+condition+`
+			RETURNING task.id, old.status AS from_status, task.status AS to_status
+		)
+		INSERT INTO task_status_history(task_id, from_status, to_status, changed_by)
+		SELECT id, from_status, to_status, $4::integer
+		FROM updated`,
+		args...,
 	)
+	if err != nil {
+		return false, err
+	}
+
+	return tag.RowsAffected() != 0, nil
+}
+
+// statusTransitionError explains why status update did not affect any row
+func statusTransitionError(ctx context.Context, q querier, ownerId uint16, id int64, requested string) error {
+	var current, beforeDelete string
+
+	err := q.QueryRow(ctx, `
+		SELECT status, COALESCE(status_before_delete, $3)
+		FROM task
+		WHERE id = $1 AND owner_id = $2
+	`, id, ownerId, StatusCreated).Scan(&current, &beforeDelete)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrTaskNotFound
+		}
+		return err
+	}
+
+	if requested == "" { // restore
+		requested = beforeDelete
+	}
+
+	return &StatusTransitionError{
+		Current:   current,
+		Requested: requested,
+	}
+}
+
+// StartTaskProgress assigns the actor to the task without assignees in the same transaction with config.AutoAssign
+func (s *PgStore) StartTaskProgress(ownerId uint16, id int64, actorId uint16) error {
+	ctx := context.Background()
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	err = updateTaskStatus(ctx, tx, ownerId, id, actorId, StatusInProgress)
+	if err != nil {
+		return err
+	}
+
+	err = autoAssign(ctx, tx, id, actorId)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return err
+	}
 
 	if config.DebugLog() {
 		log.Println("task start progress: successfully changed status in db")
 	}
 
-	return err
+	return nil
 }
 
-func PauseTask(id uint16) error {
-	conn, err := db.ConnectionPool()
+// PauseTask closes the running time entry in the same transaction
+func (s *PgStore) PauseTask(ownerId uint16, id int64, actorId uint16) error {
+	ctx := context.Background()
+
+	tx, err := s.pool.Begin(ctx)
 	if err != nil {
 		return err
 	}
+	defer tx.Rollback(ctx)
 
-	_, err = conn.Exec(context.Background(), `
-		UPDATE task
-		SET status = $1
-		WHERE id = $2`,
-		StatusPaused,
-		id,
-	)
+	err = updateTaskStatus(ctx, tx, ownerId, id, actorId, StatusPaused)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return err
+	}
 
 	if config.DebugLog() {
 		log.Println("task pause: successfully changed status in db")
 	}
 
-	return err
+	return nil
 }
 
-func DoneTask(id uint16) error {
-	conn, err := db.ConnectionPool()
+// DoneTask creates the next occurrence of a recurring task in the same transaction,
+// with config.StrictChecklist the change is rolled back if the checklist is not complete
+func (s *PgStore) DoneTask(ownerId uint16, id int64, actorId uint16) error {
+	ctx := context.Background()
+
+	tx, err := s.pool.Begin(ctx)
 	if err != nil {
 		return err
 	}
+	defer tx.Rollback(ctx)
 
-	_, err = conn.Exec(context.Background(), `
-		UPDATE task
-		SET status = $1
-		WHERE id = $2`,
-		StatusDone,
-		id,
-	)
+	err = updateTaskStatus(ctx, tx, ownerId, id, actorId, StatusDone)
+	if err != nil {
+		return err
+	}
+
+	err = checklistComplete(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+
+	err = createNextOccurrence(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return err
+	}
 
 	if config.DebugLog() {
 		log.Println("task done: successfully changed status in db")
 	}
 
-	return err
+	return nil
 }
 
-func DeleteTask(id uint16) error {
-	conn, err := db.ConnectionPool()
+// DeleteTask moves the task with its not deleted descendants to trash and closes their running time entries
+func (s *PgStore) DeleteTask(ownerId uint16, id int64, actorId uint16) error {
+	ctx := context.Background()
+
+	tx, err := s.pool.Begin(ctx)
 	if err != nil {
 		return err
 	}
+	defer tx.Rollback(ctx)
 
-	_, err = conn.Exec(context.Background(), `
-		UPDATE task
-		SET status = $1
-		WHERE id = $2`,
-		StatusDeleted,
+	var current string
+	err = tx.QueryRow(ctx, `
+		SELECT status
+		FROM task
+		WHERE id = $1 AND owner_id = $2
+		FOR UPDATE
+	`, id, ownerId).Scan(&current)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrTaskNotFound
+		}
+		return err
+	}
+
+	if !CanChangeStatus(current, StatusDeleted) {
+		return &StatusTransitionError{
+			Current:   current,
+			Requested: StatusDeleted,
+		}
+	}
+
+	// deleted_with tells RestoreTask which descendants were deleted together with the task
+	_, err = tx.Exec(ctx, `
+		WITH RECURSIVE subtree AS (
+			SELECT id
+			FROM task
+			WHERE id = $1
+			UNION ALL
+			SELECT t.id
+			FROM task t
+			JOIN subtree ON t.parent_id = subtree.id
+			WHERE t.status <> $2
+		), deleted AS (
+			UPDATE task
+			SET status = $2,
+				status_before_delete = task.status,
+				deleted_at = now(),
+				deleted_with = $1,
+				updated_at = now()
+			FROM subtree
+			WHERE task.id = subtree.id
+			RETURNING task.id, task.status_before_delete
+		), stopped AS (
+			UPDATE task_time_entry
+			SET ended_at = now()
+			WHERE ended_at IS NULL AND task_id IN (SELECT id FROM deleted)
+		)
+		INSERT INTO task_status_history(task_id, from_status, to_status, changed_by)
+		SELECT id, status_before_delete, $2, $3::integer
+		FROM deleted`,
 		id,
+		StatusDeleted,
+		actorId,
 	)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return err
+	}
 
 	if config.DebugLog() {
 		log.Println("delete task: successfully deleted task in db")
 	}
 
-	return err
+	return nil
 }
 
-func RestoreTask(id uint16) error {
-	conn, err := db.ConnectionPool()
+// RestoreTask returns the task with descendants deleted together with it to their statuses before deletion,
+// the ones back in progress get a running time entry of the actor
+func (s *PgStore) RestoreTask(ownerId uint16, id int64, actorId uint16) error {
+	ctx := context.Background()
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var current string
+	var parentStatus sql.NullString
+	err = tx.QueryRow(ctx, `
+		SELECT t.status, p.status
+		FROM task t
+		LEFT JOIN task p ON p.id = t.parent_id
+		WHERE t.id = $1 AND t.owner_id = $2
+		FOR UPDATE OF t
+	`, id, ownerId).Scan(&current, &parentStatus)
 	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrTaskNotFound
+		}
 		return err
 	}
 
-	_, err = conn.Exec(context.Background(), `
-		UPDATE task
-		SET status = $1
-		WHERE id = $2`,
-		StatusCreated, // ? maybe we should use in progress status or paused
+	if current != StatusDeleted {
+		return statusTransitionError(ctx, tx, ownerId, id, "")
+	}
+
+	if parentStatus.String == StatusDeleted {
+		return ErrTaskParentDeleted
+	}
+
+	_, err = tx.Exec(ctx, `
+		WITH restored AS (
+			UPDATE task
+			SET status = COALESCE(status_before_delete, $3),
+				status_before_delete = NULL,
+				deleted_at = NULL,
+				deleted_with = NULL,
+				updated_at = now()
+			WHERE owner_id = $2 AND status = $4 AND (id = $1 OR deleted_with = $1)
+			RETURNING id, status
+		), history AS (
+			INSERT INTO task_status_history(task_id, from_status, to_status, changed_by)
+			SELECT id, $4, status, $5::integer
+			FROM restored
+		)
+		INSERT INTO task_time_entry(task_id, user_id)
+		SELECT id, $5::integer
+		FROM restored
+		WHERE status = $6
+		ON CONFLICT (task_id) WHERE ended_at IS NULL DO NOTHING`,
 		id,
+		ownerId,
+		StatusCreated, // tasks deleted before status_before_delete was introduced
+		StatusDeleted,
+		actorId,
+		StatusInProgress,
 	)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return err
+	}
 
 	if config.DebugLog() {
 		log.Println("restore task: successfully restored task in db")
 	}
 
-	return err
+	return nil
 }
 
-func DeleteTaskCompletely(id uint16) error {
-	conn, err := db.ConnectionPool()
+// DeleteTaskCompletely deletes the task with all its descendants, they are removed by parent_fk cascade
+func (s *PgStore) DeleteTaskCompletely(ownerId uint16, id int64) ([]string, error) {
+	ctx := context.Background()
+
+	tx, err := s.pool.Begin(ctx)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	err = ownedTaskExists(ctx, tx, ownerId, id)
+	if err != nil {
+		return nil, err
+	}
+
+	keys, err := lockedAttachmentKeys(ctx, tx, []int64{id})
+	if err != nil {
+		return nil, err
 	}
 
-	_, err = conn.Exec(context.Background(), `
+	tag, err := tx.Exec(ctx, `
 		DELETE FROM task
-		WHERE id = $1`,
+		WHERE id = $1 AND owner_id = $2`,
 		id,
+		ownerId,
 	)
+	if err != nil {
+		return nil, err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return nil, ErrTaskNotFound
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return nil, err
+	}
 
 	if config.DebugLog() {
 		log.Println("delete task completely: successfully deleted task in db completely")
 	}
 
-	return err
+	return keys, nil
 }
 
-func FreeTaskTrash() error {
-	conn, err := db.ConnectionPool()
+func (s *PgStore) FreeTaskTrash(ownerId uint16, projectId *int64) ([]string, error) {
+	ctx := context.Background()
+
+	tx, err := s.pool.Begin(ctx)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	var ids []int64
+	rows, err := tx.Query(ctx, `
+		SELECT id
+		FROM task
+		WHERE owner_id = $1 AND status = $2 AND ($3::bigint IS NULL OR project_id = $3)
+		FOR UPDATE
+	`, ownerId, StatusDeleted, projectId)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var id int64
+		err = rows.Scan(&id)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	if projectId != nil {
+		var outside bool
+		err = tx.QueryRow(ctx, `
+			WITH RECURSIVE subtree AS (
+				SELECT id, project_id
+				FROM task
+				WHERE id = ANY($1)
+				UNION
+				SELECT t.id, t.project_id
+				FROM task t
+				JOIN subtree ON t.parent_id = subtree.id
+			)
+			SELECT EXISTS (SELECT 1 FROM subtree WHERE project_id IS DISTINCT FROM $2)
+		`, ids, *projectId).Scan(&outside)
+		if err != nil {
+			return nil, err
+		}
+
+		if outside {
+			return nil, ErrTrashSubtaskOutsideProject
+		}
 	}
 
-	_, err = conn.Exec(context.Background(), `
+	keys, err := lockedAttachmentKeys(ctx, tx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Exec(ctx, `
 		DELETE FROM task
-		WHERE status = $1`,
-		StatusDeleted,
+		WHERE id = ANY($1)`,
+		ids,
 	)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return nil, err
+	}
 
 	if config.DebugLog() {
 		log.Println("free task trash: successfully freed task trash in db")
 	}
 
-	return err
+	return keys, nil
+}
+
+func (s *PgStore) GetTaskStatusHistory(ownerId uint16, id int64) ([]*TaskStatusChange, error) {
+	var result []*TaskStatusChange = []*TaskStatusChange{}
+
+	rows, err := s.pool.Query(context.Background(), `
+		SELECT COALESCE(h.from_status, ''), h.to_status, COALESCE(h.changed_by, 0), h.changed_at
+		FROM task_status_history h
+		JOIN task t ON t.id = h.task_id
+		WHERE h.task_id = $1 AND t.owner_id = $2
+		ORDER BY h.changed_at ASC, h.id ASC
+	`, id, ownerId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var item TaskStatusChange
+
+		err = rows.Scan(&item.FromStatus, &item.ToStatus, &item.ChangedBy, &item.ChangedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, &item)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(result) == 0 { // every task has at least creation row, so task is missing or not owned
+		return nil, ErrTaskNotFound
+	}
+
+	if config.DebugLog() {
+		log.Println("task status history: successfully retrieved data from db")
+	}
+
+	return result, nil
 }
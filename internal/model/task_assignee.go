@@ -0,0 +1,188 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"log"
+	"todo/internal/config"
+)
+
+// ErrAssigneeNotMember is returned for assignee who is neither the owner nor a member of the owner workspace
+var ErrAssigneeNotMember = errors.New("assignee is not a member of the workspace")
+
+// TaskAssigneeStore methods are scoped to the task owner the same way TaskStore ones are
+type TaskAssigneeStore interface {
+	// SetTaskAssignees replaces assignees of the task by distinct userIds and returns them ordered by id
+	SetTaskAssignees(ownerId uint16, taskId int64, userIds []uint16) ([]uint16, error)
+}
+
+func assigneeIds(userIds []uint16) []int32 {
+	ids := make([]int32, 0, len(userIds))
+	for _, id := range userIds {
+		ids = append(ids, int32(id))
+	}
+	return ids
+}
+
+func (s *PgStore) SetTaskAssignees(ownerId uint16, taskId int64, userIds []uint16) ([]uint16, error) {
+	ctx := context.Background()
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	err = ownedTaskExists(ctx, tx, ownerId, taskId)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := assigneeIds(userIds)
+
+	var members int
+	err = tx.QueryRow(ctx, `
+		SELECT COUNT(*)
+		FROM users u
+		WHERE u.id = ANY($2) AND (u.id = $1 OR EXISTS (
+			SELECT 1
+			FROM workspace_member m
+			WHERE m.workspace_id = $1 AND m.user_id = u.id AND m.accepted_at IS NOT NULL
+		))
+	`, ownerId, ids).Scan(&members)
+	if err != nil {
+		return nil, err
+	}
+
+	if members != len(ids) {
+		return nil, ErrAssigneeNotMember
+	}
+
+	_, err = tx.Exec(ctx, `
+		DELETE FROM task_assignee
+		WHERE task_id = $1 AND user_id <> ALL($2)`,
+		taskId,
+		ids,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO task_assignee(task_id, user_id)
+		SELECT $1, unnest($2::integer[])
+		ON CONFLICT DO NOTHING`,
+		taskId,
+		ids,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []uint16{}
+	rows, err := tx.Query(ctx, `
+		SELECT user_id
+		FROM task_assignee
+		WHERE task_id = $1
+		ORDER BY user_id
+	`, taskId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id uint16
+
+		err = rows.Scan(&id)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, id)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if config.DebugLog() {
+		log.Println("task assignees: successfully set data in db")
+	}
+
+	return result, nil
+}
+
+// autoAssign assigns the user who started the task with config.AutoAssign unless the task has assignees already
+func autoAssign(ctx context.Context, q querier, taskId int64, userId uint16) error {
+	if !config.AutoAssign() {
+		return nil
+	}
+
+	_, err := q.Exec(ctx, `
+		INSERT INTO task_assignee(task_id, user_id)
+		SELECT $1, $2::integer
+		WHERE NOT EXISTS (SELECT 1 FROM task_assignee WHERE task_id = $1)`,
+		taskId,
+		userId,
+	)
+	return err
+}
+
+// copyAssignees gives the next occurrence of a recurring task the same assignees
+func copyAssignees(ctx context.Context, q querier, fromId, toId int64) error {
+	_, err := q.Exec(ctx, `
+		INSERT INTO task_assignee(task_id, user_id)
+		SELECT $2, user_id
+		FROM task_assignee
+		WHERE task_id = $1`,
+		fromId,
+		toId,
+	)
+	return err
+}
+
+// loadTaskAssignees fills assignees of all tasks by a single query
+func (s *PgStore) loadTaskAssignees(ctx context.Context, tasks []*Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	byId := make(map[int64]*Task, len(tasks))
+	ids := make([]int64, 0, len(tasks))
+	for _, task := range tasks {
+		byId[task.Id] = task
+		ids = append(ids, task.Id)
+	}
+
+	rows, err := s.pool.Query(ctx, `
+		SELECT task_id, user_id
+		FROM task_assignee
+		WHERE task_id = ANY($1)
+		ORDER BY user_id
+	`, ids)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var taskId int64
+		var userId uint16
+
+		err = rows.Scan(&taskId, &userId)
+		if err != nil {
+			return err
+		}
+
+		task := byId[taskId]
+		task.Assignees = append(task.Assignees, userId)
+	}
+
+	return rows.Err()
+}
@@ -0,0 +1,63 @@
+package model
+
+import (
+	"sort"
+	"todo/internal/config"
+)
+
+// assignees returns assignees of the task ordered by id, must be called with s.mu held
+func (s *MemoryStore) assignees(taskId int64) []uint16 {
+	result := []uint16{}
+	for userId := range s.taskAssignees[taskId] {
+		result = append(result, userId)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i] < result[j]
+	})
+
+	return result
+}
+
+// workspaceMember tells whether the user is the owner or a member of the owner workspace,
+// must be called with s.mu held
+func (s *MemoryStore) workspaceMember(ownerId, userId uint16) bool {
+	if userId == ownerId {
+		_, ok := s.users[userId]
+		return ok
+	}
+
+	member, ok := s.workspaceMembers[ownerId][userId]
+	return ok && member.AcceptedAt != nil
+}
+
+func (s *MemoryStore) SetTaskAssignees(ownerId uint16, taskId int64, userIds []uint16) ([]uint16, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.ownedTask(ownerId, taskId); err != nil {
+		return nil, err
+	}
+
+	for _, userId := range userIds {
+		if !s.workspaceMember(ownerId, userId) {
+			return nil, ErrAssigneeNotMember
+		}
+	}
+
+	s.taskAssignees[taskId] = map[uint16]bool{}
+	for _, userId := range userIds {
+		s.taskAssignees[taskId][userId] = true
+	}
+
+	return s.assignees(taskId), nil
+}
+
+// autoAssign is the same as the postgres one, must be called with s.mu held
+func (s *MemoryStore) autoAssign(taskId int64, userId uint16) {
+	if !config.AutoAssign() || len(s.taskAssignees[taskId]) > 0 {
+		return
+	}
+
+	s.taskAssignees[taskId] = map[uint16]bool{userId: true}
+}
@@ -0,0 +1,227 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+	"todo/internal/config"
+
+	"github.com/jackc/pgx/v4"
+)
+
+// TaskAttachment is metadata of a file attached to task, the content is kept by blob store under BlobKey
+type TaskAttachment struct {
+	Id          int64     `json:"id"`
+	TaskId      int64     `json:"task_id"`
+	UploaderId  uint16    `json:"uploader_id"`
+	Name        string    `json:"name" example:"report.pdf"`
+	ContentType string    `json:"content_type" example:"application/pdf"`
+	Size        int64     `json:"size"`
+	Checksum    string    `json:"checksum"` // hex encoded sha256 of the content
+	BlobKey     string    `json:"-"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+var ErrAttachmentNotFound = errors.New("attachment not found")
+
+// TaskAttachmentStore keeps attachment metadata scoped to the task owner,
+// removing the blobs is up to the caller
+type TaskAttachmentStore interface {
+	GetTaskAttachments(ownerId uint16, taskId int64) ([]*TaskAttachment, error)
+	GetTaskAttachment(ownerId uint16, taskId, id int64) (*TaskAttachment, error)
+	AddTaskAttachment(ownerId uint16, attachment TaskAttachment) (*TaskAttachment, error)
+	// DeleteTaskAttachment returns the deleted attachment, so its blob can be removed
+	DeleteTaskAttachment(ownerId uint16, taskId, id int64) (*TaskAttachment, error)
+}
+
+const taskAttachmentColumns = "id, task_id, uploader_id, name, content_type, size, checksum, blob_key, created_at"
+
+func scanTaskAttachment(row pgx.Row) (*TaskAttachment, error) {
+	var item TaskAttachment
+
+	err := row.Scan(&item.Id, &item.TaskId, &item.UploaderId, &item.Name, &item.ContentType, &item.Size, &item.Checksum, &item.BlobKey, &item.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &item, nil
+}
+
+func (s *PgStore) GetTaskAttachments(ownerId uint16, taskId int64) ([]*TaskAttachment, error) {
+	ctx := context.Background()
+
+	err := ownedTaskExists(ctx, s.pool, ownerId, taskId)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.pool.Query(ctx, `
+		SELECT `+taskAttachmentColumns+`
+		FROM task_attachment
+		WHERE task_id = $1
+		ORDER BY id
+	`, taskId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []*TaskAttachment{}
+	for rows.Next() {
+		item, err := scanTaskAttachment(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, item)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	if config.DebugLog() {
+		log.Println("task attachments: successfully retrieved data from db")
+	}
+
+	return result, nil
+}
+
+func (s *PgStore) GetTaskAttachment(ownerId uint16, taskId, id int64) (*TaskAttachment, error) {
+	ctx := context.Background()
+
+	err := ownedTaskExists(ctx, s.pool, ownerId, taskId)
+	if err != nil {
+		return nil, err
+	}
+
+	item, err := scanTaskAttachment(s.pool.QueryRow(ctx, `
+		SELECT `+taskAttachmentColumns+`
+		FROM task_attachment
+		WHERE id = $1 AND task_id = $2
+	`, id, taskId))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrAttachmentNotFound
+		}
+		return nil, err
+	}
+
+	return item, nil
+}
+
+func (s *PgStore) AddTaskAttachment(ownerId uint16, attachment TaskAttachment) (*TaskAttachment, error) {
+	item, err := scanTaskAttachment(s.pool.QueryRow(context.Background(), `
+		INSERT INTO task_attachment(task_id, uploader_id, name, content_type, size, checksum, blob_key)
+		SELECT id, $3, $4, $5, $6, $7, $8
+		FROM task
+		WHERE id = $1 AND owner_id = $2
+		RETURNING `+taskAttachmentColumns,
+		attachment.TaskId,
+		ownerId,
+		attachment.UploaderId,
+		attachment.Name,
+		attachment.ContentType,
+		attachment.Size,
+		attachment.Checksum,
+		attachment.BlobKey,
+	))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrTaskNotFound
+		}
+		return nil, err
+	}
+
+	if config.DebugLog() {
+		log.Println("add task attachment: successfully created data in db")
+	}
+
+	return item, nil
+}
+
+func (s *PgStore) DeleteTaskAttachment(ownerId uint16, taskId, id int64) (*TaskAttachment, error) {
+	ctx := context.Background()
+
+	err := ownedTaskExists(ctx, s.pool, ownerId, taskId)
+	if err != nil {
+		return nil, err
+	}
+
+	item, err := scanTaskAttachment(s.pool.QueryRow(ctx, `
+		DELETE FROM task_attachment
+		WHERE id = $1 AND task_id = $2
+		RETURNING `+taskAttachmentColumns,
+		id,
+		taskId,
+	))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrAttachmentNotFound
+		}
+		return nil, err
+	}
+
+	if config.DebugLog() {
+		log.Println("delete task attachment: successfully deleted data in db")
+	}
+
+	return item, nil
+}
+
+// lockedAttachmentKeys locks the tasks against new attachments and returns blob keys of attachments
+// of the tasks and all their descendants, which are deleted together with them by parent_fk cascade
+func lockedAttachmentKeys(ctx context.Context, tx pgx.Tx, ids []int64) ([]string, error) {
+	_, err := tx.Exec(ctx, `
+		WITH RECURSIVE subtree AS (
+			SELECT id
+			FROM task
+			WHERE id = ANY($1)
+			UNION
+			SELECT t.id
+			FROM task t
+			JOIN subtree ON t.parent_id = subtree.id
+		)
+		SELECT id
+		FROM task
+		WHERE id IN (SELECT id FROM subtree)
+		FOR UPDATE
+	`, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.Query(ctx, `
+		WITH RECURSIVE subtree AS (
+			SELECT id
+			FROM task
+			WHERE id = ANY($1)
+			UNION
+			SELECT t.id
+			FROM task t
+			JOIN subtree ON t.parent_id = subtree.id
+		)
+		SELECT blob_key
+		FROM task_attachment
+		WHERE task_id IN (SELECT id FROM subtree)
+	`, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := []string{}
+	for rows.Next() {
+		var key string
+
+		err = rows.Scan(&key)
+		if err != nil {
+			return nil, err
+		}
+
+		keys = append(keys, key)
+	}
+
+	return keys, rows.Err()
+}
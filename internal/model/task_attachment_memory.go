@@ -0,0 +1,99 @@
+package model
+
+import (
+	"sort"
+	"time"
+)
+
+func (s *MemoryStore) GetTaskAttachments(ownerId uint16, taskId int64) ([]*TaskAttachment, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if _, err := s.ownedTask(ownerId, taskId); err != nil {
+		return nil, err
+	}
+
+	result := []*TaskAttachment{}
+	for _, attachment := range s.attachments {
+		if attachment.TaskId == taskId {
+			item := *attachment
+			result = append(result, &item)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Id < result[j].Id
+	})
+
+	return result, nil
+}
+
+// ownAttachment must be called with s.mu held
+func (s *MemoryStore) ownAttachment(ownerId uint16, taskId, id int64) (*TaskAttachment, error) {
+	if _, err := s.ownedTask(ownerId, taskId); err != nil {
+		return nil, err
+	}
+
+	attachment, ok := s.attachments[id]
+	if !ok || attachment.TaskId != taskId {
+		return nil, ErrAttachmentNotFound
+	}
+
+	return attachment, nil
+}
+
+func (s *MemoryStore) GetTaskAttachment(ownerId uint16, taskId, id int64) (*TaskAttachment, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	attachment, err := s.ownAttachment(ownerId, taskId, id)
+	if err != nil {
+		return nil, err
+	}
+
+	item := *attachment
+	return &item, nil
+}
+
+func (s *MemoryStore) AddTaskAttachment(ownerId uint16, attachment TaskAttachment) (*TaskAttachment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.ownedTask(ownerId, attachment.TaskId); err != nil {
+		return nil, err
+	}
+
+	s.lastAttachmentId++
+	attachment.Id = s.lastAttachmentId
+	attachment.CreatedAt = time.Now()
+	s.attachments[attachment.Id] = &attachment
+
+	item := attachment
+	return &item, nil
+}
+
+func (s *MemoryStore) DeleteTaskAttachment(ownerId uint16, taskId, id int64) (*TaskAttachment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	attachment, err := s.ownAttachment(ownerId, taskId, id)
+	if err != nil {
+		return nil, err
+	}
+
+	delete(s.attachments, id)
+
+	return attachment, nil
+}
+
+// removeTaskAttachments returns blob keys of the removed attachments, must be called with s.mu held
+func (s *MemoryStore) removeTaskAttachments(taskId int64) []string {
+	var keys []string
+	for id, attachment := range s.attachments {
+		if attachment.TaskId == taskId {
+			keys = append(keys, attachment.BlobKey)
+			delete(s.attachments, id)
+		}
+	}
+	return keys
+}
@@ -0,0 +1,444 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+	"todo/internal/config"
+
+	"github.com/jackc/pgx/v4"
+)
+
+// ChecklistItem is a step of the task checklist, items are ordered by Position the same way tasks of a status column are
+type ChecklistItem struct {
+	Id        int64     `json:"id"`
+	TaskId    int64     `json:"task_id"`
+	Text      string    `json:"text" example:"Write release notes"`
+	Checked   bool      `json:"checked"`
+	Position  string    `json:"position"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ChecklistProgress counts checked and total items of the task checklist
+type ChecklistProgress struct {
+	Checked int `json:"checked"`
+	Total   int `json:"total"`
+}
+
+// ChecklistItemFields are the editable fields of a checklist item, nil keeps the current value
+type ChecklistItemFields struct {
+	Text    *string
+	Checked *bool
+}
+
+// ChecklistMove places item between its neighbours, without neighbours item goes to the end of the checklist
+type ChecklistMove struct {
+	After  int64 // id of the item to place after, 0 means none
+	Before int64 // id of the item to place before, 0 means none
+}
+
+var (
+	ErrChecklistItemNotFound  = errors.New("checklist item not found")
+	ErrChecklistNeighbourItem = errors.New("neighbour item is missing or neighbours are in the wrong order")
+)
+
+// ChecklistIncompleteError is returned by DoneTask and MoveTask to done with config.StrictChecklist
+// while some of the checklist items are unchecked
+type ChecklistIncompleteError struct {
+	Unchecked int
+}
+
+func (e *ChecklistIncompleteError) Error() string {
+	return fmt.Sprintf("task has %d unchecked checklist items", e.Unchecked)
+}
+
+// TaskChecklistStore methods are scoped to the task owner the same way TaskStore ones are
+type TaskChecklistStore interface {
+	GetChecklist(ownerId uint16, taskId int64) ([]*ChecklistItem, error)
+	AddChecklistItem(ownerId uint16, taskId int64, text string) (*ChecklistItem, error)
+	EditChecklistItem(ownerId uint16, taskId, id int64, fields ChecklistItemFields) (*ChecklistItem, error)
+	MoveChecklistItem(ownerId uint16, taskId, id int64, move ChecklistMove) (*ChecklistItem, error)
+	RemoveChecklistItem(ownerId uint16, taskId, id int64) error
+}
+
+// checklistMovePosition returns position between neighbours of the move,
+// items are the other items of the checklist ordered by position and id
+func checklistMovePosition(items []*ChecklistItem, move ChecklistMove) (string, error) {
+	index := func(id int64) int {
+		for i, item := range items {
+			if item.Id == id {
+				return i
+			}
+		}
+		return -1
+	}
+
+	var lower, upper string
+
+	if move.After != 0 {
+		i := index(move.After)
+		if i < 0 {
+			return "", ErrChecklistNeighbourItem
+		}
+		lower = items[i].Position
+		if move.Before == 0 && i+1 < len(items) {
+			upper = items[i+1].Position
+		}
+	}
+
+	if move.Before != 0 {
+		i := index(move.Before)
+		if i < 0 {
+			return "", ErrChecklistNeighbourItem
+		}
+		upper = items[i].Position
+		if move.After == 0 && i > 0 {
+			lower = items[i-1].Position
+		}
+	}
+
+	if move.After == 0 && move.Before == 0 && len(items) > 0 {
+		lower = items[len(items)-1].Position
+	}
+
+	position, err := movePosition(lower, upper, TaskMove{After: move.After, Before: move.Before})
+	if errors.Is(err, ErrTaskNeighbourInvalid) {
+		return "", ErrChecklistNeighbourItem
+	}
+
+	return position, err
+}
+
+const checklistItemColumns = "id, task_id, text, checked, position, created_at, updated_at"
+
+func scanChecklistItem(row pgx.Row) (*ChecklistItem, error) {
+	var item ChecklistItem
+
+	err := row.Scan(&item.Id, &item.TaskId, &item.Text, &item.Checked, &item.Position, &item.CreatedAt, &item.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &item, nil
+}
+
+// queryChecklist returns items of the task ordered by position
+func queryChecklist(ctx context.Context, q querier, taskId int64) ([]*ChecklistItem, error) {
+	rows, err := q.Query(ctx, `
+		SELECT `+checklistItemColumns+`
+		FROM task_checklist_item
+		WHERE task_id = $1
+		ORDER BY position, id
+	`, taskId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []*ChecklistItem{}
+	for rows.Next() {
+		item, err := scanChecklistItem(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, item)
+	}
+
+	return result, rows.Err()
+}
+
+// lockChecklist locks the task, so positions of its checklist are computed by one transaction at a time
+func lockChecklist(ctx context.Context, tx pgx.Tx, ownerId uint16, taskId int64) error {
+	var id int64
+
+	err := tx.QueryRow(ctx, `
+		SELECT id
+		FROM task
+		WHERE id = $1 AND owner_id = $2
+		FOR UPDATE
+	`, taskId, ownerId).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrTaskNotFound
+	}
+
+	return err
+}
+
+func (s *PgStore) GetChecklist(ownerId uint16, taskId int64) ([]*ChecklistItem, error) {
+	ctx := context.Background()
+
+	err := ownedTaskExists(ctx, s.pool, ownerId, taskId)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := queryChecklist(ctx, s.pool, taskId)
+	if err != nil {
+		return nil, err
+	}
+
+	if config.DebugLog() {
+		log.Println("task checklist: successfully retrieved data from db")
+	}
+
+	return result, nil
+}
+
+func (s *PgStore) AddChecklistItem(ownerId uint16, taskId int64, text string) (*ChecklistItem, error) {
+	ctx := context.Background()
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	err = lockChecklist(ctx, tx, ownerId, taskId)
+	if err != nil {
+		return nil, err
+	}
+
+	var last string
+	err = tx.QueryRow(ctx, `
+		SELECT position
+		FROM task_checklist_item
+		WHERE task_id = $1
+		ORDER BY position DESC, id DESC
+		LIMIT 1
+	`, taskId).Scan(&last)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, err
+	}
+
+	position, err := positionBetween(last, "")
+	if err != nil {
+		return nil, err
+	}
+
+	item, err := scanChecklistItem(tx.QueryRow(ctx, `
+		INSERT INTO task_checklist_item(task_id, text, position)
+		VALUES ($1, $2, $3)
+		RETURNING `+checklistItemColumns,
+		taskId,
+		text,
+		position,
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if config.DebugLog() {
+		log.Println("add checklist item: successfully created data in db")
+	}
+
+	return item, nil
+}
+
+func (s *PgStore) EditChecklistItem(ownerId uint16, taskId, id int64, fields ChecklistItemFields) (*ChecklistItem, error) {
+	ctx := context.Background()
+
+	err := ownedTaskExists(ctx, s.pool, ownerId, taskId)
+	if err != nil {
+		return nil, err
+	}
+
+	item, err := scanChecklistItem(s.pool.QueryRow(ctx, `
+		UPDATE task_checklist_item
+		SET text = COALESCE($1, text),
+			checked = COALESCE($2, checked),
+			updated_at = now()
+		WHERE id = $3 AND task_id = $4
+		RETURNING `+checklistItemColumns,
+		fields.Text,
+		fields.Checked,
+		id,
+		taskId,
+	))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrChecklistItemNotFound
+		}
+		return nil, err
+	}
+
+	if config.DebugLog() {
+		log.Println("edit checklist item: successfully edited data in db")
+	}
+
+	return item, nil
+}
+
+func (s *PgStore) MoveChecklistItem(ownerId uint16, taskId, id int64, move ChecklistMove) (*ChecklistItem, error) {
+	ctx := context.Background()
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	err = lockChecklist(ctx, tx, ownerId, taskId)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := queryChecklist(ctx, tx, taskId)
+	if err != nil {
+		return nil, err
+	}
+
+	found := false
+	others := make([]*ChecklistItem, 0, len(items))
+	for _, item := range items {
+		if item.Id == id {
+			found = true
+			continue
+		}
+		others = append(others, item)
+	}
+	if !found {
+		return nil, ErrChecklistItemNotFound
+	}
+
+	position, err := checklistMovePosition(others, move)
+	if err != nil {
+		return nil, err
+	}
+
+	item, err := scanChecklistItem(tx.QueryRow(ctx, `
+		UPDATE task_checklist_item
+		SET position = $1,
+			updated_at = now()
+		WHERE id = $2
+		RETURNING `+checklistItemColumns,
+		position,
+		id,
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if config.DebugLog() {
+		log.Println("move checklist item: successfully moved data in db")
+	}
+
+	return item, nil
+}
+
+func (s *PgStore) RemoveChecklistItem(ownerId uint16, taskId, id int64) error {
+	ctx := context.Background()
+
+	err := ownedTaskExists(ctx, s.pool, ownerId, taskId)
+	if err != nil {
+		return err
+	}
+
+	tag, err := s.pool.Exec(ctx, `
+		DELETE FROM task_checklist_item
+		WHERE id = $1 AND task_id = $2`,
+		id,
+		taskId,
+	)
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return ErrChecklistItemNotFound
+	}
+
+	if config.DebugLog() {
+		log.Println("remove checklist item: successfully deleted data in db")
+	}
+
+	return nil
+}
+
+// checklistComplete returns ChecklistIncompleteError with config.StrictChecklist if some item of the task is unchecked
+func checklistComplete(ctx context.Context, q querier, taskId int64) error {
+	if !config.StrictChecklist() {
+		return nil
+	}
+
+	var unchecked int
+	err := q.QueryRow(ctx, `
+		SELECT COUNT(*)
+		FROM task_checklist_item
+		WHERE task_id = $1 AND NOT checked
+	`, taskId).Scan(&unchecked)
+	if err != nil {
+		return err
+	}
+
+	if unchecked > 0 {
+		return &ChecklistIncompleteError{Unchecked: unchecked}
+	}
+
+	return nil
+}
+
+// copyChecklist gives the next occurrence of a recurring task the same checklist, all items unchecked
+func copyChecklist(ctx context.Context, tx pgx.Tx, fromId, toId int64) error {
+	_, err := tx.Exec(ctx, `
+		INSERT INTO task_checklist_item(task_id, text, position)
+		SELECT $2, text, position
+		FROM task_checklist_item
+		WHERE task_id = $1`,
+		fromId,
+		toId,
+	)
+	return err
+}
+
+// loadTaskChecklistProgress fills checklist progress of all tasks by a single query
+func (s *PgStore) loadTaskChecklistProgress(ctx context.Context, tasks []*Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	byId := make(map[int64]*Task, len(tasks))
+	ids := make([]int64, 0, len(tasks))
+	for _, task := range tasks {
+		byId[task.Id] = task
+		ids = append(ids, task.Id)
+	}
+
+	rows, err := s.pool.Query(ctx, `
+		SELECT task_id, COUNT(*) FILTER (WHERE checked), COUNT(*)
+		FROM task_checklist_item
+		WHERE task_id = ANY($1)
+		GROUP BY task_id
+	`, ids)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var taskId int64
+		var progress ChecklistProgress
+
+		err = rows.Scan(&taskId, &progress.Checked, &progress.Total)
+		if err != nil {
+			return err
+		}
+
+		byId[taskId].Checklist = progress
+	}
+
+	return rows.Err()
+}
@@ -0,0 +1,181 @@
+package model
+
+import (
+	"sort"
+	"time"
+	"todo/internal/config"
+)
+
+// checklist returns items of the task ordered by position, must be called with s.mu held
+func (s *MemoryStore) checklist(taskId int64) []*ChecklistItem {
+	var result []*ChecklistItem
+	for _, item := range s.checklistItems {
+		if item.TaskId == taskId {
+			result = append(result, item)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Position != result[j].Position {
+			return result[i].Position < result[j].Position
+		}
+		return result[i].Id < result[j].Id
+	})
+
+	return result
+}
+
+// checklistProgress must be called with s.mu held
+func (s *MemoryStore) checklistProgress(taskId int64) ChecklistProgress {
+	var progress ChecklistProgress
+	for _, item := range s.checklistItems {
+		if item.TaskId != taskId {
+			continue
+		}
+		progress.Total++
+		if item.Checked {
+			progress.Checked++
+		}
+	}
+	return progress
+}
+
+// checklistComplete must be called with s.mu held
+func (s *MemoryStore) checklistComplete(taskId int64) error {
+	if !config.StrictChecklist() {
+		return nil
+	}
+
+	progress := s.checklistProgress(taskId)
+	if progress.Checked < progress.Total {
+		return &ChecklistIncompleteError{Unchecked: progress.Total - progress.Checked}
+	}
+
+	return nil
+}
+
+// ownChecklistItem must be called with s.mu held
+func (s *MemoryStore) ownChecklistItem(ownerId uint16, taskId, id int64) (*ChecklistItem, error) {
+	if _, err := s.ownedTask(ownerId, taskId); err != nil {
+		return nil, err
+	}
+
+	item, ok := s.checklistItems[id]
+	if !ok || item.TaskId != taskId {
+		return nil, ErrChecklistItemNotFound
+	}
+
+	return item, nil
+}
+
+func copyChecklistItems(items []*ChecklistItem) []*ChecklistItem {
+	result := make([]*ChecklistItem, 0, len(items))
+	for _, item := range items {
+		copied := *item
+		result = append(result, &copied)
+	}
+	return result
+}
+
+func (s *MemoryStore) GetChecklist(ownerId uint16, taskId int64) ([]*ChecklistItem, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if _, err := s.ownedTask(ownerId, taskId); err != nil {
+		return nil, err
+	}
+
+	return copyChecklistItems(s.checklist(taskId)), nil
+}
+
+func (s *MemoryStore) AddChecklistItem(ownerId uint16, taskId int64, text string) (*ChecklistItem, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.ownedTask(ownerId, taskId); err != nil {
+		return nil, err
+	}
+
+	position, err := checklistMovePosition(s.checklist(taskId), ChecklistMove{})
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+
+	s.lastChecklistItemId++
+	item := &ChecklistItem{
+		Id:        s.lastChecklistItemId,
+		TaskId:    taskId,
+		Text:      text,
+		Position:  position,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	s.checklistItems[item.Id] = item
+
+	copied := *item
+	return &copied, nil
+}
+
+func (s *MemoryStore) EditChecklistItem(ownerId uint16, taskId, id int64, fields ChecklistItemFields) (*ChecklistItem, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	item, err := s.ownChecklistItem(ownerId, taskId, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if fields.Text != nil {
+		item.Text = *fields.Text
+	}
+	if fields.Checked != nil {
+		item.Checked = *fields.Checked
+	}
+	item.UpdatedAt = time.Now()
+
+	copied := *item
+	return &copied, nil
+}
+
+func (s *MemoryStore) MoveChecklistItem(ownerId uint16, taskId, id int64, move ChecklistMove) (*ChecklistItem, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	item, err := s.ownChecklistItem(ownerId, taskId, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var others []*ChecklistItem
+	for _, other := range s.checklist(taskId) {
+		if other.Id != id {
+			others = append(others, other)
+		}
+	}
+
+	position, err := checklistMovePosition(others, move)
+	if err != nil {
+		return nil, err
+	}
+
+	item.Position = position
+	item.UpdatedAt = time.Now()
+
+	copied := *item
+	return &copied, nil
+}
+
+func (s *MemoryStore) RemoveChecklistItem(ownerId uint16, taskId, id int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.ownChecklistItem(ownerId, taskId, id); err != nil {
+		return err
+	}
+
+	delete(s.checklistItems, id)
+
+	return nil
+}
@@ -0,0 +1,41 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChecklistMovePosition(t *testing.T) {
+	items := []*ChecklistItem{{Id: 1, Position: "F"}, {Id: 2, Position: "V"}, {Id: 3, Position: "k"}}
+
+	// without neighbours the item goes to the end
+	position, err := checklistMovePosition(items, ChecklistMove{})
+	assert.NoError(t, err)
+	assert.Greater(t, position, "k")
+
+	position, err = checklistMovePosition(nil, ChecklistMove{})
+	assert.NoError(t, err)
+	assert.NotEmpty(t, position)
+
+	// one neighbour is enough, the other one is the adjacent item
+	position, err = checklistMovePosition(items, ChecklistMove{After: 1})
+	assert.NoError(t, err)
+	assert.Greater(t, position, "F")
+	assert.Less(t, position, "V")
+
+	position, err = checklistMovePosition(items, ChecklistMove{Before: 1})
+	assert.NoError(t, err)
+	assert.Less(t, position, "F")
+
+	position, err = checklistMovePosition(items, ChecklistMove{After: 2, Before: 3})
+	assert.NoError(t, err)
+	assert.Greater(t, position, "V")
+	assert.Less(t, position, "k")
+
+	_, err = checklistMovePosition(items, ChecklistMove{After: 3, Before: 1})
+	assert.ErrorIs(t, err, ErrChecklistNeighbourItem)
+
+	_, err = checklistMovePosition(items, ChecklistMove{After: 4})
+	assert.ErrorIs(t, err, ErrChecklistNeighbourItem)
+}
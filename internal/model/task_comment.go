@@ -0,0 +1,296 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+	"todo/internal/config"
+
+	"github.com/jackc/pgx/v4"
+)
+
+type TaskComment struct {
+	Id        int64     `json:"id"`
+	TaskId    int64     `json:"task_id"`
+	AuthorId  uint16    `json:"author_id"`
+	Body      string    `json:"body" example:"Lorum ipsum"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TaskCommentPage is a page of comments ordered from the oldest one
+type TaskCommentPage struct {
+	Comments   []*TaskComment `json:"data"`
+	Total      int            `json:"total"` // count of comments of the task on all pages
+	NextCursor string         `json:"next_cursor,omitempty"`
+	Next       int64          `json:"-"` // id of the last comment of the page, 0 on the last page
+}
+
+var (
+	ErrCommentNotFound  = errors.New("comment not found")
+	ErrCommentNotAuthor = errors.New("comment belongs to another user")
+)
+
+// TaskCommentStore methods are scoped to the task owner the same way TaskStore ones are,
+// only author of a comment can edit or delete it
+type TaskCommentStore interface {
+	// GetTaskComments returns up to limit comments following the comment with id after, 0 means from the start
+	GetTaskComments(ownerId uint16, taskId, after int64, limit int) (*TaskCommentPage, error)
+	AddTaskComment(ownerId uint16, taskId int64, authorId uint16, body string) (*TaskComment, error)
+	EditTaskComment(ownerId uint16, taskId, id int64, authorId uint16, body string) (*TaskComment, error)
+	DeleteTaskComment(ownerId uint16, taskId, id int64, authorId uint16) error
+}
+
+// paginate cuts the extra comment fetched over the limit and points Next to the last comment of the page
+func (page *TaskCommentPage) paginate(limit int) {
+	if len(page.Comments) <= limit {
+		return
+	}
+
+	page.Comments = page.Comments[:limit]
+	page.Next = page.Comments[len(page.Comments)-1].Id
+}
+
+// ownedTaskExists returns ErrTaskNotFound unless the task belongs to the owner
+func ownedTaskExists(ctx context.Context, q querier, ownerId uint16, id int64) error {
+	var exists bool
+	err := q.QueryRow(ctx, `
+		SELECT EXISTS (SELECT 1 FROM task WHERE id = $1 AND owner_id = $2)
+	`, id, ownerId).Scan(&exists)
+	if err != nil {
+		return err
+	}
+
+	if !exists {
+		return ErrTaskNotFound
+	}
+
+	return nil
+}
+
+func (s *PgStore) GetTaskComments(ownerId uint16, taskId, after int64, limit int) (*TaskCommentPage, error) {
+	ctx := context.Background()
+
+	err := ownedTaskExists(ctx, s.pool, ownerId, taskId)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &TaskCommentPage{Comments: []*TaskComment{}}
+
+	err = s.pool.QueryRow(ctx, `
+		SELECT COUNT(*)
+		FROM task_comment
+		WHERE task_id = $1
+	`, taskId).Scan(&page.Total)
+	if err != nil {
+		return nil, err
+	}
+
+	// one extra row tells whether there is a next page
+	rows, err := s.pool.Query(ctx, `
+		SELECT id, task_id, author_id, body, created_at, updated_at
+		FROM task_comment
+		WHERE task_id = $1 AND id > $2
+		ORDER BY id
+		LIMIT $3
+	`, taskId, after, limit+1)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var item TaskComment
+
+		err = rows.Scan(&item.Id, &item.TaskId, &item.AuthorId, &item.Body, &item.CreatedAt, &item.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		page.Comments = append(page.Comments, &item)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	page.paginate(limit)
+
+	if config.DebugLog() {
+		log.Println("task comments: successfully retrieved data from db")
+	}
+
+	return page, nil
+}
+
+func (s *PgStore) AddTaskComment(ownerId uint16, taskId int64, authorId uint16, body string) (*TaskComment, error) {
+	item := TaskComment{TaskId: taskId, AuthorId: authorId, Body: body}
+
+	err := s.pool.QueryRow(context.Background(), `
+		INSERT INTO task_comment(task_id, author_id, body)
+		SELECT id, $3, $4
+		FROM task
+		WHERE id = $1 AND owner_id = $2
+		RETURNING id, created_at, updated_at`,
+		taskId,
+		ownerId,
+		authorId,
+		body,
+	).Scan(&item.Id, &item.CreatedAt, &item.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrTaskNotFound
+		}
+		return nil, err
+	}
+
+	if config.DebugLog() {
+		log.Println("add task comment: successfully created data in db")
+	}
+
+	return &item, nil
+}
+
+// lockOwnComment checks the comment exists and is written by the author, locking it for the change
+func lockOwnComment(ctx context.Context, tx pgx.Tx, ownerId uint16, taskId, id int64, authorId uint16) error {
+	err := ownedTaskExists(ctx, tx, ownerId, taskId)
+	if err != nil {
+		return err
+	}
+
+	var commentAuthorId uint16
+	err = tx.QueryRow(ctx, `
+		SELECT author_id
+		FROM task_comment
+		WHERE id = $1 AND task_id = $2
+		FOR UPDATE
+	`, id, taskId).Scan(&commentAuthorId)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrCommentNotFound
+		}
+		return err
+	}
+
+	if commentAuthorId != authorId {
+		return ErrCommentNotAuthor
+	}
+
+	return nil
+}
+
+func (s *PgStore) EditTaskComment(ownerId uint16, taskId, id int64, authorId uint16, body string) (*TaskComment, error) {
+	ctx := context.Background()
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	err = lockOwnComment(ctx, tx, ownerId, taskId, id, authorId)
+	if err != nil {
+		return nil, err
+	}
+
+	var item TaskComment
+	err = tx.QueryRow(ctx, `
+		UPDATE task_comment
+		SET body = $1,
+			updated_at = now()
+		WHERE id = $2
+		RETURNING id, task_id, author_id, body, created_at, updated_at`,
+		body,
+		id,
+	).Scan(&item.Id, &item.TaskId, &item.AuthorId, &item.Body, &item.CreatedAt, &item.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if config.DebugLog() {
+		log.Println("edit task comment: successfully edited data in db")
+	}
+
+	return &item, nil
+}
+
+func (s *PgStore) DeleteTaskComment(ownerId uint16, taskId, id int64, authorId uint16) error {
+	ctx := context.Background()
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	err = lockOwnComment(ctx, tx, ownerId, taskId, id, authorId)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `
+		DELETE FROM task_comment
+		WHERE id = $1`,
+		id,
+	)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return err
+	}
+
+	if config.DebugLog() {
+		log.Println("delete task comment: successfully deleted data in db")
+	}
+
+	return nil
+}
+
+// loadTaskCommentCounts fills comment counts of all tasks by a single query
+func (s *PgStore) loadTaskCommentCounts(ctx context.Context, tasks []*Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	byId := make(map[int64]*Task, len(tasks))
+	ids := make([]int64, 0, len(tasks))
+	for _, task := range tasks {
+		byId[task.Id] = task
+		ids = append(ids, task.Id)
+	}
+
+	rows, err := s.pool.Query(ctx, `
+		SELECT task_id, COUNT(*)
+		FROM task_comment
+		WHERE task_id = ANY($1)
+		GROUP BY task_id
+	`, ids)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var taskId int64
+		var count int
+
+		err = rows.Scan(&taskId, &count)
+		if err != nil {
+			return err
+		}
+
+		byId[taskId].CommentCount = count
+	}
+
+	return rows.Err()
+}
@@ -0,0 +1,123 @@
+package model
+
+import (
+	"sort"
+	"time"
+)
+
+func (s *MemoryStore) GetTaskComments(ownerId uint16, taskId, after int64, limit int) (*TaskCommentPage, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if _, err := s.ownedTask(ownerId, taskId); err != nil {
+		return nil, err
+	}
+
+	page := &TaskCommentPage{Comments: []*TaskComment{}}
+	for _, comment := range s.comments {
+		if comment.TaskId != taskId {
+			continue
+		}
+
+		page.Total++
+
+		if comment.Id > after {
+			item := *comment
+			page.Comments = append(page.Comments, &item)
+		}
+	}
+
+	sort.Slice(page.Comments, func(i, j int) bool {
+		return page.Comments[i].Id < page.Comments[j].Id
+	})
+
+	if len(page.Comments) > limit+1 {
+		page.Comments = page.Comments[:limit+1]
+	}
+	page.paginate(limit)
+
+	return page, nil
+}
+
+func (s *MemoryStore) AddTaskComment(ownerId uint16, taskId int64, authorId uint16, body string) (*TaskComment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.ownedTask(ownerId, taskId); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+
+	s.lastCommentId++
+	comment := &TaskComment{
+		Id:        s.lastCommentId,
+		TaskId:    taskId,
+		AuthorId:  authorId,
+		Body:      body,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	s.comments[comment.Id] = comment
+
+	item := *comment
+	return &item, nil
+}
+
+// ownComment must be called with s.mu held
+func (s *MemoryStore) ownComment(ownerId uint16, taskId, id int64, authorId uint16) (*TaskComment, error) {
+	if _, err := s.ownedTask(ownerId, taskId); err != nil {
+		return nil, err
+	}
+
+	comment, ok := s.comments[id]
+	if !ok || comment.TaskId != taskId {
+		return nil, ErrCommentNotFound
+	}
+
+	if comment.AuthorId != authorId {
+		return nil, ErrCommentNotAuthor
+	}
+
+	return comment, nil
+}
+
+func (s *MemoryStore) EditTaskComment(ownerId uint16, taskId, id int64, authorId uint16, body string) (*TaskComment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	comment, err := s.ownComment(ownerId, taskId, id, authorId)
+	if err != nil {
+		return nil, err
+	}
+
+	comment.Body = body
+	comment.UpdatedAt = time.Now()
+
+	item := *comment
+	return &item, nil
+}
+
+func (s *MemoryStore) DeleteTaskComment(ownerId uint16, taskId, id int64, authorId uint16) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.ownComment(ownerId, taskId, id, authorId); err != nil {
+		return err
+	}
+
+	delete(s.comments, id)
+
+	return nil
+}
+
+// commentCount must be called with s.mu held
+func (s *MemoryStore) commentCount(task *Task) int {
+	count := 0
+	for _, comment := range s.comments {
+		if comment.TaskId == task.Id {
+			count++
+		}
+	}
+	return count
+}
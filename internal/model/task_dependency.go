@@ -0,0 +1,290 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"todo/internal/config"
+
+	"github.com/jackc/pgx/v4"
+)
+
+// TaskRef is a short reference to another task
+type TaskRef struct {
+	Id       int64  `json:"id"`
+	PublicId string `json:"public_id"`
+	Name     string `json:"name"`
+	Status   string `json:"status"`
+}
+
+var (
+	ErrTaskBlockerNotFound = errors.New("blocker task not found")
+	ErrTaskDependencyCycle = errors.New("task can not be blocked by itself or by a task it blocks")
+)
+
+// TaskBlockedError is returned when task is started while some of its blockers are not done
+type TaskBlockedError struct {
+	Blockers []int64 // ids of unfinished blockers
+}
+
+func (e *TaskBlockedError) Error() string {
+	return fmt.Sprintf("task is blocked by %d unfinished task(s)", len(e.Blockers))
+}
+
+// TaskDependencyStore methods are scoped to the owner the same way TaskStore ones are.
+// A blocker blocks the task until it is done, blockers in trash do not block
+type TaskDependencyStore interface {
+	// AddTaskBlocker does nothing if the blocker is already there
+	AddTaskBlocker(ownerId uint16, taskId, blockerId int64) error
+	// RemoveTaskBlocker does nothing if there is no such blocker
+	RemoveTaskBlocker(ownerId uint16, taskId, blockerId int64) error
+}
+
+// taskDependencyLock is advisory lock class serializing dependency changes of one owner,
+// so two concurrent inserts can not make a cycle each of them would not see alone
+const taskDependencyLock = 7405165
+
+// unfinishedBlocker is the condition of task_dependency row d whose blocker b still blocks the task
+const unfinishedBlocker = "b.status NOT IN ('" + StatusDone + "', '" + StatusDeleted + "')"
+
+// taskBlockedCondition is true for the task of the id column while it has unfinished blockers
+func taskBlockedCondition(column string) string {
+	return `EXISTS (
+		SELECT 1
+		FROM task_dependency d
+		JOIN task b ON b.id = d.blocker_id
+		WHERE d.task_id = ` + column + ` AND ` + unfinishedBlocker + `
+	)`
+}
+
+// unfinishedBlockers returns ids of blockers keeping the task from start
+func unfinishedBlockers(ctx context.Context, q querier, id int64) ([]int64, error) {
+	rows, err := q.Query(ctx, `
+		SELECT b.id
+		FROM task_dependency d
+		JOIN task b ON b.id = d.blocker_id
+		WHERE d.task_id = $1 AND `+unfinishedBlocker+`
+		ORDER BY b.id
+	`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []int64
+	for rows.Next() {
+		var blockerId int64
+
+		err = rows.Scan(&blockerId)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, blockerId)
+	}
+
+	return result, rows.Err()
+}
+
+// ownedTaskAndBlocker checks both tasks belong to the owner
+func ownedTaskAndBlocker(ctx context.Context, tx pgx.Tx, ownerId uint16, taskId, blockerId int64) error {
+	var taskFound, blockerFound bool
+
+	err := tx.QueryRow(ctx, `
+		SELECT
+			EXISTS (SELECT 1 FROM task WHERE id = $1 AND owner_id = $3),
+			EXISTS (SELECT 1 FROM task WHERE id = $2 AND owner_id = $3)
+	`, taskId, blockerId, ownerId).Scan(&taskFound, &blockerFound)
+	if err != nil {
+		return err
+	}
+
+	if !taskFound {
+		return ErrTaskNotFound
+	}
+	if !blockerFound {
+		return ErrTaskBlockerNotFound
+	}
+
+	return nil
+}
+
+func (s *PgStore) AddTaskBlocker(ownerId uint16, taskId, blockerId int64) error {
+	ctx := context.Background()
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, "SELECT pg_advisory_xact_lock($1, $2)", taskDependencyLock, int32(ownerId))
+	if err != nil {
+		return err
+	}
+
+	err = ownedTaskAndBlocker(ctx, tx, ownerId, taskId, blockerId)
+	if err != nil {
+		return err
+	}
+
+	if taskId == blockerId {
+		return ErrTaskDependencyCycle
+	}
+
+	// the new link makes a cycle if the task already blocks the blocker, directly or not
+	var cycle bool
+	err = tx.QueryRow(ctx, `
+		WITH RECURSIVE dependents AS (
+			SELECT task_id
+			FROM task_dependency
+			WHERE blocker_id = $1
+			UNION
+			SELECT d.task_id
+			FROM task_dependency d
+			JOIN dependents ON d.blocker_id = dependents.task_id
+		)
+		SELECT EXISTS (SELECT 1 FROM dependents WHERE task_id = $2)
+	`, taskId, blockerId).Scan(&cycle)
+	if err != nil {
+		return err
+	}
+
+	if cycle {
+		return ErrTaskDependencyCycle
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO task_dependency(task_id, blocker_id)
+		VALUES ($1, $2)
+		ON CONFLICT DO NOTHING`,
+		taskId,
+		blockerId,
+	)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return err
+	}
+
+	if config.DebugLog() {
+		log.Println("add task blocker: successfully created data in db")
+	}
+
+	return nil
+}
+
+func (s *PgStore) RemoveTaskBlocker(ownerId uint16, taskId, blockerId int64) error {
+	ctx := context.Background()
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	err = ownedTaskAndBlocker(ctx, tx, ownerId, taskId, blockerId)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `
+		DELETE FROM task_dependency
+		WHERE task_id = $1 AND blocker_id = $2`,
+		taskId,
+		blockerId,
+	)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return err
+	}
+
+	if config.DebugLog() {
+		log.Println("remove task blocker: successfully deleted data in db")
+	}
+
+	return nil
+}
+
+// loadTaskBlocked marks tasks having unfinished blockers by a single query
+func (s *PgStore) loadTaskBlocked(ctx context.Context, tasks []*Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	byId := make(map[int64]*Task, len(tasks))
+	ids := make([]int64, 0, len(tasks))
+	for _, task := range tasks {
+		byId[task.Id] = task
+		ids = append(ids, task.Id)
+	}
+
+	rows, err := s.pool.Query(ctx, `
+		SELECT DISTINCT d.task_id
+		FROM task_dependency d
+		JOIN task b ON b.id = d.blocker_id
+		WHERE d.task_id = ANY($1) AND `+unfinishedBlocker,
+		ids,
+	)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var taskId int64
+
+		err = rows.Scan(&taskId)
+		if err != nil {
+			return err
+		}
+
+		byId[taskId].Blocked = true
+	}
+
+	return rows.Err()
+}
+
+// loadTaskDependencies fills blockers and dependents of the task
+func (s *PgStore) loadTaskDependencies(ctx context.Context, task *Task) error {
+	rows, err := s.pool.Query(ctx, `
+		SELECT d.blocker_id = $1, t.id, t.public_id, t.name, t.status
+		FROM task_dependency d
+		JOIN task t ON t.id = CASE WHEN d.blocker_id = $1 THEN d.task_id ELSE d.blocker_id END
+		WHERE d.task_id = $1 OR d.blocker_id = $1
+		ORDER BY t.id
+	`, task.Id)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	task.Blockers = []*TaskRef{}
+	task.Dependents = []*TaskRef{}
+
+	for rows.Next() {
+		var dependent bool
+		var item TaskRef
+
+		err = rows.Scan(&dependent, &item.Id, &item.PublicId, &item.Name, &item.Status)
+		if err != nil {
+			return err
+		}
+
+		if dependent {
+			task.Dependents = append(task.Dependents, &item)
+		} else {
+			task.Blockers = append(task.Blockers, &item)
+		}
+	}
+
+	return rows.Err()
+}
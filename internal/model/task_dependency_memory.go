@@ -0,0 +1,121 @@
+package model
+
+import "sort"
+
+func (s *MemoryStore) AddTaskBlocker(ownerId uint16, taskId, blockerId int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.ownedTask(ownerId, taskId); err != nil {
+		return err
+	}
+	if _, err := s.ownedTask(ownerId, blockerId); err != nil {
+		return ErrTaskBlockerNotFound
+	}
+
+	// the new link makes a cycle if the task already blocks the blocker, directly or not
+	visited := map[int64]bool{}
+	queue := []int64{taskId}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+
+		if id == blockerId {
+			return ErrTaskDependencyCycle
+		}
+		if visited[id] {
+			continue
+		}
+		visited[id] = true
+
+		for dependentId, blockers := range s.taskBlockers {
+			if blockers[id] {
+				queue = append(queue, dependentId)
+			}
+		}
+	}
+
+	if s.taskBlockers[taskId] == nil {
+		s.taskBlockers[taskId] = map[int64]bool{}
+	}
+	s.taskBlockers[taskId][blockerId] = true
+
+	return nil
+}
+
+func (s *MemoryStore) RemoveTaskBlocker(ownerId uint16, taskId, blockerId int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.ownedTask(ownerId, taskId); err != nil {
+		return err
+	}
+	if _, err := s.ownedTask(ownerId, blockerId); err != nil {
+		return ErrTaskBlockerNotFound
+	}
+
+	delete(s.taskBlockers[taskId], blockerId)
+
+	return nil
+}
+
+// unfinishedBlockers returns ids of blockers keeping the task from start, must be called with s.mu held
+func (s *MemoryStore) unfinishedBlockers(task *Task) []int64 {
+	var result []int64
+	for blockerId := range s.taskBlockers[task.Id] {
+		status := s.tasks[blockerId].Status
+		if status != StatusDone && status != StatusDeleted {
+			result = append(result, blockerId)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i] < result[j]
+	})
+
+	return result
+}
+
+// taskDependencies returns blockers and dependents of the task ordered by id, must be called with s.mu held
+func (s *MemoryStore) taskDependencies(task *Task) ([]*TaskRef, []*TaskRef) {
+	blockers := []*TaskRef{}
+	dependents := []*TaskRef{}
+
+	for dependentId, blockerIds := range s.taskBlockers {
+		if dependentId == task.Id {
+			for blockerId := range blockerIds {
+				blockers = append(blockers, s.tasks[blockerId].ref())
+			}
+		} else if blockerIds[task.Id] {
+			dependents = append(dependents, s.tasks[dependentId].ref())
+		}
+	}
+
+	sortTaskRefs(blockers)
+	sortTaskRefs(dependents)
+
+	return blockers, dependents
+}
+
+func sortTaskRefs(list []*TaskRef) {
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Id < list[j].Id
+	})
+}
+
+// removeTaskDependencies must be called with s.mu held
+func (s *MemoryStore) removeTaskDependencies(id int64) {
+	delete(s.taskBlockers, id)
+	for _, blockers := range s.taskBlockers {
+		delete(blockers, id)
+	}
+}
+
+func (t *Task) ref() *TaskRef {
+	return &TaskRef{
+		Id:       t.Id,
+		PublicId: t.PublicId,
+		Name:     t.Name,
+		Status:   t.Status,
+	}
+}
@@ -0,0 +1,280 @@
+package model
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+	"todo/internal/config"
+)
+
+const (
+	DefaultTaskListLimit = 50
+	MaxTaskListLimit     = 500
+)
+
+// TaskSortColumns maps sort names accepted by GetTaskList to task columns or expressions
+var TaskSortColumns = map[string]string{
+	"id":         "id",
+	"name":       "name",
+	"status":     "status",
+	"created_at": "created_at",
+	"updated_at": "updated_at",
+	"due_at":     "COALESCE(due_at, 'infinity')", // tasks without due date go last
+	"priority":   "priority",
+	"position":   "position",
+}
+
+// cursorTimeLayout keeps timestamp cursor values fixed width, so they compare as strings
+const cursorTimeLayout = "2006-01-02T15:04:05.000000000Z07:00"
+
+// cursorNoTime is cursor value of a missing timestamp, it is greater than any formatted one
+// the same way postgres 'infinity' is
+const cursorNoTime = "infinity"
+
+func timeCursorValue(t *time.Time) string {
+	if t == nil {
+		return cursorNoTime
+	}
+	return t.UTC().Format(cursorTimeLayout)
+}
+
+// TaskCursor points to the last task of the previous page
+type TaskCursor struct {
+	Id    int64  `json:"id"`
+	Value string `json:"v,omitempty"` // value of the sort column, empty when sorted by id
+}
+
+type TaskListFilter struct {
+	Statuses  []string // empty means every status except deleted
+	Search    string   // substring of name or description, case insensitive
+	DueBefore *time.Time
+	Overdue   bool     // due date has passed and task is not done
+	Blocked   bool     // some blocker of the task is not done
+	Labels    []string // label names, distinct
+	AllLabels bool     // task must have all Labels instead of any of them
+	Tree      bool     // only top level tasks are filtered, each one with all its descendants
+	ProjectId *int64   // only tasks of the project
+	Assignee  uint16   // only tasks assigned to the user, 0 means any
+	Sort      string   // one of TaskSortColumns keys
+	Desc      bool
+	Limit     int
+	After     *TaskCursor
+}
+
+type TaskPage struct {
+	Tasks      []*Task     `json:"data"`
+	Total      int         `json:"total"` // count of tasks matching filter on all pages
+	NextCursor string      `json:"next_cursor,omitempty"`
+	Next       *TaskCursor `json:"-"` // nil on the last page
+}
+
+// cursor returns position of the task in the list sorted by sort column
+func (t *Task) cursor(sort string) TaskCursor {
+	cursor := TaskCursor{Id: t.Id}
+
+	switch sort {
+	case "name":
+		cursor.Value = t.Name
+	case "status":
+		cursor.Value = t.Status
+	case "priority":
+		cursor.Value = strconv.Itoa(int(priorityRank(t.Priority)))
+	case "position":
+		cursor.Value = t.Position
+	case "created_at":
+		cursor.Value = timeCursorValue(&t.CreatedAt)
+	case "updated_at":
+		cursor.Value = timeCursorValue(&t.UpdatedAt)
+	case "due_at":
+		cursor.Value = timeCursorValue(t.DueAt)
+	}
+
+	return cursor
+}
+
+// Valid reports whether the cursor value can be compared with the sort column
+func (c *TaskCursor) Valid(sort string) bool {
+	if strings.HasSuffix(sort, "_at") {
+		if sort == "due_at" && c.Value == cursorNoTime {
+			return true
+		}
+		_, err := time.Parse(cursorTimeLayout, c.Value)
+		return err == nil
+	}
+	if sort == "priority" {
+		rank, err := strconv.Atoi(c.Value)
+		return err == nil && rank >= 0 && rank < len(priorities)
+	}
+	return true
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+func (s *PgStore) GetTaskList(ownerId uint16, filter TaskListFilter) (*TaskPage, error) {
+	var args []interface{}
+	arg := func(value interface{}) string {
+		args = append(args, value)
+		return "$" + strconv.Itoa(len(args))
+	}
+
+	owner := arg(ownerId)
+	where := []string{"owner_id = " + owner}
+
+	if len(filter.Statuses) > 0 {
+		var placeholders []string
+		for _, status := range filter.Statuses {
+			placeholders = append(placeholders, arg(status))
+		}
+		where = append(where, "status IN ("+strings.Join(placeholders, ", ")+")")
+	} else {
+		where = append(where, "status <> "+arg(StatusDeleted)) // by default show all and ignore deleted
+	}
+
+	if len(filter.Search) > 0 {
+		pattern := arg("%" + escapeLike(filter.Search) + "%")
+		where = append(where, "(name ILIKE "+pattern+" OR description ILIKE "+pattern+")")
+	}
+
+	if filter.DueBefore != nil {
+		where = append(where, "due_at < "+arg(*filter.DueBefore))
+	}
+
+	if len(filter.Labels) > 0 {
+		var placeholders []string
+		for _, label := range filter.Labels {
+			placeholders = append(placeholders, arg(label))
+		}
+
+		labeled := `id IN (
+			SELECT tl.task_id
+			FROM task_label tl
+			JOIN label l ON l.id = tl.label_id
+			WHERE l.owner_id = ` + owner + ` AND l.name IN (` + strings.Join(placeholders, ", ") + `)`
+		if filter.AllLabels {
+			labeled += " GROUP BY tl.task_id HAVING COUNT(*) = " + arg(len(filter.Labels))
+		}
+		where = append(where, labeled+")")
+	}
+
+	if filter.Tree {
+		where = append(where, "parent_id IS NULL")
+	}
+
+	if filter.ProjectId != nil {
+		where = append(where, "project_id = "+arg(*filter.ProjectId))
+	}
+
+	if filter.Assignee != 0 {
+		where = append(where, "EXISTS (SELECT 1 FROM task_assignee WHERE task_id = task.id AND user_id = "+arg(int32(filter.Assignee))+")")
+	}
+
+	if filter.Overdue {
+		where = append(where, "due_at < now() AND status NOT IN ("+arg(StatusDone)+", "+arg(StatusDeleted)+")")
+	}
+
+	if filter.Blocked {
+		where = append(where, taskBlockedCondition("task.id"))
+	}
+
+	page := &TaskPage{Tasks: []*Task{}}
+
+	err := s.pool.QueryRow(context.Background(), `
+		SELECT COUNT(*)
+		FROM task
+		WHERE `+strings.Join(where, " AND "),
+		args...,
+	).Scan(&page.Total)
+	if err != nil {
+		return nil, err
+	}
+
+	column, ok := TaskSortColumns[filter.Sort]
+	if !ok {
+		column = "id"
+	}
+
+	direction, comparison := "ASC", ">"
+	if filter.Desc {
+		direction, comparison = "DESC", "<"
+	}
+
+	if filter.After != nil {
+		if column == "id" {
+			where = append(where, "id "+comparison+" "+arg(filter.After.Id))
+		} else {
+			value := arg(filter.After.Value)
+			if strings.HasSuffix(filter.Sort, "_at") {
+				value += "::timestamptz"
+			}
+			where = append(where, "("+column+", id) "+comparison+" ("+value+", "+arg(filter.After.Id)+")")
+		}
+	}
+
+	orderBy := "id " + direction
+	if column != "id" {
+		orderBy = column + " " + direction + ", " + orderBy
+	}
+
+	// one extra row tells whether there is a next page
+	rows, err := s.pool.Query(context.Background(), `
+		SELECT `+taskColumns+`
+		FROM task
+		WHERE `+strings.Join(where, " AND ")+`
+		ORDER BY `+orderBy+`
+		LIMIT `+arg(filter.Limit+1),
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		item, err := scanTask(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		page.Tasks = append(page.Tasks, item)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	page.paginate(filter)
+
+	err = s.loadTaskDetails(context.Background(), page.Tasks)
+	if err != nil {
+		return nil, err
+	}
+
+	if filter.Tree {
+		err = s.loadTaskSubtrees(context.Background(), page.Tasks)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if config.DebugLog() {
+		log.Println("task list offset: successfully retrived data from db")
+	}
+
+	return page, nil
+}
+
+// paginate cuts the extra task fetched over the limit and points Next to the last task of the page
+func (page *TaskPage) paginate(filter TaskListFilter) {
+	if len(page.Tasks) <= filter.Limit {
+		return
+	}
+
+	page.Tasks = page.Tasks[:filter.Limit]
+
+	next := page.Tasks[len(page.Tasks)-1].cursor(filter.Sort)
+	page.Next = &next
+}
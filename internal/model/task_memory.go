@@ -0,0 +1,693 @@
+package model
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// compareCursors orders tasks the same way as ORDER BY column, id does
+func compareCursors(a, b TaskCursor, sort string) int {
+	if sort != "id" {
+		if c := strings.Compare(a.Value, b.Value); c != 0 {
+			return c
+		}
+	}
+
+	switch {
+	case a.Id < b.Id:
+		return -1
+	case a.Id > b.Id:
+		return 1
+	}
+	return 0
+}
+
+func (s *MemoryStore) GetTaskList(ownerId uint16, filter TaskListFilter) (*TaskPage, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	sortBy := filter.Sort
+	if _, ok := TaskSortColumns[sortBy]; !ok {
+		sortBy = "id"
+	}
+
+	search := strings.ToLower(filter.Search)
+
+	page := &TaskPage{Tasks: []*Task{}}
+
+	for _, task := range s.tasks {
+		if task.OwnerId != ownerId {
+			continue
+		}
+
+		if len(filter.Statuses) > 0 {
+			if !containsString(filter.Statuses, task.Status) {
+				continue
+			}
+		} else if task.Status == StatusDeleted { // by default show all and ignore deleted
+			continue
+		}
+
+		if len(search) > 0 &&
+			!strings.Contains(strings.ToLower(task.Name), search) &&
+			!strings.Contains(strings.ToLower(task.Description), search) {
+			continue
+		}
+
+		if filter.DueBefore != nil && (task.DueAt == nil || !task.DueAt.Before(*filter.DueBefore)) {
+			continue
+		}
+
+		if filter.Overdue && (task.DueAt == nil || !task.DueAt.Before(time.Now()) ||
+			task.Status == StatusDone || task.Status == StatusDeleted) {
+			continue
+		}
+
+		if filter.Blocked && len(s.unfinishedBlockers(task)) == 0 {
+			continue
+		}
+
+		if filter.Tree && task.ParentId != nil {
+			continue
+		}
+
+		if filter.ProjectId != nil && (task.ProjectId == nil || *task.ProjectId != *filter.ProjectId) {
+			continue
+		}
+
+		if filter.Assignee != 0 && !s.taskAssignees[task.Id][filter.Assignee] {
+			continue
+		}
+
+		if len(filter.Labels) > 0 && !s.hasLabels(task, filter.Labels, filter.AllLabels) {
+			continue
+		}
+
+		page.Total++
+
+		if filter.After != nil {
+			c := compareCursors(task.cursor(sortBy), *filter.After, sortBy)
+			if (!filter.Desc && c <= 0) || (filter.Desc && c >= 0) {
+				continue
+			}
+		}
+
+		if filter.Tree {
+			page.Tasks = append(page.Tasks, s.taskTree(task))
+		} else {
+			page.Tasks = append(page.Tasks, s.taskCopy(task))
+		}
+	}
+
+	sort.Slice(page.Tasks, func(i, j int) bool {
+		c := compareCursors(page.Tasks[i].cursor(sortBy), page.Tasks[j].cursor(sortBy), sortBy)
+		if filter.Desc {
+			return c > 0
+		}
+		return c < 0
+	})
+
+	if len(page.Tasks) > filter.Limit+1 {
+		page.Tasks = page.Tasks[:filter.Limit+1]
+	}
+	page.paginate(filter)
+
+	return page, nil
+}
+
+// taskCopy returns a copy of the task with its labels, progress, blocked flag and comment count, must be called with s.mu held
+func (s *MemoryStore) taskCopy(task *Task) *Task {
+	item := *task
+
+	item.Labels = []*Label{}
+	for labelId := range s.taskLabels[task.Id] {
+		label := s.labels[labelId].Label
+		item.Labels = append(item.Labels, &label)
+	}
+	sortLabels(item.Labels)
+
+	item.Assignees = s.assignees(task.Id)
+
+	item.Progress = s.taskProgress(task)
+	item.Children = nil
+	item.Blocked = len(s.unfinishedBlockers(task)) > 0
+	item.CommentCount = s.commentCount(task)
+	item.Checklist = s.checklistProgress(task.Id)
+	item.Blockers = nil
+	item.Dependents = nil
+
+	return &item
+}
+
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *MemoryStore) GetStatusList() ([]*Status, error) {
+	var result []*Status = []*Status{}
+	for _, status := range statuses {
+		result = append(result, &Status{Name: status})
+	}
+
+	return result, nil
+}
+
+func (s *MemoryStore) CreateTask(ownerId, actorId uint16, fields TaskFields) (*Task, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if fields.ParentId != nil {
+		if err := s.checkTaskParent(ownerId, 0, *fields.ParentId); err != nil {
+			return nil, err
+		}
+	}
+
+	if fields.ProjectId != nil {
+		if err := s.checkTaskProject(ownerId, 0, *fields.ProjectId); err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+
+	position, _ := positionBetween(s.lastTaskPosition(ownerId, StatusCreated, 0), "")
+
+	s.lastTaskId++
+	task := &Task{
+		Id:          s.lastTaskId,
+		PublicId:    uuid.NewString(),
+		OwnerId:     ownerId,
+		Name:        fields.Name,
+		Description: fields.Description,
+		Status:      StatusCreated,
+		Priority:    priorityName(priorityRank(fields.Priority)),
+		Position:    position,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+		DueAt:       fields.DueAt,
+		DueTimezone: fields.DueTimezone,
+		Recurrence:  fields.Recurrence,
+		ParentId:    fields.ParentId,
+		ProjectId:   fields.ProjectId,
+
+		EstimateMinutes: fields.EstimateMinutes,
+	}
+	s.tasks[task.Id] = task
+	s.addTaskHistory(task.Id, "", task.Status, actorId, now)
+
+	return s.taskCopy(task), nil
+}
+
+func (s *MemoryStore) GetTaskIdByPublicId(ownerId uint16, publicId string) (int64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, task := range s.tasks {
+		if task.PublicId == publicId && task.OwnerId == ownerId {
+			return task.Id, nil
+		}
+	}
+
+	return 0, ErrTaskNotFound
+}
+
+// ownedTask must be called with s.mu held
+func (s *MemoryStore) ownedTask(ownerId uint16, id int64) (*Task, error) {
+	task, ok := s.tasks[id]
+	if !ok || task.OwnerId != ownerId {
+		return nil, ErrTaskNotFound
+	}
+
+	return task, nil
+}
+
+func (s *MemoryStore) GetTask(ownerId uint16, id int64) (*Task, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	task, err := s.ownedTask(ownerId, id)
+	if err != nil {
+		return nil, err
+	}
+
+	item := s.taskCopy(task)
+	item.Blockers, item.Dependents = s.taskDependencies(task)
+
+	return item, nil
+}
+
+func (s *MemoryStore) EditTask(ownerId uint16, id int64, edit TaskEditFunc) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	task, err := s.ownedTask(ownerId, id)
+	if err != nil {
+		return err
+	}
+
+	fields, err := edit(s.taskCopy(task))
+	if err != nil {
+		return err
+	}
+
+	if fields.ParentId != nil {
+		if err := s.checkTaskParent(ownerId, id, *fields.ParentId); err != nil {
+			return err
+		}
+	}
+
+	if fields.ProjectId != nil {
+		if err := s.checkTaskProject(ownerId, id, *fields.ProjectId); err != nil {
+			return err
+		}
+	}
+
+	if !sameTime(task.DueAt, fields.DueAt) {
+		task.remindedAt = nil
+	}
+
+	task.Name = fields.Name
+	task.Description = fields.Description
+	task.Priority = priorityName(priorityRank(fields.Priority))
+	task.DueAt = fields.DueAt
+	task.DueTimezone = fields.DueTimezone
+	task.Recurrence = fields.Recurrence
+	task.ParentId = fields.ParentId
+	task.ProjectId = fields.ProjectId
+	task.EstimateMinutes = fields.EstimateMinutes
+	task.UpdatedAt = time.Now()
+
+	return nil
+}
+
+func (s *MemoryStore) setTaskStatus(ownerId uint16, id int64, actorId uint16, status string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	task, err := s.ownedTask(ownerId, id)
+	if err != nil {
+		return err
+	}
+
+	// trash is left only by RestoreTask
+	if task.Status == StatusDeleted || !CanChangeStatus(task.Status, status) {
+		return &StatusTransitionError{
+			Current:   task.Status,
+			Requested: status,
+		}
+	}
+
+	if status == StatusInProgress {
+		if blockers := s.unfinishedBlockers(task); len(blockers) > 0 {
+			return &TaskBlockedError{Blockers: blockers}
+		}
+	}
+
+	if status == StatusDone {
+		if err := s.checklistComplete(task.Id); err != nil {
+			return err
+		}
+	}
+
+	// the task goes to the end of its new column
+	position, err := positionBetween(s.lastTaskPosition(ownerId, status, task.Id), "")
+	if err != nil {
+		return err
+	}
+	task.Position = position
+
+	now := time.Now()
+	s.changeTaskStatus(task, status, actorId, now)
+
+	if status == StatusInProgress {
+		s.autoAssign(task.Id, actorId)
+	}
+
+	if status == StatusDone {
+		return s.createNextOccurrence(task, now)
+	}
+
+	return nil
+}
+
+// changeTaskStatus sets status with its timestamps and writes history, must be called with s.mu held
+func (s *MemoryStore) changeTaskStatus(task *Task, status string, changedBy uint16, now time.Time) {
+	switch status {
+	case StatusInProgress:
+		if task.StartedAt == nil {
+			task.StartedAt = &now
+		}
+	case StatusDone:
+		task.CompletedAt = &now
+	case StatusDeleted:
+		task.statusBeforeDelete = task.Status
+		task.DeletedAt = &now
+	}
+
+	if task.Status == StatusDeleted {
+		task.statusBeforeDelete = ""
+		task.DeletedAt = nil
+	}
+
+	s.addTaskHistory(task.Id, task.Status, status, changedBy, now)
+	s.trackTaskTime(task.Id, changedBy, status, now)
+	task.Status = status
+	task.UpdatedAt = now
+}
+
+// addTaskHistory must be called with s.mu held
+func (s *MemoryStore) addTaskHistory(id int64, from, to string, changedBy uint16, at time.Time) {
+	s.taskHistory[id] = append(s.taskHistory[id], &TaskStatusChange{
+		FromStatus: from,
+		ToStatus:   to,
+		ChangedBy:  changedBy,
+		ChangedAt:  at,
+	})
+}
+
+func (s *MemoryStore) GetTaskStatusHistory(ownerId uint16, id int64) ([]*TaskStatusChange, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if _, err := s.ownedTask(ownerId, id); err != nil {
+		return nil, err
+	}
+
+	var result []*TaskStatusChange = []*TaskStatusChange{}
+	for _, change := range s.taskHistory[id] {
+		item := *change
+		result = append(result, &item)
+	}
+
+	return result, nil
+}
+
+func (s *MemoryStore) StartTaskProgress(ownerId uint16, id int64, actorId uint16) error {
+	return s.setTaskStatus(ownerId, id, actorId, StatusInProgress)
+}
+
+func (s *MemoryStore) PauseTask(ownerId uint16, id int64, actorId uint16) error {
+	return s.setTaskStatus(ownerId, id, actorId, StatusPaused)
+}
+
+func (s *MemoryStore) DoneTask(ownerId uint16, id int64, actorId uint16) error {
+	return s.setTaskStatus(ownerId, id, actorId, StatusDone)
+}
+
+func (s *MemoryStore) DeleteTask(ownerId uint16, id int64, actorId uint16) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	task, err := s.ownedTask(ownerId, id)
+	if err != nil {
+		return err
+	}
+
+	if !CanChangeStatus(task.Status, StatusDeleted) {
+		return &StatusTransitionError{
+			Current:   task.Status,
+			Requested: StatusDeleted,
+		}
+	}
+
+	now := time.Now()
+	for _, item := range s.subtree(task, false) {
+		s.changeTaskStatus(item, StatusDeleted, actorId, now)
+		item.deletedWith = id
+	}
+
+	return nil
+}
+
+func (s *MemoryStore) RestoreTask(ownerId uint16, id int64, actorId uint16) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	task, err := s.ownedTask(ownerId, id)
+	if err != nil {
+		return err
+	}
+
+	status := task.statusBeforeDelete
+	if status == "" {
+		status = StatusCreated
+	}
+
+	if task.Status != StatusDeleted {
+		return &StatusTransitionError{
+			Current:   task.Status,
+			Requested: status,
+		}
+	}
+
+	if task.ParentId != nil {
+		if parent, ok := s.tasks[*task.ParentId]; ok && parent.Status == StatusDeleted {
+			return ErrTaskParentDeleted
+		}
+	}
+
+	now := time.Now()
+	for _, item := range s.tasks {
+		if item.OwnerId != ownerId || item.Status != StatusDeleted || (item.Id != id && item.deletedWith != id) {
+			continue
+		}
+
+		status := item.statusBeforeDelete
+		if status == "" {
+			status = StatusCreated
+		}
+
+		s.changeTaskStatus(item, status, actorId, now)
+		item.deletedWith = 0
+	}
+
+	return nil
+}
+
+// deleteTask removes task with everything it owns and returns blob keys of its attachments,
+// must be called with s.mu held
+func (s *MemoryStore) deleteTask(id int64) []string {
+	delete(s.tasks, id)
+	delete(s.taskHistory, id)
+	delete(s.taskLabels, id)
+	delete(s.taskAssignees, id)
+	s.removeTaskDependencies(id)
+
+	for commentId, comment := range s.comments {
+		if comment.TaskId == id {
+			delete(s.comments, commentId)
+		}
+	}
+
+	for itemId, item := range s.checklistItems {
+		if item.TaskId == id {
+			delete(s.checklistItems, itemId)
+		}
+	}
+
+	for entryId, entry := range s.timeEntries {
+		if entry.TaskId == id {
+			delete(s.timeEntries, entryId)
+		}
+	}
+
+	return s.removeTaskAttachments(id)
+}
+
+func (s *MemoryStore) DeleteTaskCompletely(ownerId uint16, id int64) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	task, err := s.ownedTask(ownerId, id)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := []string{}
+	for _, item := range s.subtree(task, true) {
+		keys = append(keys, s.deleteTask(item.Id)...)
+	}
+
+	return keys, nil
+}
+
+func (s *MemoryStore) FreeTaskTrash(ownerId uint16, projectId *int64) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var trash []*Task
+	for _, task := range s.tasks {
+		if task.OwnerId != ownerId || task.Status != StatusDeleted {
+			continue
+		}
+		if projectId != nil && (task.ProjectId == nil || *task.ProjectId != *projectId) {
+			continue
+		}
+		trash = append(trash, s.subtree(task, true)...)
+	}
+
+	if projectId != nil {
+		for _, task := range trash {
+			if task.ProjectId == nil || *task.ProjectId != *projectId {
+				return nil, ErrTrashSubtaskOutsideProject
+			}
+		}
+	}
+
+	keys := []string{}
+	for _, task := range trash {
+		keys = append(keys, s.deleteTask(task.Id)...)
+	}
+
+	return keys, nil
+}
+
+// lastTaskPosition must be called with s.mu held
+func (s *MemoryStore) lastTaskPosition(ownerId uint16, status string, exceptId int64) string {
+	var last *Task
+	for _, task := range s.tasks {
+		if task.OwnerId != ownerId || task.Status != status || task.Id == exceptId {
+			continue
+		}
+		if last == nil || compareCursors(task.cursor("position"), last.cursor("position"), "position") > 0 {
+			last = task
+		}
+	}
+
+	if last == nil {
+		return ""
+	}
+	return last.Position
+}
+
+func (s *MemoryStore) MoveTask(ownerId uint16, id int64, actorId uint16, move TaskMove) (*Task, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	task, err := s.ownedTask(ownerId, id)
+	if err != nil {
+		return nil, err
+	}
+
+	status := move.Status
+	if status == "" {
+		status = task.Status
+	}
+
+	// trash is changed only by DeleteTask and RestoreTask, they take care of descendants
+	if task.Status == StatusDeleted || status == StatusDeleted || (status != task.Status && !CanChangeStatus(task.Status, status)) {
+		return nil, &StatusTransitionError{
+			Current:   task.Status,
+			Requested: status,
+		}
+	}
+
+	if status == StatusDone && task.Status != StatusDone {
+		if err := s.checklistComplete(task.Id); err != nil {
+			return nil, err
+		}
+	}
+
+	if status == StatusInProgress && task.Status != StatusInProgress {
+		if blockers := s.unfinishedBlockers(task); len(blockers) > 0 {
+			return nil, &TaskBlockedError{Blockers: blockers}
+		}
+	}
+
+	neighbour := func(neighbourId int64) (*Task, error) {
+		item, ok := s.tasks[neighbourId]
+		if !ok || item.OwnerId != ownerId || item.Status != status || item.Id == id {
+			return nil, ErrTaskNeighbourInvalid
+		}
+		return item, nil
+	}
+
+	// adjacent returns position of the task next to the neighbour in the column, direction is 1 or -1
+	adjacent := func(of *Task, direction int) string {
+		var next *Task
+		for _, item := range s.tasks {
+			if item.OwnerId != ownerId || item.Status != status || item.Id == id {
+				continue
+			}
+			if compareCursors(item.cursor("position"), of.cursor("position"), "position")*direction <= 0 {
+				continue
+			}
+			if next == nil || compareCursors(item.cursor("position"), next.cursor("position"), "position")*direction < 0 {
+				next = item
+			}
+		}
+
+		if next == nil {
+			return ""
+		}
+		return next.Position
+	}
+
+	var lower, upper string
+
+	if move.After != 0 {
+		after, err := neighbour(move.After)
+		if err != nil {
+			return nil, err
+		}
+		lower = after.Position
+		if move.Before == 0 {
+			upper = adjacent(after, 1)
+		}
+	}
+
+	if move.Before != 0 {
+		before, err := neighbour(move.Before)
+		if err != nil {
+			return nil, err
+		}
+		upper = before.Position
+		if move.After == 0 {
+			lower = adjacent(before, -1)
+		}
+	}
+
+	if move.After == 0 && move.Before == 0 {
+		lower = s.lastTaskPosition(ownerId, status, id)
+	}
+
+	position, err := movePosition(lower, upper, move)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+
+	if status != task.Status {
+		s.changeTaskStatus(task, status, actorId, now)
+
+		if status == StatusInProgress {
+			s.autoAssign(task.Id, actorId)
+		}
+
+		if status == StatusDone {
+			if err := s.createNextOccurrence(task, now); err != nil {
+				return nil, err
+			}
+		}
+	}
+	task.Position = position
+	task.UpdatedAt = now
+
+	return s.taskCopy(task), nil
+}
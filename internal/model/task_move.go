@@ -0,0 +1,240 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"log"
+	"todo/internal/config"
+
+	"github.com/jackc/pgx/v4"
+)
+
+// TaskMove places task into the status column between its neighbours,
+// without neighbours task goes to the end of the column
+type TaskMove struct {
+	Status string // empty keeps the current status
+	After  int64  // id of the task to place after, 0 means none
+	Before int64  // id of the task to place before, 0 means none
+}
+
+// ErrTaskNeighbourInvalid is returned when a neighbour of MoveTask is missing, is in another status
+// or neighbours are in the wrong order
+var ErrTaskNeighbourInvalid = errors.New("neighbour task is missing or is in another status")
+
+// lastTaskPosition returns the greatest position in the status column, empty if column is empty
+func lastTaskPosition(ctx context.Context, q querier, ownerId uint16, status string, exceptId int64) (string, error) {
+	var position string
+
+	err := q.QueryRow(ctx, `
+		SELECT position
+		FROM task
+		WHERE owner_id = $1 AND status = $2 AND id <> $3
+		ORDER BY position DESC, id DESC
+		LIMIT 1
+	`, ownerId, status, exceptId).Scan(&position)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", nil
+	}
+
+	return position, err
+}
+
+// movePosition returns position between neighbours of the move
+func movePosition(lower, upper string, move TaskMove) (string, error) {
+	if upper != "" && lower >= upper {
+		if move.After != 0 && move.Before != 0 {
+			return "", ErrTaskNeighbourInvalid
+		}
+		upper = "" // neighbours share the key after concurrent moves, there is nothing between them
+	}
+
+	return positionBetween(lower, upper)
+}
+
+func (s *PgStore) MoveTask(ownerId uint16, id int64, actorId uint16, move TaskMove) (*Task, error) {
+	ctx := context.Background()
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	var current string
+	err = tx.QueryRow(ctx, `
+		SELECT status
+		FROM task
+		WHERE id = $1 AND owner_id = $2
+		FOR UPDATE
+	`, id, ownerId).Scan(&current)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrTaskNotFound
+		}
+		return nil, err
+	}
+
+	status := move.Status
+	if status == "" {
+		status = current
+	}
+
+	// trash is changed only by DeleteTask and RestoreTask, they take care of descendants
+	if current == StatusDeleted || status == StatusDeleted || (status != current && !CanChangeStatus(current, status)) {
+		return nil, &StatusTransitionError{
+			Current:   current,
+			Requested: status,
+		}
+	}
+
+	if status == StatusDone && current != StatusDone {
+		err = checklistComplete(ctx, tx, id)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if status == StatusInProgress && current != StatusInProgress {
+		blockers, err := unfinishedBlockers(ctx, tx, id)
+		if err != nil {
+			return nil, err
+		}
+		if len(blockers) > 0 {
+			return nil, &TaskBlockedError{Blockers: blockers}
+		}
+	}
+
+	neighbour := func(neighbourId int64) (string, error) {
+		var position string
+
+		err := tx.QueryRow(ctx, `
+			SELECT position
+			FROM task
+			WHERE id = $1 AND owner_id = $2 AND status = $3 AND id <> $4
+		`, neighbourId, ownerId, status, id).Scan(&position)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", ErrTaskNeighbourInvalid
+		}
+
+		return position, err
+	}
+
+	// adjacent returns position of the task next to the neighbour in the column
+	adjacent := func(position string, neighbourId int64, comparison, direction string) (string, error) {
+		var result string
+
+		err := tx.QueryRow(ctx, `
+			SELECT position
+			FROM task
+			WHERE owner_id = $1 AND status = $2 AND id <> $3 AND (position, id) `+comparison+` ($4, $5)
+			ORDER BY position `+direction+`, id `+direction+`
+			LIMIT 1
+		`, ownerId, status, id, position, neighbourId).Scan(&result)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", nil
+		}
+
+		return result, err
+	}
+
+	var lower, upper string
+
+	if move.After != 0 {
+		lower, err = neighbour(move.After)
+		if err == nil && move.Before == 0 {
+			upper, err = adjacent(lower, move.After, ">", "ASC")
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if move.Before != 0 {
+		upper, err = neighbour(move.Before)
+		if err == nil && move.After == 0 {
+			lower, err = adjacent(upper, move.Before, "<", "DESC")
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if move.After == 0 && move.Before == 0 {
+		lower, err = lastTaskPosition(ctx, tx, ownerId, status, id)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	position, err := movePosition(lower, upper, move)
+	if err != nil {
+		return nil, err
+	}
+
+	set := "status = $1, position = $2"
+	if status != current {
+		set += statusTimestampsSet(status)
+	}
+
+	item, err := scanTask(tx.QueryRow(ctx, `
+		UPDATE task
+		SET `+set+`, updated_at = now()
+		WHERE id = $3
+		RETURNING `+taskColumns,
+		status,
+		position,
+		id,
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	if status != current {
+		_, err = tx.Exec(ctx, `
+			INSERT INTO task_status_history(task_id, from_status, to_status, changed_by)
+			VALUES ($1, $2, $3, $4)`,
+			id,
+			current,
+			status,
+			actorId,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		err = trackTaskTime(ctx, tx, id, actorId, status)
+		if err != nil {
+			return nil, err
+		}
+
+		if status == StatusInProgress {
+			err = autoAssign(ctx, tx, id, actorId)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if status == StatusDone {
+			err = createNextOccurrence(ctx, tx, id)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.loadTaskDetails(ctx, []*Task{item})
+	if err != nil {
+		return nil, err
+	}
+
+	if config.DebugLog() {
+		log.Println("task move: successfully moved task in db")
+	}
+
+	return item, nil
+}
@@ -0,0 +1,125 @@
+package model
+
+import (
+	"context"
+	"log"
+	"time"
+	"todo/internal/config"
+	"todo/internal/recurrence"
+
+	"github.com/jackc/pgx/v4"
+)
+
+// dueLocation is the location occurrences of the task are computed in
+func (t *Task) dueLocation() *time.Location {
+	if t.DueTimezone != "" {
+		if loc, err := time.LoadLocation(t.DueTimezone); err == nil {
+			return loc
+		}
+	}
+	return time.UTC
+}
+
+// NextOccurrences returns due dates of up to n occurrences following the task if it is done at now.
+// Occurrences missed by then are skipped, the list is empty if the task does not recur
+func (t *Task) NextOccurrences(now time.Time, n int) ([]time.Time, error) {
+	if t.Recurrence == "" || t.DueAt == nil {
+		return []time.Time{}, nil
+	}
+
+	rule, err := recurrence.Parse(t.Recurrence)
+	if err != nil {
+		return nil, err
+	}
+
+	start := t.DueAt.In(t.dueLocation())
+	after := start
+	if now.After(after) {
+		after = now
+	}
+
+	return rule.Occurrences(start, after, n), nil
+}
+
+// createNextOccurrence copies the recurring task done just now with the next due date,
+// labels, assignees and unchecked checklist are copied too, blockers and subtasks are not
+func createNextOccurrence(ctx context.Context, tx pgx.Tx, id int64) error {
+	task, err := scanTask(tx.QueryRow(ctx, `
+		SELECT `+taskColumns+`
+		FROM task
+		WHERE id = $1
+	`, id))
+	if err != nil {
+		return err
+	}
+
+	next, err := task.NextOccurrences(time.Now(), 1)
+	if err != nil || len(next) == 0 {
+		return err
+	}
+
+	position, err := lastTaskPosition(ctx, tx, task.OwnerId, StatusCreated, 0)
+	if err != nil {
+		return err
+	}
+
+	position, err = positionBetween(position, "")
+	if err != nil {
+		return err
+	}
+
+	var nextId int64
+	err = tx.QueryRow(ctx, `
+		INSERT INTO task(owner_id, name, description, status, priority, position, due_at, due_timezone, parent_id, recurrence, project_id, estimate_minutes)
+		SELECT owner_id, name, description, $2, priority, $3, $4, due_timezone, parent_id, recurrence, project_id, estimate_minutes
+		FROM task
+		WHERE id = $1
+		RETURNING id`,
+		id,
+		StatusCreated,
+		position,
+		next[0],
+	).Scan(&nextId)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO task_status_history(task_id, from_status, to_status, changed_by)
+		VALUES ($1, NULL, $2, $3)`,
+		nextId,
+		StatusCreated,
+		task.OwnerId,
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO task_label(task_id, label_id)
+		SELECT $2, label_id
+		FROM task_label
+		WHERE task_id = $1`,
+		id,
+		nextId,
+	)
+	if err != nil {
+		return err
+	}
+
+	err = copyChecklist(ctx, tx, id, nextId)
+	if err != nil {
+		return err
+	}
+
+	err = copyAssignees(ctx, tx, id, nextId)
+	if err != nil {
+		return err
+	}
+
+	if config.DebugLog() {
+		log.Println("task done: successfully created next occurrence in db")
+	}
+
+	return nil
+}
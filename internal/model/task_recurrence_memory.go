@@ -0,0 +1,68 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// createNextOccurrence copies the recurring task done at now with the next due date, must be called with s.mu held
+func (s *MemoryStore) createNextOccurrence(task *Task, now time.Time) error {
+	next, err := task.NextOccurrences(now, 1)
+	if err != nil || len(next) == 0 {
+		return err
+	}
+
+	position, _ := positionBetween(s.lastTaskPosition(task.OwnerId, StatusCreated, 0), "")
+
+	s.lastTaskId++
+	item := &Task{
+		Id:          s.lastTaskId,
+		PublicId:    uuid.NewString(),
+		OwnerId:     task.OwnerId,
+		Name:        task.Name,
+		Description: task.Description,
+		Status:      StatusCreated,
+		Priority:    task.Priority,
+		Position:    position,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+		DueAt:       &next[0],
+		DueTimezone: task.DueTimezone,
+		Recurrence:  task.Recurrence,
+		ParentId:    task.ParentId,
+		ProjectId:   task.ProjectId,
+
+		EstimateMinutes: task.EstimateMinutes,
+	}
+	s.tasks[item.Id] = item
+	s.addTaskHistory(item.Id, "", item.Status, task.OwnerId, now)
+
+	if labels := s.taskLabels[task.Id]; len(labels) > 0 {
+		s.taskLabels[item.Id] = map[int64]bool{}
+		for labelId := range labels {
+			s.taskLabels[item.Id][labelId] = true
+		}
+	}
+
+	if assignees := s.taskAssignees[task.Id]; len(assignees) > 0 {
+		s.taskAssignees[item.Id] = map[uint16]bool{}
+		for userId := range assignees {
+			s.taskAssignees[item.Id][userId] = true
+		}
+	}
+
+	for _, checklistItem := range s.checklist(task.Id) {
+		s.lastChecklistItemId++
+		s.checklistItems[s.lastChecklistItemId] = &ChecklistItem{
+			Id:        s.lastChecklistItemId,
+			TaskId:    item.Id,
+			Text:      checklistItem.Text,
+			Position:  checklistItem.Position,
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+	}
+
+	return nil
+}
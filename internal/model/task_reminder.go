@@ -0,0 +1,60 @@
+package model
+
+import (
+	"context"
+	"log"
+	"time"
+	"todo/internal/config"
+)
+
+// TaskReminderStore is used by the reminder scheduler, it is not scoped to an owner
+type TaskReminderStore interface {
+	// ClaimTaskReminders marks up to limit not yet reminded tasks due before the time as reminded
+	// and returns them, so every reminder is claimed once even by several server instances
+	ClaimTaskReminders(dueBefore time.Time, limit int) ([]*Task, error)
+}
+
+func (s *PgStore) ClaimTaskReminders(dueBefore time.Time, limit int) ([]*Task, error) {
+	var result []*Task = []*Task{}
+
+	rows, err := s.pool.Query(context.Background(), `
+		UPDATE task
+		SET reminded_at = now()
+		WHERE id IN (
+			SELECT id
+			FROM task
+			WHERE reminded_at IS NULL AND due_at < $1 AND status NOT IN ($2, $3)
+			ORDER BY due_at
+			LIMIT $4
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING `+taskColumns,
+		dueBefore,
+		StatusDone,
+		StatusDeleted,
+		limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		item, err := scanTask(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, item)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	if config.DebugLog() && len(result) > 0 {
+		log.Println("task reminders: successfully claimed data in db")
+	}
+
+	return result, nil
+}
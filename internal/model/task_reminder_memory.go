@@ -0,0 +1,38 @@
+package model
+
+import (
+	"sort"
+	"time"
+)
+
+func (s *MemoryStore) ClaimTaskReminders(dueBefore time.Time, limit int) ([]*Task, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var due []*Task
+	for _, task := range s.tasks {
+		if task.remindedAt != nil || task.DueAt == nil || !task.DueAt.Before(dueBefore) ||
+			task.Status == StatusDone || task.Status == StatusDeleted {
+			continue
+		}
+		due = append(due, task)
+	}
+
+	sort.Slice(due, func(i, j int) bool {
+		return due[i].DueAt.Before(*due[j].DueAt)
+	})
+	if len(due) > limit {
+		due = due[:limit]
+	}
+
+	now := time.Now()
+
+	var result []*Task = []*Task{}
+	for _, task := range due {
+		task.remindedAt = &now
+
+		result = append(result, s.taskCopy(task))
+	}
+
+	return result, nil
+}
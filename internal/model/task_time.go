@@ -0,0 +1,185 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+	"todo/internal/config"
+
+	"github.com/jackc/pgx/v4"
+)
+
+// TimeEntry is time spent on a task. Tracked entries are opened when the task goes in progress
+// and closed when it leaves in progress, manual ones are added by users
+type TimeEntry struct {
+	Id        int64      `json:"id"`
+	TaskId    int64      `json:"task_id"`
+	UserId    uint16     `json:"user_id"` // who started the task or added the entry
+	StartedAt time.Time  `json:"started_at"`
+	EndedAt   *time.Time `json:"ended_at"` // nil while the entry is running
+	Manual    bool       `json:"manual"`
+	Note      string     `json:"note" example:"Call with the customer"`
+	Seconds   int64      `json:"seconds"` // duration, up to now for the running entry
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// TaskTime is the time tracked on a task compared with its estimate
+type TaskTime struct {
+	Entries         []*TimeEntry `json:"data"`
+	TotalSeconds    int64        `json:"total_seconds"`
+	EstimateMinutes *int32       `json:"estimate_minutes"`
+}
+
+// TimeEntryFields are the fields of a manual time entry
+type TimeEntryFields struct {
+	StartedAt time.Time
+	EndedAt   time.Time
+	Note      string
+}
+
+// TaskTimeStore methods are scoped to the task owner the same way TaskStore ones are
+type TaskTimeStore interface {
+	// GetTaskTime returns entries of the task from the earliest
+	GetTaskTime(ownerId uint16, taskId int64) (*TaskTime, error)
+	AddTimeEntry(ownerId uint16, taskId int64, userId uint16, fields TimeEntryFields) (*TimeEntry, error)
+}
+
+// setSeconds computes duration of the entry, running one counts up to now
+func (e *TimeEntry) setSeconds(now time.Time) {
+	end := now
+	if e.EndedAt != nil {
+		end = *e.EndedAt
+	}
+
+	e.Seconds = int64(end.Sub(e.StartedAt) / time.Second)
+	if e.Seconds < 0 {
+		e.Seconds = 0
+	}
+}
+
+// newTaskTime sums up the entries
+func newTaskTime(entries []*TimeEntry, estimate *int32, now time.Time) *TaskTime {
+	result := &TaskTime{Entries: entries, EstimateMinutes: estimate}
+	for _, entry := range entries {
+		entry.setSeconds(now)
+		result.TotalSeconds += entry.Seconds
+	}
+	return result
+}
+
+const timeEntryColumns = "id, task_id, user_id, started_at, ended_at, manual, note, created_at"
+
+func scanTimeEntry(row pgx.Row) (*TimeEntry, error) {
+	var item TimeEntry
+
+	err := row.Scan(&item.Id, &item.TaskId, &item.UserId, &item.StartedAt, &item.EndedAt, &item.Manual, &item.Note, &item.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &item, nil
+}
+
+func (s *PgStore) GetTaskTime(ownerId uint16, taskId int64) (*TaskTime, error) {
+	ctx := context.Background()
+
+	var estimate *int32
+	var now time.Time
+	err := s.pool.QueryRow(ctx, `
+		SELECT estimate_minutes, now()
+		FROM task
+		WHERE id = $1 AND owner_id = $2
+	`, taskId, ownerId).Scan(&estimate, &now)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrTaskNotFound
+		}
+		return nil, err
+	}
+
+	rows, err := s.pool.Query(ctx, `
+		SELECT `+timeEntryColumns+`
+		FROM task_time_entry
+		WHERE task_id = $1
+		ORDER BY started_at, id
+	`, taskId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []*TimeEntry{}
+	for rows.Next() {
+		item, err := scanTimeEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, item)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	if config.DebugLog() {
+		log.Println("task time: successfully retrieved data from db")
+	}
+
+	return newTaskTime(entries, estimate, now), nil
+}
+
+func (s *PgStore) AddTimeEntry(ownerId uint16, taskId int64, userId uint16, fields TimeEntryFields) (*TimeEntry, error) {
+	ctx := context.Background()
+
+	err := ownedTaskExists(ctx, s.pool, ownerId, taskId)
+	if err != nil {
+		return nil, err
+	}
+
+	item, err := scanTimeEntry(s.pool.QueryRow(ctx, `
+		INSERT INTO task_time_entry(task_id, user_id, started_at, ended_at, manual, note)
+		VALUES ($1, $2, $3, $4, true, $5)
+		RETURNING `+timeEntryColumns,
+		taskId,
+		userId,
+		fields.StartedAt,
+		fields.EndedAt,
+		fields.Note,
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	item.setSeconds(fields.EndedAt)
+
+	if config.DebugLog() {
+		log.Println("add time entry: successfully created data in db")
+	}
+
+	return item, nil
+}
+
+// trackTaskTime opens a time entry of the user when the task goes in progress
+// and closes the running one when it goes to any other status
+func trackTaskTime(ctx context.Context, q querier, taskId int64, userId uint16, status string) error {
+	if status == StatusInProgress {
+		_, err := q.Exec(ctx, `
+			INSERT INTO task_time_entry(task_id, user_id)
+			VALUES ($1, $2)
+			ON CONFLICT (task_id) WHERE ended_at IS NULL DO NOTHING`,
+			taskId,
+			userId,
+		)
+		return err
+	}
+
+	_, err := q.Exec(ctx, `
+		UPDATE task_time_entry
+		SET ended_at = now()
+		WHERE task_id = $1 AND ended_at IS NULL`,
+		taskId,
+	)
+	return err
+}
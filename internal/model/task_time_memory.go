@@ -0,0 +1,93 @@
+package model
+
+import (
+	"sort"
+	"time"
+)
+
+// trackTaskTime is the same as the postgres one, must be called with s.mu held
+func (s *MemoryStore) trackTaskTime(taskId int64, userId uint16, status string, now time.Time) {
+	var running *TimeEntry
+	for _, entry := range s.timeEntries {
+		if entry.TaskId == taskId && entry.EndedAt == nil {
+			running = entry
+			break
+		}
+	}
+
+	if status != StatusInProgress {
+		if running != nil {
+			running.EndedAt = &now
+		}
+		return
+	}
+
+	if running != nil {
+		return
+	}
+
+	s.lastTimeEntryId++
+	s.timeEntries[s.lastTimeEntryId] = &TimeEntry{
+		Id:        s.lastTimeEntryId,
+		TaskId:    taskId,
+		UserId:    userId,
+		StartedAt: now,
+		CreatedAt: now,
+	}
+}
+
+func (s *MemoryStore) GetTaskTime(ownerId uint16, taskId int64) (*TaskTime, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	task, err := s.ownedTask(ownerId, taskId)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := []*TimeEntry{}
+	for _, entry := range s.timeEntries {
+		if entry.TaskId == taskId {
+			copied := *entry
+			entries = append(entries, &copied)
+		}
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		if !entries[i].StartedAt.Equal(entries[j].StartedAt) {
+			return entries[i].StartedAt.Before(entries[j].StartedAt)
+		}
+		return entries[i].Id < entries[j].Id
+	})
+
+	return newTaskTime(entries, task.EstimateMinutes, time.Now()), nil
+}
+
+func (s *MemoryStore) AddTimeEntry(ownerId uint16, taskId int64, userId uint16, fields TimeEntryFields) (*TimeEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.ownedTask(ownerId, taskId); err != nil {
+		return nil, err
+	}
+
+	endedAt := fields.EndedAt
+
+	s.lastTimeEntryId++
+	entry := &TimeEntry{
+		Id:        s.lastTimeEntryId,
+		TaskId:    taskId,
+		UserId:    userId,
+		StartedAt: fields.StartedAt,
+		EndedAt:   &endedAt,
+		Manual:    true,
+		Note:      fields.Note,
+		CreatedAt: time.Now(),
+	}
+	s.timeEntries[entry.Id] = entry
+
+	copied := *entry
+	copied.setSeconds(endedAt)
+
+	return &copied, nil
+}
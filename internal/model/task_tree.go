@@ -0,0 +1,267 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"log"
+	"todo/internal/config"
+
+	"github.com/jackc/pgx/v4"
+)
+
+// TaskProgress counts direct children of the task, deleted ones are not counted
+type TaskProgress struct {
+	Done  int `json:"done"`
+	Total int `json:"total"`
+}
+
+var (
+	ErrTaskParentNotFound = errors.New("parent task is missing or deleted")
+	ErrTaskParentCycle    = errors.New("task can not be a descendant of itself")
+	ErrTaskParentDeleted  = errors.New("parent task is deleted")
+	// ErrTrashSubtaskOutsideProject is returned by FreeTaskTrash of a project when subtasks of its trashed tasks
+	// belong to other projects, they would be deleted together with their parents
+	ErrTrashSubtaskOutsideProject = errors.New("trashed task of the project has subtasks in other projects")
+)
+
+// taskTreeLock is advisory lock class serializing parent changes of one owner,
+// so two concurrent edits can not make a cycle each of them would not see alone
+const taskTreeLock = 7405164
+
+// checkTaskParent locks the parent against deletion and checks task can be put under it,
+// id is 0 for a new task
+func checkTaskParent(ctx context.Context, tx pgx.Tx, ownerId uint16, id, parentId int64) error {
+	_, err := tx.Exec(ctx, "SELECT pg_advisory_xact_lock($1, $2)", taskTreeLock, int32(ownerId))
+	if err != nil {
+		return err
+	}
+
+	var status string
+	err = tx.QueryRow(ctx, `
+		SELECT status
+		FROM task
+		WHERE id = $1 AND owner_id = $2
+		FOR SHARE
+	`, parentId, ownerId).Scan(&status)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrTaskParentNotFound
+		}
+		return err
+	}
+
+	if status == StatusDeleted {
+		return ErrTaskParentNotFound
+	}
+
+	if id == 0 {
+		return nil
+	}
+
+	var cycle bool
+	err = tx.QueryRow(ctx, `
+		WITH RECURSIVE ancestors AS (
+			SELECT id, parent_id
+			FROM task
+			WHERE id = $1
+			UNION
+			SELECT t.id, t.parent_id
+			FROM task t
+			JOIN ancestors a ON t.id = a.parent_id
+		)
+		SELECT EXISTS (SELECT 1 FROM ancestors WHERE id = $2)
+	`, parentId, id).Scan(&cycle)
+	if err != nil {
+		return err
+	}
+
+	if cycle {
+		return ErrTaskParentCycle
+	}
+
+	return nil
+}
+
+func (s *PgStore) GetTaskChildren(ownerId uint16, id int64) ([]*Task, error) {
+	ctx := context.Background()
+
+	var exists bool
+	err := s.pool.QueryRow(ctx, `
+		SELECT EXISTS (SELECT 1 FROM task WHERE id = $1 AND owner_id = $2)
+	`, id, ownerId).Scan(&exists)
+	if err != nil {
+		return nil, err
+	}
+
+	if !exists {
+		return nil, ErrTaskNotFound
+	}
+
+	// positions are keys of status columns, so children are grouped by status first
+	result, err := s.queryTasks(ctx, `
+		SELECT `+taskColumns+`
+		FROM task
+		WHERE parent_id = $1 AND status <> $2
+		ORDER BY array_position($3::text[], status), position, id
+	`, id, StatusDeleted, statuses)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.loadTaskDetails(ctx, result)
+	if err != nil {
+		return nil, err
+	}
+
+	if config.DebugLog() {
+		log.Println("task children: successfully retrieved data from db")
+	}
+
+	return result, nil
+}
+
+// queryTasks reads tasks selected by taskColumns
+func (s *PgStore) queryTasks(ctx context.Context, sql string, args ...interface{}) ([]*Task, error) {
+	var result []*Task = []*Task{}
+
+	rows, err := s.pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		item, err := scanTask(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, item)
+	}
+
+	return result, rows.Err()
+}
+
+// loadTaskDetails fills labels, progress, blocked flag, comment count and checklist progress of all tasks, one query for each
+func (s *PgStore) loadTaskDetails(ctx context.Context, tasks []*Task) error {
+	err := s.loadTaskLabels(ctx, tasks)
+	if err != nil {
+		return err
+	}
+
+	err = s.loadTaskProgress(ctx, tasks)
+	if err != nil {
+		return err
+	}
+
+	err = s.loadTaskBlocked(ctx, tasks)
+	if err != nil {
+		return err
+	}
+
+	err = s.loadTaskCommentCounts(ctx, tasks)
+	if err != nil {
+		return err
+	}
+
+	err = s.loadTaskAssignees(ctx, tasks)
+	if err != nil {
+		return err
+	}
+
+	return s.loadTaskChecklistProgress(ctx, tasks)
+}
+
+func (s *PgStore) loadTaskProgress(ctx context.Context, tasks []*Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	byId := make(map[int64]*Task, len(tasks))
+	ids := make([]int64, 0, len(tasks))
+	for _, task := range tasks {
+		byId[task.Id] = task
+		ids = append(ids, task.Id)
+	}
+
+	rows, err := s.pool.Query(ctx, `
+		SELECT parent_id, COUNT(*) FILTER (WHERE status = $2), COUNT(*)
+		FROM task
+		WHERE parent_id = ANY($1) AND status <> $3
+		GROUP BY parent_id
+	`, ids, StatusDone, StatusDeleted)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var parentId int64
+		var progress TaskProgress
+
+		err = rows.Scan(&parentId, &progress.Done, &progress.Total)
+		if err != nil {
+			return err
+		}
+
+		byId[parentId].Progress = progress
+	}
+
+	return rows.Err()
+}
+
+// loadTaskSubtrees fills children of the tasks recursively with their not deleted descendants
+func (s *PgStore) loadTaskSubtrees(ctx context.Context, tasks []*Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	ids := make([]int64, 0, len(tasks))
+	for _, task := range tasks {
+		ids = append(ids, task.Id)
+	}
+
+	descendants, err := s.queryTasks(ctx, `
+		WITH RECURSIVE subtree AS (
+			SELECT `+taskColumns+`
+			FROM task
+			WHERE parent_id = ANY($1) AND status <> $2
+			UNION ALL
+			SELECT `+prefixedTaskColumns("t")+`
+			FROM task t
+			JOIN subtree ON t.parent_id = subtree.id
+			WHERE t.status <> $2
+		)
+		SELECT `+taskColumns+`
+		FROM subtree
+		ORDER BY array_position($3::text[], status), position, id
+	`, ids, StatusDeleted, statuses)
+	if err != nil {
+		return err
+	}
+
+	err = s.loadTaskDetails(ctx, descendants)
+	if err != nil {
+		return err
+	}
+
+	attachChildren(tasks, descendants)
+
+	return nil
+}
+
+// attachChildren puts every descendant into children of its parent keeping descendants order
+func attachChildren(roots, descendants []*Task) {
+	byId := make(map[int64]*Task, len(roots)+len(descendants))
+	for _, task := range roots {
+		byId[task.Id] = task
+	}
+	for _, task := range descendants {
+		byId[task.Id] = task
+	}
+
+	for _, task := range descendants {
+		parent := byId[*task.ParentId]
+		parent.Children = append(parent.Children, task)
+	}
+}
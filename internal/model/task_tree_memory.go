@@ -0,0 +1,95 @@
+package model
+
+import "sort"
+
+// checkTaskParent must be called with s.mu held, id is 0 for a new task
+func (s *MemoryStore) checkTaskParent(ownerId uint16, id, parentId int64) error {
+	parent, ok := s.tasks[parentId]
+	if !ok || parent.OwnerId != ownerId || parent.Status == StatusDeleted {
+		return ErrTaskParentNotFound
+	}
+
+	for ancestor := parent; ancestor != nil; {
+		if ancestor.Id == id {
+			return ErrTaskParentCycle
+		}
+		if ancestor.ParentId == nil {
+			break
+		}
+		ancestor = s.tasks[*ancestor.ParentId]
+	}
+
+	return nil
+}
+
+// children returns direct children of the task ordered by status and then position, since positions
+// are keys of status columns, must be called with s.mu held
+func (s *MemoryStore) children(task *Task, withDeleted bool) []*Task {
+	var result []*Task = []*Task{}
+	for _, item := range s.tasks {
+		if item.ParentId == nil || *item.ParentId != task.Id {
+			continue
+		}
+		if item.Status == StatusDeleted && !withDeleted {
+			continue
+		}
+		result = append(result, item)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if ri, rj := statusRank(result[i].Status), statusRank(result[j].Status); ri != rj {
+			return ri < rj
+		}
+		return compareCursors(result[i].cursor("position"), result[j].cursor("position"), "position") < 0
+	})
+
+	return result
+}
+
+// subtree returns the task with its descendants, must be called with s.mu held.
+// Without deleted ones the subtree stops at deleted descendants
+func (s *MemoryStore) subtree(task *Task, withDeleted bool) []*Task {
+	result := []*Task{task}
+	for _, child := range s.children(task, withDeleted) {
+		result = append(result, s.subtree(child, withDeleted)...)
+	}
+	return result
+}
+
+// taskTree returns a copy of the task with its not deleted descendants as children, must be called with s.mu held
+func (s *MemoryStore) taskTree(task *Task) *Task {
+	item := s.taskCopy(task)
+	for _, child := range s.children(task, false) {
+		item.Children = append(item.Children, s.taskTree(child))
+	}
+	return item
+}
+
+// taskProgress must be called with s.mu held
+func (s *MemoryStore) taskProgress(task *Task) TaskProgress {
+	var progress TaskProgress
+	for _, child := range s.children(task, false) {
+		progress.Total++
+		if child.Status == StatusDone {
+			progress.Done++
+		}
+	}
+	return progress
+}
+
+func (s *MemoryStore) GetTaskChildren(ownerId uint16, id int64) ([]*Task, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	task, err := s.ownedTask(ownerId, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []*Task = []*Task{}
+	for _, child := range s.children(task, false) {
+		result = append(result, s.taskCopy(child))
+	}
+
+	return result, nil
+}
@@ -0,0 +1,103 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+	"todo/internal/config"
+
+	"github.com/jackc/pgx/v4"
+)
+
+// ErrRefreshTokenNotFound is returned when refresh token was never issued, already used or expired
+var ErrRefreshTokenNotFound = errors.New("refresh token not found")
+
+type TokenStore interface {
+	SaveRefreshToken(tokenId string, userId uint16, expiresAt time.Time) error
+	// ConsumeRefreshToken deletes refresh token, so it can be used only once, and returns its user id
+	ConsumeRefreshToken(tokenId string) (uint16, error)
+	RevokeToken(tokenId string, expiresAt time.Time) error
+	IsTokenRevoked(tokenId string) (bool, error)
+}
+
+func (s *PgStore) SaveRefreshToken(tokenId string, userId uint16, expiresAt time.Time) error {
+	_, err := s.pool.Exec(context.Background(), `
+		INSERT INTO refresh_token(id, user_id, expires_at)
+		VALUES ($1, $2, $3)`,
+		tokenId,
+		userId,
+		expiresAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	if config.DebugLog() {
+		log.Println("save refresh token: successfully created data in db")
+	}
+
+	return nil
+}
+
+func (s *PgStore) ConsumeRefreshToken(tokenId string) (uint16, error) {
+	var userId uint16
+
+	err := s.pool.QueryRow(context.Background(), `
+		DELETE FROM refresh_token
+		WHERE id = $1 AND expires_at > now()
+		RETURNING user_id`,
+		tokenId,
+	).Scan(&userId)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return userId, ErrRefreshTokenNotFound
+		}
+		return userId, err
+	}
+
+	if config.DebugLog() {
+		log.Println("consume refresh token: successfully deleted data in db")
+	}
+
+	return userId, nil
+}
+
+func (s *PgStore) RevokeToken(tokenId string, expiresAt time.Time) error {
+	_, err := s.pool.Exec(context.Background(), `
+		INSERT INTO revoked_token(id, expires_at)
+		VALUES ($1, $2)
+		ON CONFLICT (id) DO NOTHING`,
+		tokenId,
+		expiresAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	// expired tokens are rejected anyway, no need to keep them in denylist
+	_, err = s.pool.Exec(context.Background(), `
+		DELETE FROM revoked_token
+		WHERE expires_at < now()`,
+	)
+	if err != nil {
+		return err
+	}
+
+	if config.DebugLog() {
+		log.Println("revoke token: successfully created data in db")
+	}
+
+	return nil
+}
+
+func (s *PgStore) IsTokenRevoked(tokenId string) (bool, error) {
+	var revoked bool
+
+	err := s.pool.QueryRow(context.Background(), `
+		SELECT EXISTS(SELECT 1 FROM revoked_token WHERE id = $1)`,
+		tokenId,
+	).Scan(&revoked)
+
+	return revoked, err
+}
@@ -0,0 +1,57 @@
+package model
+
+import "time"
+
+type memoryRefreshToken struct {
+	userId    uint16
+	expiresAt time.Time
+}
+
+func (s *MemoryStore) SaveRefreshToken(tokenId string, userId uint16, expiresAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.refreshTokens[tokenId] = memoryRefreshToken{
+		userId:    userId,
+		expiresAt: expiresAt,
+	}
+
+	return nil
+}
+
+func (s *MemoryStore) ConsumeRefreshToken(tokenId string) (uint16, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	token, ok := s.refreshTokens[tokenId]
+	if !ok || !token.expiresAt.After(time.Now()) {
+		return 0, ErrRefreshTokenNotFound
+	}
+	delete(s.refreshTokens, tokenId)
+
+	return token.userId, nil
+}
+
+func (s *MemoryStore) RevokeToken(tokenId string, expiresAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.revokedTokens[tokenId] = expiresAt
+
+	now := time.Now()
+	for id, expiresAt := range s.revokedTokens {
+		if expiresAt.Before(now) {
+			delete(s.revokedTokens, id)
+		}
+	}
+
+	return nil
+}
+
+func (s *MemoryStore) IsTokenRevoked(tokenId string) (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	_, revoked := s.revokedTokens[tokenId]
+	return revoked, nil
+}
@@ -0,0 +1,76 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"log"
+	"todo/internal/config"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+)
+
+type User struct {
+	Id           uint16 `json:"id"`
+	Login        string `json:"login" example:"michael"`
+	PasswordHash string `json:"-"`
+}
+
+var (
+	ErrUserNotFound   = errors.New("user not found")
+	ErrUserLoginTaken = errors.New("user login is already taken")
+)
+
+type UserStore interface {
+	CreateUser(login, passwordHash string) (uint16, error)
+	GetUserByLogin(login string) (*User, error)
+}
+
+const pgUniqueViolation = "23505"
+
+func (s *PgStore) CreateUser(login, passwordHash string) (uint16, error) {
+	var id uint16
+
+	err := s.pool.QueryRow(context.Background(), `
+		INSERT INTO users(login, password_hash)
+		VALUES ($1, $2) RETURNING id`,
+		login,
+		passwordHash,
+	).Scan(&id)
+
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation {
+			return id, ErrUserLoginTaken
+		}
+		return id, err
+	}
+
+	if config.DebugLog() {
+		log.Println("user create: successfully created data in db")
+	}
+
+	return id, nil
+}
+
+func (s *PgStore) GetUserByLogin(login string) (*User, error) {
+	var item User
+
+	err := s.pool.QueryRow(context.Background(), `
+		SELECT id, login, password_hash
+		FROM users
+		WHERE login = $1
+	`, login).Scan(&item.Id, &item.Login, &item.PasswordHash)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	if config.DebugLog() {
+		log.Println("get user by login: successfully retrieved data in db")
+	}
+
+	return &item, nil
+}
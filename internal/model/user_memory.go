@@ -0,0 +1,35 @@
+package model
+
+func (s *MemoryStore) CreateUser(login, passwordHash string) (uint16, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, user := range s.users {
+		if user.Login == login {
+			return 0, ErrUserLoginTaken
+		}
+	}
+
+	s.lastUserId++
+	s.users[s.lastUserId] = &User{
+		Id:           s.lastUserId,
+		Login:        login,
+		PasswordHash: passwordHash,
+	}
+
+	return s.lastUserId, nil
+}
+
+func (s *MemoryStore) GetUserByLogin(login string) (*User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, user := range s.users {
+		if user.Login == login {
+			item := *user
+			return &item, nil
+		}
+	}
+
+	return nil, ErrUserNotFound
+}
@@ -0,0 +1,316 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+	"todo/internal/config"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+)
+
+// Roles of workspace members, a member with owner role can do everything the workspace owner can
+const (
+	RoleOwner  = "owner"
+	RoleEditor = "editor"
+	RoleViewer = "viewer"
+)
+
+var Roles = []string{RoleOwner, RoleEditor, RoleViewer}
+
+// Workspace is everything its owner has: tasks, labels and projects, shared with the members.
+// Every user has one, its id is the id of the owner
+type Workspace struct {
+	Id         uint16 `json:"id"`
+	OwnerLogin string `json:"owner_login" example:"michael"`
+	Role       string `json:"role" example:"editor"` // role of the user the workspace is listed for
+}
+
+// WorkspaceMember is a member of the workspace or an invited user until the invitation is accepted
+type WorkspaceMember struct {
+	UserId     uint16     `json:"user_id"`
+	Login      string     `json:"login" example:"scottie"`
+	Role       string     `json:"role" example:"viewer"`
+	InvitedAt  time.Time  `json:"invited_at"`
+	AcceptedAt *time.Time `json:"accepted_at"` // nil while the invitation is pending
+}
+
+var (
+	ErrWorkspaceNotFound  = errors.New("workspace not found")
+	ErrMemberNotFound     = errors.New("workspace member not found")
+	ErrMemberExists       = errors.New("user is already a member of the workspace or invited to it")
+	ErrInvitationNotFound = errors.New("workspace invitation not found")
+)
+
+type WorkspaceStore interface {
+	// GetWorkspaceList returns own workspace of the user first, then the ones the user is a member of
+	GetWorkspaceList(userId uint16) ([]*Workspace, error)
+	// GetWorkspaceInvitations returns workspaces the user is invited to and has not accepted yet
+	GetWorkspaceInvitations(userId uint16) ([]*Workspace, error)
+	// GetWorkspaceRole returns owner for own workspace, ErrWorkspaceNotFound unless the user is a member
+	GetWorkspaceRole(workspaceId, userId uint16) (string, error)
+	GetWorkspaceMembers(workspaceId uint16) ([]*WorkspaceMember, error)
+	// InviteWorkspaceMember returns ErrUserNotFound for unknown login, ErrMemberExists for the owner and members
+	InviteWorkspaceMember(workspaceId uint16, login, role string) (*WorkspaceMember, error)
+	AcceptWorkspaceInvitation(workspaceId, userId uint16) error
+	EditWorkspaceMember(workspaceId, userId uint16, role string) error
+	// RemoveWorkspaceMember removes a member or a pending invitation, the member is unassigned from tasks of the workspace
+	RemoveWorkspaceMember(workspaceId, userId uint16) error
+}
+
+// queryWorkspaces returns workspaces of the user membership, accepted or pending ones
+func (s *PgStore) queryWorkspaces(userId uint16, accepted bool) ([]*Workspace, error) {
+	var result []*Workspace = []*Workspace{}
+
+	rows, err := s.pool.Query(context.Background(), `
+		SELECT m.workspace_id, u.login, m.role
+		FROM workspace_member m
+		JOIN users u ON u.id = m.workspace_id
+		WHERE m.user_id = $1 AND (m.accepted_at IS NOT NULL) = $2
+		ORDER BY u.login
+	`, userId, accepted)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var item Workspace
+
+		err = rows.Scan(&item.Id, &item.OwnerLogin, &item.Role)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, &item)
+	}
+
+	return result, rows.Err()
+}
+
+func (s *PgStore) GetWorkspaceList(userId uint16) ([]*Workspace, error) {
+	own := Workspace{Id: userId, Role: RoleOwner}
+
+	err := s.pool.QueryRow(context.Background(), `
+		SELECT login
+		FROM users
+		WHERE id = $1
+	`, userId).Scan(&own.OwnerLogin)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrWorkspaceNotFound
+		}
+		return nil, err
+	}
+
+	shared, err := s.queryWorkspaces(userId, true)
+	if err != nil {
+		return nil, err
+	}
+
+	if config.DebugLog() {
+		log.Println("workspace list: successfully retrieved data from db")
+	}
+
+	return append([]*Workspace{&own}, shared...), nil
+}
+
+func (s *PgStore) GetWorkspaceInvitations(userId uint16) ([]*Workspace, error) {
+	result, err := s.queryWorkspaces(userId, false)
+	if err != nil {
+		return nil, err
+	}
+
+	if config.DebugLog() {
+		log.Println("workspace invitations: successfully retrieved data from db")
+	}
+
+	return result, nil
+}
+
+func (s *PgStore) GetWorkspaceRole(workspaceId, userId uint16) (string, error) {
+	if workspaceId == userId {
+		return RoleOwner, nil
+	}
+
+	var role string
+	err := s.pool.QueryRow(context.Background(), `
+		SELECT role
+		FROM workspace_member
+		WHERE workspace_id = $1 AND user_id = $2 AND accepted_at IS NOT NULL
+	`, workspaceId, userId).Scan(&role)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", ErrWorkspaceNotFound
+		}
+		return "", err
+	}
+
+	return role, nil
+}
+
+func (s *PgStore) GetWorkspaceMembers(workspaceId uint16) ([]*WorkspaceMember, error) {
+	var result []*WorkspaceMember = []*WorkspaceMember{}
+
+	rows, err := s.pool.Query(context.Background(), `
+		SELECT m.user_id, u.login, m.role, m.invited_at, m.accepted_at
+		FROM workspace_member m
+		JOIN users u ON u.id = m.user_id
+		WHERE m.workspace_id = $1
+		ORDER BY u.login
+	`, workspaceId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var item WorkspaceMember
+
+		err = rows.Scan(&item.UserId, &item.Login, &item.Role, &item.InvitedAt, &item.AcceptedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, &item)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	if config.DebugLog() {
+		log.Println("workspace members: successfully retrieved data from db")
+	}
+
+	return result, nil
+}
+
+func (s *PgStore) InviteWorkspaceMember(workspaceId uint16, login, role string) (*WorkspaceMember, error) {
+	user, err := s.GetUserByLogin(login)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.Id == workspaceId {
+		return nil, ErrMemberExists
+	}
+
+	item := WorkspaceMember{UserId: user.Id, Login: user.Login, Role: role}
+
+	err = s.pool.QueryRow(context.Background(), `
+		INSERT INTO workspace_member(workspace_id, user_id, role)
+		VALUES ($1, $2, $3) RETURNING invited_at`,
+		workspaceId,
+		user.Id,
+		role,
+	).Scan(&item.InvitedAt)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation {
+			return nil, ErrMemberExists
+		}
+		return nil, err
+	}
+
+	if config.DebugLog() {
+		log.Println("workspace invite: successfully created data in db")
+	}
+
+	return &item, nil
+}
+
+func (s *PgStore) AcceptWorkspaceInvitation(workspaceId, userId uint16) error {
+	tag, err := s.pool.Exec(context.Background(), `
+		UPDATE workspace_member
+		SET accepted_at = COALESCE(accepted_at, now())
+		WHERE workspace_id = $1 AND user_id = $2`,
+		workspaceId,
+		userId,
+	)
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return ErrInvitationNotFound
+	}
+
+	if config.DebugLog() {
+		log.Println("workspace accept: successfully edited data in db")
+	}
+
+	return nil
+}
+
+func (s *PgStore) EditWorkspaceMember(workspaceId, userId uint16, role string) error {
+	tag, err := s.pool.Exec(context.Background(), `
+		UPDATE workspace_member
+		SET role = $3
+		WHERE workspace_id = $1 AND user_id = $2`,
+		workspaceId,
+		userId,
+		role,
+	)
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return ErrMemberNotFound
+	}
+
+	if config.DebugLog() {
+		log.Println("workspace member edit: successfully edited data in db")
+	}
+
+	return nil
+}
+
+func (s *PgStore) RemoveWorkspaceMember(workspaceId, userId uint16) error {
+	ctx := context.Background()
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, `
+		DELETE FROM workspace_member
+		WHERE workspace_id = $1 AND user_id = $2`,
+		workspaceId,
+		userId,
+	)
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return ErrMemberNotFound
+	}
+
+	_, err = tx.Exec(ctx, `
+		DELETE FROM task_assignee a
+		USING task t
+		WHERE a.task_id = t.id AND t.owner_id = $1 AND a.user_id = $2`,
+		workspaceId,
+		userId,
+	)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return err
+	}
+
+	if config.DebugLog() {
+		log.Println("workspace member remove: successfully deleted data in db")
+	}
+
+	return nil
+}
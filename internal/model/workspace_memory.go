@@ -0,0 +1,167 @@
+package model
+
+import (
+	"sort"
+	"time"
+)
+
+// memberWorkspaces returns workspaces of the user membership, accepted or pending ones,
+// must be called with s.mu held
+func (s *MemoryStore) memberWorkspaces(userId uint16, accepted bool) []*Workspace {
+	var result []*Workspace = []*Workspace{}
+	for workspaceId, members := range s.workspaceMembers {
+		member, ok := members[userId]
+		if !ok || (member.AcceptedAt != nil) != accepted {
+			continue
+		}
+
+		result = append(result, &Workspace{
+			Id:         workspaceId,
+			OwnerLogin: s.users[workspaceId].Login,
+			Role:       member.Role,
+		})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].OwnerLogin < result[j].OwnerLogin
+	})
+
+	return result
+}
+
+func (s *MemoryStore) GetWorkspaceList(userId uint16) ([]*Workspace, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	user, ok := s.users[userId]
+	if !ok {
+		return nil, ErrWorkspaceNotFound
+	}
+
+	own := &Workspace{Id: userId, OwnerLogin: user.Login, Role: RoleOwner}
+	return append([]*Workspace{own}, s.memberWorkspaces(userId, true)...), nil
+}
+
+func (s *MemoryStore) GetWorkspaceInvitations(userId uint16) ([]*Workspace, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.memberWorkspaces(userId, false), nil
+}
+
+func (s *MemoryStore) GetWorkspaceRole(workspaceId, userId uint16) (string, error) {
+	if workspaceId == userId {
+		return RoleOwner, nil
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	member, ok := s.workspaceMembers[workspaceId][userId]
+	if !ok || member.AcceptedAt == nil {
+		return "", ErrWorkspaceNotFound
+	}
+
+	return member.Role, nil
+}
+
+func (s *MemoryStore) GetWorkspaceMembers(workspaceId uint16) ([]*WorkspaceMember, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var result []*WorkspaceMember = []*WorkspaceMember{}
+	for _, member := range s.workspaceMembers[workspaceId] {
+		item := *member
+		result = append(result, &item)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Login < result[j].Login
+	})
+
+	return result, nil
+}
+
+func (s *MemoryStore) InviteWorkspaceMember(workspaceId uint16, login, role string) (*WorkspaceMember, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var user *User
+	for _, item := range s.users {
+		if item.Login == login {
+			user = item
+		}
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+
+	if _, ok := s.workspaceMembers[workspaceId][user.Id]; ok || user.Id == workspaceId {
+		return nil, ErrMemberExists
+	}
+
+	member := &WorkspaceMember{
+		UserId:    user.Id,
+		Login:     user.Login,
+		Role:      role,
+		InvitedAt: time.Now(),
+	}
+
+	if s.workspaceMembers[workspaceId] == nil {
+		s.workspaceMembers[workspaceId] = map[uint16]*WorkspaceMember{}
+	}
+	s.workspaceMembers[workspaceId][user.Id] = member
+
+	item := *member
+	return &item, nil
+}
+
+func (s *MemoryStore) AcceptWorkspaceInvitation(workspaceId, userId uint16) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	member, ok := s.workspaceMembers[workspaceId][userId]
+	if !ok {
+		return ErrInvitationNotFound
+	}
+
+	if member.AcceptedAt == nil {
+		now := time.Now()
+		member.AcceptedAt = &now
+	}
+
+	return nil
+}
+
+func (s *MemoryStore) EditWorkspaceMember(workspaceId, userId uint16, role string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	member, ok := s.workspaceMembers[workspaceId][userId]
+	if !ok {
+		return ErrMemberNotFound
+	}
+
+	member.Role = role
+
+	return nil
+}
+
+func (s *MemoryStore) RemoveWorkspaceMember(workspaceId, userId uint16) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.workspaceMembers[workspaceId][userId]; !ok {
+		return ErrMemberNotFound
+	}
+
+	delete(s.workspaceMembers[workspaceId], userId)
+
+	for _, task := range s.tasks {
+		if task.OwnerId == workspaceId {
+			delete(s.taskAssignees[task.Id], userId)
+		}
+	}
+
+	return nil
+}
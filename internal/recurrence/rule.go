@@ -0,0 +1,302 @@
+// Package recurrence implements the subset of iCalendar RRULE (RFC 5545) recurring tasks use:
+// FREQ of DAILY, WEEKLY or MONTHLY with INTERVAL, BYDAY for weekly, BYMONTHDAY for monthly and UNTIL.
+// Occurrences keep the wall clock time of the start in its location, so they do not drift over DST
+package recurrence
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	Daily   = "DAILY"
+	Weekly  = "WEEKLY"
+	Monthly = "MONTHLY"
+)
+
+// MaxInterval keeps a rule from pointing centuries ahead
+const MaxInterval = 1000
+
+// maxPeriods bounds the search of the next occurrence, e.g. BYMONTHDAY=31 skips short months
+const maxPeriods = 10000
+
+var weekdays = map[string]time.Weekday{
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+	"SU": time.Sunday,
+}
+
+var weekdayNames = []string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+const (
+	untilLayout     = "20060102T150405Z"
+	untilDateLayout = "20060102"
+)
+
+// Rule is a parsed recurrence rule, the first occurrence is the start passed to Next
+type Rule struct {
+	Freq       string
+	Interval   int
+	ByDay      []time.Weekday // weekly only, empty means weekday of the start
+	ByMonthDay []int          // monthly only, negative counts from the end of month, empty means day of the start
+	Until      *time.Time     // last possible occurrence, inclusive
+	untilDate  bool           // Until is a date, it is compared with dates of occurrences
+}
+
+// Parse parses rule like FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH, an optional RRULE: prefix is allowed
+func Parse(s string) (*Rule, error) {
+	s = strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(s)), "RRULE:")
+	if s == "" {
+		return nil, errors.New("rule is empty")
+	}
+
+	rule := &Rule{Interval: 1}
+	seen := map[string]bool{}
+
+	for _, part := range strings.Split(s, ";") {
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 || kv[1] == "" {
+			return nil, fmt.Errorf("%q is not NAME=VALUE", part)
+		}
+
+		name, value := kv[0], kv[1]
+		if seen[name] {
+			return nil, fmt.Errorf("%s is repeated", name)
+		}
+		seen[name] = true
+
+		switch name {
+		case "FREQ":
+			if value != Daily && value != Weekly && value != Monthly {
+				return nil, fmt.Errorf("FREQ must be DAILY, WEEKLY or MONTHLY")
+			}
+			rule.Freq = value
+		case "INTERVAL":
+			interval, err := strconv.Atoi(value)
+			if err != nil || interval < 1 || interval > MaxInterval {
+				return nil, fmt.Errorf("INTERVAL must be an integer from 1 to %d", MaxInterval)
+			}
+			rule.Interval = interval
+		case "BYDAY":
+			for _, day := range strings.Split(value, ",") {
+				weekday, ok := weekdays[day]
+				if !ok {
+					return nil, fmt.Errorf("BYDAY must be a list of MO, TU, WE, TH, FR, SA, SU")
+				}
+				rule.ByDay = append(rule.ByDay, weekday)
+			}
+		case "BYMONTHDAY":
+			for _, day := range strings.Split(value, ",") {
+				monthDay, err := strconv.Atoi(day)
+				if err != nil || monthDay == 0 || monthDay < -31 || monthDay > 31 {
+					return nil, fmt.Errorf("BYMONTHDAY must be a list of days from 1 to 31 or from -31 to -1")
+				}
+				rule.ByMonthDay = append(rule.ByMonthDay, monthDay)
+			}
+		case "UNTIL":
+			until, err := time.Parse(untilLayout, value)
+			if err != nil {
+				until, err = time.Parse(untilDateLayout, value)
+				rule.untilDate = true
+			}
+			if err != nil {
+				return nil, fmt.Errorf("UNTIL must be YYYYMMDD or YYYYMMDDTHHMMSSZ")
+			}
+			rule.Until = &until
+		default:
+			return nil, fmt.Errorf("%s is not supported", name)
+		}
+	}
+
+	if rule.Freq == "" {
+		return nil, errors.New("FREQ is required")
+	}
+	if len(rule.ByDay) > 0 && rule.Freq != Weekly {
+		return nil, errors.New("BYDAY is supported with FREQ=WEEKLY only")
+	}
+	if len(rule.ByMonthDay) > 0 && rule.Freq != Monthly {
+		return nil, errors.New("BYMONTHDAY is supported with FREQ=MONTHLY only")
+	}
+
+	sort.Slice(rule.ByDay, func(i, j int) bool {
+		return mondayIndex(rule.ByDay[i]) < mondayIndex(rule.ByDay[j])
+	})
+
+	return rule, nil
+}
+
+// String formats the rule back, parsing it gives the same rule
+func (r *Rule) String() string {
+	parts := []string{"FREQ=" + r.Freq}
+
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+
+	if len(r.ByDay) > 0 {
+		var days []string
+		for _, day := range r.ByDay {
+			days = append(days, weekdayNames[day])
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+
+	if len(r.ByMonthDay) > 0 {
+		var days []string
+		for _, day := range r.ByMonthDay {
+			days = append(days, strconv.Itoa(day))
+		}
+		parts = append(parts, "BYMONTHDAY="+strings.Join(days, ","))
+	}
+
+	if r.Until != nil {
+		if r.untilDate {
+			parts = append(parts, "UNTIL="+r.Until.Format(untilDateLayout))
+		} else {
+			parts = append(parts, "UNTIL="+r.Until.UTC().Format(untilLayout))
+		}
+	}
+
+	return strings.Join(parts, ";")
+}
+
+// Next returns the first occurrence of the series started at start which is later than after.
+// It reports false when the series ends before that
+func (r *Rule) Next(start, after time.Time) (time.Time, bool) {
+	if !after.Before(start) {
+		// skip the periods which end before after, there is nothing later than after in them
+		return r.nextFrom(start, r.periodsBefore(start, after), after)
+	}
+	return r.nextFrom(start, 0, after)
+}
+
+// Occurrences returns up to n occurrences following after, each one is later than the previous
+func (r *Rule) Occurrences(start, after time.Time, n int) []time.Time {
+	var result []time.Time = []time.Time{}
+	for len(result) < n {
+		next, ok := r.Next(start, after)
+		if !ok {
+			break
+		}
+		result = append(result, next)
+		after = next
+	}
+	return result
+}
+
+func (r *Rule) nextFrom(start time.Time, period int, after time.Time) (time.Time, bool) {
+	if r.ended(after) {
+		return time.Time{}, false
+	}
+
+	for limit := period + maxPeriods; period < limit; period++ {
+		for _, candidate := range r.period(start, period) {
+			if candidate.Before(start) || !candidate.After(after) {
+				continue
+			}
+			if r.ended(candidate) {
+				return time.Time{}, false
+			}
+			return candidate, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// periodsBefore estimates how many whole periods after start there are before after, it never overestimates
+func (r *Rule) periodsBefore(start, after time.Time) int {
+	var periods int
+	switch r.Freq {
+	case Daily:
+		periods = int(after.Sub(start).Hours()/24) / r.Interval
+	case Weekly:
+		periods = int(after.Sub(start).Hours()/24/7) / r.Interval
+	case Monthly:
+		months := (after.Year()-start.Year())*12 + int(after.Month()) - int(start.Month())
+		periods = months / r.Interval
+	}
+
+	// DST and month lengths may make the estimate off by one
+	if periods > 0 {
+		periods--
+	}
+	return periods
+}
+
+// period returns occurrence candidates of the period ordered by time, some of them may be before start
+func (r *Rule) period(start time.Time, n int) []time.Time {
+	year, month, day := start.Date()
+	hour, min, sec := start.Clock()
+	loc := start.Location()
+	at := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, hour, min, sec, start.Nanosecond(), loc)
+	}
+
+	switch r.Freq {
+	case Weekly:
+		monday := day - mondayIndex(start.Weekday()) + n*r.Interval*7
+		if len(r.ByDay) == 0 {
+			return []time.Time{at(year, month, monday+mondayIndex(start.Weekday()))}
+		}
+		var result []time.Time
+		for _, weekday := range r.ByDay {
+			result = append(result, at(year, month, monday+mondayIndex(weekday)))
+		}
+		return result
+	case Monthly:
+		first := time.Date(year, month+time.Month(n*r.Interval), 1, 0, 0, 0, 0, loc)
+		length := time.Date(first.Year(), first.Month()+1, 0, 0, 0, 0, 0, loc).Day()
+
+		monthDays := r.ByMonthDay
+		if len(monthDays) == 0 {
+			monthDays = []int{day}
+		}
+
+		var days []int
+		for _, monthDay := range monthDays {
+			if monthDay < 0 {
+				monthDay += length + 1
+			}
+			// months without the day are skipped the way RFC 5545 does
+			if monthDay >= 1 && monthDay <= length {
+				days = append(days, monthDay)
+			}
+		}
+		sort.Ints(days)
+
+		result := []time.Time{}
+		for i, monthDay := range days {
+			if i == 0 || days[i-1] != monthDay {
+				result = append(result, at(first.Year(), first.Month(), monthDay))
+			}
+		}
+		return result
+	}
+
+	return []time.Time{at(year, month, day+n*r.Interval)}
+}
+
+// ended tells whether the occurrence is past UNTIL
+func (r *Rule) ended(occurrence time.Time) bool {
+	if r.Until == nil {
+		return false
+	}
+	if r.untilDate {
+		year, month, day := occurrence.Date()
+		return time.Date(year, month, day, 0, 0, 0, 0, time.UTC).After(*r.Until)
+	}
+	return occurrence.After(*r.Until)
+}
+
+// mondayIndex numbers weekdays from monday, weeks start on monday as RFC 5545 WKST default
+func mondayIndex(weekday time.Weekday) int {
+	return (int(weekday) + 6) % 7
+}
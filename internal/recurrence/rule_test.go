@@ -0,0 +1,75 @@
+package recurrence
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	rule, err := Parse("rrule:freq=weekly;byday=th,mo;interval=2")
+	assert.NoError(t, err)
+	assert.Equal(t, "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH", rule.String())
+
+	rule, err = Parse("FREQ=MONTHLY;BYMONTHDAY=-1;UNTIL=20240630")
+	assert.NoError(t, err)
+	assert.Equal(t, "FREQ=MONTHLY;BYMONTHDAY=-1;UNTIL=20240630", rule.String())
+
+	for _, s := range []string{
+		"",
+		"INTERVAL=2",
+		"FREQ=YEARLY",
+		"FREQ=DAILY;INTERVAL=0",
+		"FREQ=DAILY;BYDAY=MO",
+		"FREQ=WEEKLY;BYMONTHDAY=1",
+		"FREQ=WEEKLY;BYDAY=XX",
+		"FREQ=MONTHLY;BYMONTHDAY=32",
+		"FREQ=DAILY;COUNT=3",
+		"FREQ=DAILY;FREQ=WEEKLY",
+		"FREQ=DAILY;UNTIL=tomorrow",
+	} {
+		_, err := Parse(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestOccurrences(t *testing.T) {
+	amsterdam, _ := time.LoadLocation("Europe/Amsterdam")
+	format := func(list []time.Time) []string {
+		var result []string
+		for _, item := range list {
+			result = append(result, item.Format("2006-01-02T15:04 Mon"))
+		}
+		return result
+	}
+
+	// wall clock time stays the same over the DST change of 31 March
+	start := time.Date(2024, 3, 29, 9, 0, 0, 0, amsterdam)
+	rule, _ := Parse("FREQ=DAILY;INTERVAL=2")
+	assert.Equal(t, []string{"2024-03-31T09:00 Sun", "2024-04-02T09:00 Tue", "2024-04-04T09:00 Thu"},
+		format(rule.Occurrences(start, start, 3)))
+
+	// the start is a wednesday, the series starts there even though monday is in BYDAY
+	start = time.Date(2024, 5, 1, 18, 30, 0, 0, time.UTC)
+	rule, _ = Parse("FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE")
+	assert.Equal(t, []string{"2024-05-01T18:30 Wed", "2024-05-13T18:30 Mon", "2024-05-15T18:30 Wed", "2024-05-27T18:30 Mon"},
+		format(rule.Occurrences(start, start.Add(-time.Minute), 4)))
+
+	// missed occurrences are skipped
+	assert.Equal(t, []string{"2024-06-10T18:30 Mon"},
+		format(rule.Occurrences(start, time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC), 1)))
+
+	// months without the 31st are skipped
+	start = time.Date(2024, 1, 31, 8, 0, 0, 0, time.UTC)
+	rule, _ = Parse("FREQ=MONTHLY")
+	assert.Equal(t, []string{"2024-03-31T08:00 Sun", "2024-05-31T08:00 Fri"},
+		format(rule.Occurrences(start, start, 2)))
+
+	rule, _ = Parse("FREQ=MONTHLY;BYMONTHDAY=-1,15;UNTIL=20240415")
+	assert.Equal(t, []string{"2024-02-15T08:00 Thu", "2024-02-29T08:00 Thu", "2024-03-15T08:00 Fri", "2024-03-31T08:00 Sun", "2024-04-15T08:00 Mon"},
+		format(rule.Occurrences(start, start, 10)))
+
+	_, ok := rule.Next(start, time.Date(2024, 4, 16, 0, 0, 0, 0, time.UTC))
+	assert.False(t, ok)
+}
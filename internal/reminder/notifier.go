@@ -0,0 +1,32 @@
+package reminder
+
+import (
+	"context"
+	"log"
+	"time"
+	"todo/internal/model"
+)
+
+// Event is emitted once per task when its due date is closer than the lead time
+type Event struct {
+	Task *model.Task
+	At   time.Time // when the scheduler noticed the task
+}
+
+// Notifier delivers reminder events, e.g. by email or a webhook
+type Notifier interface {
+	Notify(ctx context.Context, event Event) error
+}
+
+// LogNotifier is the default notifier, it only writes events to the log
+type LogNotifier struct{}
+
+func (LogNotifier) Notify(ctx context.Context, event Event) error {
+	log.Printf("reminder: task %d %q of user %d is due at %s",
+		event.Task.Id,
+		event.Task.Name,
+		event.Task.OwnerId,
+		event.Task.DueAt.Format(time.RFC3339),
+	)
+	return nil
+}
@@ -0,0 +1,77 @@
+package reminder
+
+import (
+	"context"
+	"log"
+	"time"
+	"todo/internal/config"
+	"todo/internal/model"
+)
+
+// batchSize limits tasks claimed by one query
+const batchSize = 100
+
+// Scheduler periodically claims tasks due within the lead time and passes them to the notifier
+type Scheduler struct {
+	store    model.TaskReminderStore
+	notifier Notifier
+	lead     time.Duration
+	interval time.Duration
+}
+
+func NewScheduler(store model.TaskReminderStore, notifier Notifier, lead, interval time.Duration) *Scheduler {
+	return &Scheduler{
+		store:    store,
+		notifier: notifier,
+		lead:     lead,
+		interval: interval,
+	}
+}
+
+// Run checks due tasks every interval until ctx is done
+func (s *Scheduler) Run(ctx context.Context) {
+	if s.interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.RemindDue(ctx, time.Now()); err != nil {
+			log.Printf("reminder: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RemindDue notifies about every not yet reminded task due before now plus lead time.
+// Tasks are claimed before notifying, so a failed notification is logged and not retried
+func (s *Scheduler) RemindDue(ctx context.Context, now time.Time) error {
+	for {
+		tasks, err := s.store.ClaimTaskReminders(now.Add(s.lead), batchSize)
+		if err != nil {
+			return err
+		}
+
+		for _, task := range tasks {
+			err := s.notifier.Notify(ctx, Event{Task: task, At: now})
+			if err != nil {
+				log.Printf("reminder: task %d: %v", task.Id, err)
+			}
+		}
+
+		if config.DebugLog() && len(tasks) > 0 {
+			log.Printf("reminder: %d tasks notified", len(tasks))
+		}
+
+		if len(tasks) < batchSize {
+			return nil
+		}
+	}
+}
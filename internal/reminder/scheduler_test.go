@@ -0,0 +1,49 @@
+package reminder
+
+import (
+	"context"
+	"testing"
+	"time"
+	"todo/internal/model"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type recordingNotifier struct {
+	names []string
+}
+
+func (n *recordingNotifier) Notify(ctx context.Context, event Event) error {
+	n.names = append(n.names, event.Task.Name)
+	return nil
+}
+
+func TestRemindDue(t *testing.T) {
+	store := model.NewMemoryStore()
+	now := time.Now()
+
+	due := func(d time.Duration) *time.Time {
+		t := now.Add(d)
+		return &t
+	}
+
+	store.CreateTask(1, 1, model.TaskFields{Name: "Soon", DueAt: due(30 * time.Minute)})
+	store.CreateTask(1, 1, model.TaskFields{Name: "Overdue", DueAt: due(-time.Hour)})
+	store.CreateTask(1, 1, model.TaskFields{Name: "Later", DueAt: due(2 * time.Hour)})
+	store.CreateTask(1, 1, model.TaskFields{Name: "Someday"})
+	done, _ := store.CreateTask(1, 1, model.TaskFields{Name: "Done", DueAt: due(time.Minute)})
+	store.DoneTask(1, done.Id, 1)
+
+	notifier := &recordingNotifier{}
+	scheduler := NewScheduler(store, notifier, time.Hour, time.Minute)
+
+	assert.NoError(t, scheduler.RemindDue(context.Background(), now))
+	assert.Equal(t, []string{"Overdue", "Soon"}, notifier.names)
+
+	// every task is reminded once
+	assert.NoError(t, scheduler.RemindDue(context.Background(), now))
+	assert.Equal(t, []string{"Overdue", "Soon"}, notifier.names)
+
+	assert.NoError(t, scheduler.RemindDue(context.Background(), now.Add(90*time.Minute)))
+	assert.Equal(t, []string{"Overdue", "Soon", "Later"}, notifier.names)
+}
@@ -0,0 +1,310 @@
+package db
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// migrationLockId is key of postgres advisory lock, so that two app instances do not migrate at once
+const migrationLockId = 7405163
+
+var migrationFileName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+type MigrationStatus struct {
+	Migration
+	AppliedAt *time.Time // nil when pending
+}
+
+// Migrations returns embedded migrations ordered by version
+func Migrations() ([]Migration, error) {
+	entries, err := migrationFiles.ReadDir("migrations")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[int]*Migration{}
+	for _, entry := range entries {
+		match := migrationFileName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("migration %s: file name must be <version>_<name>.<up|down>.sql", entry.Name())
+		}
+
+		version, _ := strconv.Atoi(match[1])
+		content, err := migrationFiles.ReadFile(path.Join("migrations", entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		} else if migration.Name != match[2] {
+			return nil, fmt.Errorf("migration %d has two names: %s and %s", version, migration.Name, match[2])
+		}
+
+		if match[3] == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	var result []Migration
+	for _, migration := range byVersion {
+		if migration.Up == "" || migration.Down == "" {
+			return nil, fmt.Errorf("migration %d_%s must have both up and down files", migration.Version, migration.Name)
+		}
+		result = append(result, *migration)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Version < result[j].Version
+	})
+
+	for i, migration := range result {
+		if migration.Version != i+1 {
+			return nil, fmt.Errorf("migration versions must be sequential from 1, got %d at position %d", migration.Version, i+1)
+		}
+	}
+
+	return result, nil
+}
+
+// withMigrationLock runs f on a single connection holding the migration advisory lock
+func withMigrationLock(pool *pgxpool.Pool, f func(conn *pgxpool.Conn) error) error {
+	ctx := context.Background()
+
+	conn, err := pool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	_, err = conn.Exec(ctx, "SELECT pg_advisory_lock($1)", migrationLockId)
+	if err != nil {
+		return err
+	}
+	defer conn.Exec(ctx, "SELECT pg_advisory_unlock($1)", migrationLockId)
+
+	_, err = conn.Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS public.schema_migrations (
+			version integer NOT NULL PRIMARY KEY,
+			name character varying(255) NOT NULL,
+			applied_at timestamp with time zone DEFAULT now() NOT NULL
+		)`)
+	if err != nil {
+		return err
+	}
+
+	return f(conn)
+}
+
+func appliedMigrations(conn *pgxpool.Conn) (map[int]time.Time, error) {
+	rows, err := conn.Query(context.Background(), `
+		SELECT version, applied_at
+		FROM schema_migrations
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := map[int]time.Time{}
+	for rows.Next() {
+		var version int
+		var appliedAt time.Time
+
+		err = rows.Scan(&version, &appliedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		result[version] = appliedAt
+	}
+
+	return result, rows.Err()
+}
+
+// applyMigration runs sql and records version change in one transaction
+func applyMigration(conn *pgxpool.Conn, sql string, record func(tx pgx.Tx) error) error {
+	ctx := context.Background()
+
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, sql)
+	if err != nil {
+		return err
+	}
+
+	err = record(tx)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// MigrateUp applies every pending migration and returns the applied ones
+func MigrateUp(pool *pgxpool.Pool) ([]Migration, error) {
+	migrations, err := Migrations()
+	if err != nil {
+		return nil, err
+	}
+
+	var result []Migration
+	err = withMigrationLock(pool, func(conn *pgxpool.Conn) error {
+		applied, err := appliedMigrations(conn)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range migrations {
+			if _, ok := applied[migration.Version]; ok {
+				continue
+			}
+
+			m := migration
+			err = applyMigration(conn, m.Up, func(tx pgx.Tx) error {
+				_, err := tx.Exec(context.Background(), `
+					INSERT INTO schema_migrations(version, name)
+					VALUES ($1, $2)`,
+					m.Version,
+					m.Name,
+				)
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("migration %d_%s up: %w", m.Version, m.Name, err)
+			}
+
+			result = append(result, m)
+		}
+
+		return nil
+	})
+
+	return result, err
+}
+
+// MigrateDown reverts the latest applied migration, returns nil when nothing is applied
+func MigrateDown(pool *pgxpool.Pool) (*Migration, error) {
+	migrations, err := Migrations()
+	if err != nil {
+		return nil, err
+	}
+
+	var result *Migration
+	err = withMigrationLock(pool, func(conn *pgxpool.Conn) error {
+		applied, err := appliedMigrations(conn)
+		if err != nil {
+			return err
+		}
+
+		for i := len(migrations) - 1; i >= 0; i-- {
+			m := migrations[i]
+			if _, ok := applied[m.Version]; !ok {
+				continue
+			}
+
+			err = applyMigration(conn, m.Down, func(tx pgx.Tx) error {
+				_, err := tx.Exec(context.Background(), `
+					DELETE FROM schema_migrations
+					WHERE version = $1`,
+					m.Version,
+				)
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("migration %d_%s down: %w", m.Version, m.Name, err)
+			}
+
+			result = &m
+			return nil
+		}
+
+		return nil
+	})
+
+	return result, err
+}
+
+// MigrateForce marks migrations up to version as applied without running them,
+// meant for databases created by hand before migrations existed
+func MigrateForce(pool *pgxpool.Pool, version int) error {
+	migrations, err := Migrations()
+	if err != nil {
+		return err
+	}
+
+	if version < 0 || version > len(migrations) {
+		return fmt.Errorf("version must be between 0 and %d", len(migrations))
+	}
+
+	return withMigrationLock(pool, func(conn *pgxpool.Conn) error {
+		return applyMigration(conn, "DELETE FROM schema_migrations", func(tx pgx.Tx) error {
+			for _, m := range migrations[:version] {
+				_, err := tx.Exec(context.Background(), `
+					INSERT INTO schema_migrations(version, name)
+					VALUES ($1, $2)`,
+					m.Version,
+					m.Name,
+				)
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	})
+}
+
+func GetMigrationStatus(pool *pgxpool.Pool) ([]MigrationStatus, error) {
+	migrations, err := Migrations()
+	if err != nil {
+		return nil, err
+	}
+
+	var result []MigrationStatus
+	err = withMigrationLock(pool, func(conn *pgxpool.Conn) error {
+		applied, err := appliedMigrations(conn)
+		if err != nil {
+			return err
+		}
+
+		for _, m := range migrations {
+			status := MigrationStatus{Migration: m}
+			if appliedAt, ok := applied[m.Version]; ok {
+				status.AppliedAt = &appliedAt
+			}
+			result = append(result, status)
+		}
+
+		return nil
+	})
+
+	return result, err
+}
@@ -0,0 +1,3 @@
+DROP TABLE public.task;
+
+DROP TABLE public.status;
@@ -0,0 +1,18 @@
+CREATE TABLE public.users (
+    id integer NOT NULL,
+    login character varying(120) NOT NULL,
+    password_hash character varying(255) NOT NULL
+);
+
+ALTER TABLE public.users ALTER COLUMN id ADD GENERATED ALWAYS AS IDENTITY (
+    SEQUENCE NAME public.users_id_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    MAXVALUE 65535
+    CACHE 1
+);
+
+ALTER TABLE ONLY public.users ADD CONSTRAINT users_pkey PRIMARY KEY (id);
+
+ALTER TABLE ONLY public.users ADD CONSTRAINT users_login_key UNIQUE (login);
@@ -0,0 +1,3 @@
+ALTER TABLE public.task DROP COLUMN owner_id;
+
+DELETE FROM public.users WHERE login = 'legacy' AND password_hash = '!';
@@ -0,0 +1,17 @@
+ALTER TABLE public.task ADD COLUMN owner_id integer;
+
+-- tasks created before ownership are given to a 'legacy' user nobody can log in as ('!' is not a bcrypt hash),
+-- reassign them by hand: UPDATE task SET owner_id = <user id> WHERE owner_id = <legacy id>
+INSERT INTO public.users (login, password_hash)
+SELECT 'legacy', '!'
+WHERE EXISTS (SELECT 1 FROM public.task);
+
+UPDATE public.task
+SET owner_id = (SELECT id FROM public.users WHERE login = 'legacy')
+WHERE owner_id IS NULL;
+
+ALTER TABLE public.task ALTER COLUMN owner_id SET NOT NULL;
+
+CREATE INDEX fki_owner_fk ON public.task USING btree (owner_id);
+
+ALTER TABLE ONLY public.task ADD CONSTRAINT owner_fk FOREIGN KEY (owner_id) REFERENCES public.users(id) ON DELETE CASCADE;
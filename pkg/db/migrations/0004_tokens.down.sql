@@ -0,0 +1,3 @@
+DROP TABLE public.revoked_token;
+
+DROP TABLE public.refresh_token;
@@ -0,0 +1,16 @@
+CREATE TABLE public.refresh_token (
+    id uuid NOT NULL,
+    user_id integer NOT NULL,
+    expires_at timestamp with time zone NOT NULL
+);
+
+ALTER TABLE ONLY public.refresh_token ADD CONSTRAINT refresh_token_pkey PRIMARY KEY (id);
+
+ALTER TABLE ONLY public.refresh_token ADD CONSTRAINT user_fk FOREIGN KEY (user_id) REFERENCES public.users(id) ON DELETE CASCADE;
+
+CREATE TABLE public.revoked_token (
+    id uuid NOT NULL,
+    expires_at timestamp with time zone NOT NULL
+);
+
+ALTER TABLE ONLY public.revoked_token ADD CONSTRAINT revoked_token_pkey PRIMARY KEY (id);
@@ -0,0 +1 @@
+ALTER TABLE public.task ADD COLUMN status_before_delete character varying(120);
@@ -0,0 +1,5 @@
+DROP INDEX public.task_owner_name_idx;
+
+DROP INDEX public.fki_owner_fk;
+
+CREATE INDEX fki_owner_fk ON public.task USING btree (owner_id);
@@ -0,0 +1,5 @@
+DROP INDEX public.fki_owner_fk;
+
+CREATE INDEX fki_owner_fk ON public.task USING btree (owner_id, id);
+
+CREATE INDEX task_owner_name_idx ON public.task USING btree (owner_id, name, id);
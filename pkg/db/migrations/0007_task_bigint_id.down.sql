@@ -0,0 +1,7 @@
+-- fails if any task id does not fit into the old range
+ALTER TABLE public.task DROP COLUMN public_id;
+
+ALTER TABLE public.task DROP CONSTRAINT task_pkey;
+
+ALTER TABLE public.task ALTER COLUMN id SET MAXVALUE 65535;
+ALTER TABLE public.task ALTER COLUMN id TYPE integer;
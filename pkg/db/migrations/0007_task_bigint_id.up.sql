@@ -0,0 +1,10 @@
+ALTER TABLE public.task ALTER COLUMN id TYPE bigint;
+ALTER TABLE public.task ALTER COLUMN id SET MAXVALUE 9223372036854775807;
+
+ALTER TABLE ONLY public.task ADD CONSTRAINT task_pkey PRIMARY KEY (id);
+
+CREATE EXTENSION IF NOT EXISTS pgcrypto; -- gen_random_uuid on postgres 12
+
+ALTER TABLE public.task ADD COLUMN public_id uuid DEFAULT gen_random_uuid() NOT NULL; -- fills existing rows
+
+ALTER TABLE ONLY public.task ADD CONSTRAINT task_public_id_key UNIQUE (public_id);
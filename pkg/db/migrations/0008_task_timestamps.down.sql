@@ -0,0 +1,7 @@
+DROP TABLE public.task_status_history;
+
+ALTER TABLE public.task DROP COLUMN deleted_at;
+ALTER TABLE public.task DROP COLUMN completed_at;
+ALTER TABLE public.task DROP COLUMN started_at;
+ALTER TABLE public.task DROP COLUMN updated_at;
+ALTER TABLE public.task DROP COLUMN created_at;
@@ -0,0 +1,26 @@
+ALTER TABLE public.task ADD COLUMN created_at timestamp with time zone DEFAULT now() NOT NULL;
+ALTER TABLE public.task ADD COLUMN updated_at timestamp with time zone DEFAULT now() NOT NULL;
+ALTER TABLE public.task ADD COLUMN started_at timestamp with time zone;
+ALTER TABLE public.task ADD COLUMN completed_at timestamp with time zone;
+ALTER TABLE public.task ADD COLUMN deleted_at timestamp with time zone;
+
+CREATE TABLE public.task_status_history (
+    id bigint NOT NULL GENERATED ALWAYS AS IDENTITY,
+    task_id bigint NOT NULL,
+    from_status character varying(120), -- NULL for task creation
+    to_status character varying(120) NOT NULL,
+    changed_by integer,
+    changed_at timestamp with time zone DEFAULT now() NOT NULL
+);
+
+ALTER TABLE ONLY public.task_status_history ADD CONSTRAINT task_status_history_pkey PRIMARY KEY (id);
+
+ALTER TABLE ONLY public.task_status_history ADD CONSTRAINT task_fk FOREIGN KEY (task_id) REFERENCES public.task(id) ON DELETE CASCADE;
+
+ALTER TABLE ONLY public.task_status_history ADD CONSTRAINT changed_by_fk FOREIGN KEY (changed_by) REFERENCES public.users(id) ON DELETE SET NULL;
+
+CREATE INDEX task_status_history_task_idx ON public.task_status_history USING btree (task_id, changed_at);
+
+-- history of existing tasks starts with their current status
+INSERT INTO public.task_status_history(task_id, from_status, to_status, changed_by)
+SELECT id, NULL, status, owner_id FROM public.task;
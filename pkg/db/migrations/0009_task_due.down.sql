@@ -0,0 +1,6 @@
+DROP INDEX public.task_reminder_idx;
+DROP INDEX public.task_owner_due_idx;
+
+ALTER TABLE public.task DROP COLUMN reminded_at;
+ALTER TABLE public.task DROP COLUMN due_timezone;
+ALTER TABLE public.task DROP COLUMN due_at;
@@ -0,0 +1,9 @@
+ALTER TABLE public.task ADD COLUMN due_at timestamp with time zone;
+ALTER TABLE public.task ADD COLUMN due_timezone character varying(64) DEFAULT '' NOT NULL;
+ALTER TABLE public.task ADD COLUMN reminded_at timestamp with time zone;
+
+-- matches sort by due_at, tasks without due date go last
+CREATE INDEX task_owner_due_idx ON public.task USING btree (owner_id, (COALESCE(due_at, 'infinity')), id);
+
+-- reminder scheduler only looks for not yet reminded tasks
+CREATE INDEX task_reminder_idx ON public.task USING btree (due_at) WHERE reminded_at IS NULL;
@@ -0,0 +1,7 @@
+DROP INDEX public.task_owner_priority_idx;
+DROP INDEX public.task_owner_status_position_idx;
+
+ALTER TABLE public.task DROP COLUMN position;
+
+ALTER TABLE public.task DROP CONSTRAINT task_priority_check;
+ALTER TABLE public.task DROP COLUMN priority;
@@ -0,0 +1,19 @@
+-- rank of priority: 0 low, 1 normal, 2 high, 3 urgent
+ALTER TABLE public.task ADD COLUMN priority smallint DEFAULT 1 NOT NULL;
+
+ALTER TABLE ONLY public.task ADD CONSTRAINT task_priority_check CHECK (priority BETWEEN 0 AND 3);
+
+-- fractional position key inside status column, "C" collation compares it by bytes
+ALTER TABLE public.task ADD COLUMN position text COLLATE "C" DEFAULT '' NOT NULL;
+
+-- existing tasks keep id order, keys must not end with "0"
+UPDATE public.task SET position = ordered.position
+FROM (
+    SELECT id, 'V' || lpad(row_number() OVER (PARTITION BY owner_id, status ORDER BY id)::text, 12, '0') || 'V' AS position
+    FROM public.task
+) ordered
+WHERE task.id = ordered.id;
+
+CREATE INDEX task_owner_status_position_idx ON public.task USING btree (owner_id, status, position, id);
+
+CREATE INDEX task_owner_priority_idx ON public.task USING btree (owner_id, priority, id);
@@ -0,0 +1,3 @@
+DROP TABLE public.task_label;
+
+DROP TABLE public.label;
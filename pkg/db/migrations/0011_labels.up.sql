@@ -0,0 +1,26 @@
+CREATE TABLE public.label (
+    id bigint NOT NULL GENERATED ALWAYS AS IDENTITY,
+    owner_id integer NOT NULL,
+    name character varying(64) NOT NULL,
+    color character varying(7) DEFAULT '' NOT NULL
+);
+
+ALTER TABLE ONLY public.label ADD CONSTRAINT label_pkey PRIMARY KEY (id);
+
+ALTER TABLE ONLY public.label ADD CONSTRAINT label_owner_name_key UNIQUE (owner_id, name);
+
+ALTER TABLE ONLY public.label ADD CONSTRAINT owner_fk FOREIGN KEY (owner_id) REFERENCES public.users(id) ON DELETE CASCADE;
+
+CREATE TABLE public.task_label (
+    task_id bigint NOT NULL,
+    label_id bigint NOT NULL
+);
+
+ALTER TABLE ONLY public.task_label ADD CONSTRAINT task_label_pkey PRIMARY KEY (task_id, label_id);
+
+ALTER TABLE ONLY public.task_label ADD CONSTRAINT task_fk FOREIGN KEY (task_id) REFERENCES public.task(id) ON DELETE CASCADE;
+
+ALTER TABLE ONLY public.task_label ADD CONSTRAINT label_fk FOREIGN KEY (label_id) REFERENCES public.label(id) ON DELETE CASCADE;
+
+-- label filter of task list looks tasks up by label
+CREATE INDEX task_label_label_idx ON public.task_label USING btree (label_id, task_id);
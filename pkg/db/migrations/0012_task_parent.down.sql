@@ -0,0 +1,6 @@
+DROP INDEX public.task_deleted_with_idx;
+ALTER TABLE public.task DROP COLUMN deleted_with;
+
+DROP INDEX public.task_parent_idx;
+ALTER TABLE public.task DROP CONSTRAINT parent_fk;
+ALTER TABLE public.task DROP COLUMN parent_id;
@@ -0,0 +1,11 @@
+ALTER TABLE public.task ADD COLUMN parent_id bigint;
+
+-- deleting a task completely deletes its subtree
+ALTER TABLE ONLY public.task ADD CONSTRAINT parent_fk FOREIGN KEY (parent_id) REFERENCES public.task(id) ON DELETE CASCADE;
+
+CREATE INDEX task_parent_idx ON public.task USING btree (parent_id, position, id);
+
+-- id of the task whose deletion moved this one to trash, restoring that task restores this one too
+ALTER TABLE public.task ADD COLUMN deleted_with bigint;
+
+CREATE INDEX task_deleted_with_idx ON public.task USING btree (deleted_with) WHERE deleted_with IS NOT NULL;
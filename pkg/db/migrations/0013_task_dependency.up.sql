@@ -0,0 +1,16 @@
+-- blocker_id blocks task_id, the task can not be started until the blocker is done
+CREATE TABLE public.task_dependency (
+    task_id bigint NOT NULL,
+    blocker_id bigint NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    CONSTRAINT task_dependency_not_self CHECK (task_id <> blocker_id)
+);
+
+ALTER TABLE ONLY public.task_dependency ADD CONSTRAINT task_dependency_pkey PRIMARY KEY (task_id, blocker_id);
+
+ALTER TABLE ONLY public.task_dependency ADD CONSTRAINT task_fk FOREIGN KEY (task_id) REFERENCES public.task(id) ON DELETE CASCADE;
+
+ALTER TABLE ONLY public.task_dependency ADD CONSTRAINT blocker_fk FOREIGN KEY (blocker_id) REFERENCES public.task(id) ON DELETE CASCADE;
+
+-- dependents of a task and cycle detection walk from blocker to task
+CREATE INDEX task_dependency_blocker_idx ON public.task_dependency USING btree (blocker_id, task_id);
@@ -0,0 +1 @@
+ALTER TABLE public.task DROP COLUMN recurrence;
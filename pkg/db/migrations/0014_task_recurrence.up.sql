@@ -0,0 +1,2 @@
+-- iCalendar RRULE subset, empty means the task does not recur
+ALTER TABLE public.task ADD COLUMN recurrence character varying(256) DEFAULT '' NOT NULL;
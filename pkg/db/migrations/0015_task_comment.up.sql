@@ -0,0 +1,17 @@
+CREATE TABLE public.task_comment (
+    id bigint NOT NULL GENERATED ALWAYS AS IDENTITY,
+    task_id bigint NOT NULL,
+    author_id integer NOT NULL,
+    body text NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    updated_at timestamp with time zone DEFAULT now() NOT NULL
+);
+
+ALTER TABLE ONLY public.task_comment ADD CONSTRAINT task_comment_pkey PRIMARY KEY (id);
+
+ALTER TABLE ONLY public.task_comment ADD CONSTRAINT task_fk FOREIGN KEY (task_id) REFERENCES public.task(id) ON DELETE CASCADE;
+
+ALTER TABLE ONLY public.task_comment ADD CONSTRAINT author_fk FOREIGN KEY (author_id) REFERENCES public.users(id) ON DELETE CASCADE;
+
+-- comment list pages and comment counts of task list
+CREATE INDEX task_comment_task_idx ON public.task_comment USING btree (task_id, id);
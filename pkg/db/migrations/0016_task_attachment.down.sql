@@ -0,0 +1 @@
+DROP TABLE public.task_attachment;
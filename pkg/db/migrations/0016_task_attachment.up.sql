@@ -0,0 +1,22 @@
+CREATE TABLE public.task_attachment (
+    id bigint NOT NULL GENERATED ALWAYS AS IDENTITY,
+    task_id bigint NOT NULL,
+    uploader_id integer NOT NULL,
+    name varchar(255) NOT NULL,
+    content_type varchar(255) NOT NULL,
+    size bigint NOT NULL,
+    checksum char(64) NOT NULL,
+    blob_key varchar(255) NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL
+);
+
+ALTER TABLE ONLY public.task_attachment ADD CONSTRAINT task_attachment_pkey PRIMARY KEY (id);
+
+ALTER TABLE ONLY public.task_attachment ADD CONSTRAINT task_attachment_blob_key_key UNIQUE (blob_key);
+
+ALTER TABLE ONLY public.task_attachment ADD CONSTRAINT task_fk FOREIGN KEY (task_id) REFERENCES public.task(id) ON DELETE CASCADE;
+
+ALTER TABLE ONLY public.task_attachment ADD CONSTRAINT uploader_fk FOREIGN KEY (uploader_id) REFERENCES public.users(id) ON DELETE CASCADE;
+
+-- attachment list of task
+CREATE INDEX task_attachment_task_idx ON public.task_attachment USING btree (task_id, id);
@@ -0,0 +1,17 @@
+CREATE TABLE public.task_checklist_item (
+    id bigint NOT NULL GENERATED ALWAYS AS IDENTITY,
+    task_id bigint NOT NULL,
+    text varchar(1000) NOT NULL,
+    checked boolean DEFAULT false NOT NULL,
+    -- fractional position key inside the checklist, "C" collation compares it by bytes
+    position text COLLATE "C" NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    updated_at timestamp with time zone DEFAULT now() NOT NULL
+);
+
+ALTER TABLE ONLY public.task_checklist_item ADD CONSTRAINT task_checklist_item_pkey PRIMARY KEY (id);
+
+ALTER TABLE ONLY public.task_checklist_item ADD CONSTRAINT task_fk FOREIGN KEY (task_id) REFERENCES public.task(id) ON DELETE CASCADE;
+
+-- checklist of task in order and its progress
+CREATE INDEX task_checklist_item_task_idx ON public.task_checklist_item USING btree (task_id, position, id);
@@ -0,0 +1,3 @@
+ALTER TABLE public.task DROP COLUMN project_id;
+
+DROP TABLE public.project;
@@ -0,0 +1,24 @@
+CREATE TABLE public.project (
+    id bigint NOT NULL GENERATED ALWAYS AS IDENTITY,
+    owner_id integer NOT NULL,
+    name character varying(128) NOT NULL,
+    description text DEFAULT '' NOT NULL,
+    color character varying(7) DEFAULT '' NOT NULL,
+    archived boolean DEFAULT false NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    updated_at timestamp with time zone DEFAULT now() NOT NULL
+);
+
+ALTER TABLE ONLY public.project ADD CONSTRAINT project_pkey PRIMARY KEY (id);
+
+ALTER TABLE ONLY public.project ADD CONSTRAINT project_owner_name_key UNIQUE (owner_id, name);
+
+ALTER TABLE ONLY public.project ADD CONSTRAINT owner_fk FOREIGN KEY (owner_id) REFERENCES public.users(id) ON DELETE CASCADE;
+
+-- tasks without project are kept, deleting a project takes its tasks out of it
+ALTER TABLE public.task ADD COLUMN project_id bigint;
+
+ALTER TABLE ONLY public.task ADD CONSTRAINT project_fk FOREIGN KEY (project_id) REFERENCES public.project(id) ON DELETE SET NULL;
+
+-- task list of project filtered by status
+CREATE INDEX task_project_status_idx ON public.task USING btree (project_id, status, id);
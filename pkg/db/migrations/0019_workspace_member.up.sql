@@ -0,0 +1,19 @@
+-- workspace of a user is everything the user owns, workspace_id is the owner id
+CREATE TABLE public.workspace_member (
+    workspace_id integer NOT NULL,
+    user_id integer NOT NULL,
+    role character varying(16) NOT NULL,
+    invited_at timestamp with time zone DEFAULT now() NOT NULL,
+    accepted_at timestamp with time zone,
+    CONSTRAINT workspace_member_role_check CHECK (role IN ('owner', 'editor', 'viewer')),
+    CONSTRAINT workspace_member_not_owner_check CHECK (workspace_id <> user_id)
+);
+
+ALTER TABLE ONLY public.workspace_member ADD CONSTRAINT workspace_member_pkey PRIMARY KEY (workspace_id, user_id);
+
+ALTER TABLE ONLY public.workspace_member ADD CONSTRAINT workspace_fk FOREIGN KEY (workspace_id) REFERENCES public.users(id) ON DELETE CASCADE;
+
+ALTER TABLE ONLY public.workspace_member ADD CONSTRAINT user_fk FOREIGN KEY (user_id) REFERENCES public.users(id) ON DELETE CASCADE;
+
+-- workspaces and invitations of a user
+CREATE INDEX workspace_member_user_idx ON public.workspace_member USING btree (user_id);
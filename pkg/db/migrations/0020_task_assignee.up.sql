@@ -0,0 +1,14 @@
+CREATE TABLE public.task_assignee (
+    task_id bigint NOT NULL,
+    user_id integer NOT NULL,
+    assigned_at timestamp with time zone DEFAULT now() NOT NULL
+);
+
+ALTER TABLE ONLY public.task_assignee ADD CONSTRAINT task_assignee_pkey PRIMARY KEY (task_id, user_id);
+
+ALTER TABLE ONLY public.task_assignee ADD CONSTRAINT task_fk FOREIGN KEY (task_id) REFERENCES public.task(id) ON DELETE CASCADE;
+
+ALTER TABLE ONLY public.task_assignee ADD CONSTRAINT user_fk FOREIGN KEY (user_id) REFERENCES public.users(id) ON DELETE CASCADE;
+
+-- tasks assigned to a user
+CREATE INDEX task_assignee_user_idx ON public.task_assignee USING btree (user_id, task_id);
@@ -0,0 +1,3 @@
+ALTER TABLE public.task DROP COLUMN estimate_minutes;
+
+DROP TABLE public.task_time_entry;
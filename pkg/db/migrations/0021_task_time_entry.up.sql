@@ -0,0 +1,26 @@
+CREATE TABLE public.task_time_entry (
+    id bigint NOT NULL GENERATED ALWAYS AS IDENTITY,
+    task_id bigint NOT NULL,
+    user_id integer NOT NULL,
+    started_at timestamp with time zone DEFAULT now() NOT NULL,
+    ended_at timestamp with time zone,
+    manual boolean DEFAULT false NOT NULL,
+    note text DEFAULT '' NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    CONSTRAINT task_time_entry_range_check CHECK (ended_at IS NULL OR ended_at >= started_at)
+);
+
+ALTER TABLE ONLY public.task_time_entry ADD CONSTRAINT task_time_entry_pkey PRIMARY KEY (id);
+
+ALTER TABLE ONLY public.task_time_entry ADD CONSTRAINT task_fk FOREIGN KEY (task_id) REFERENCES public.task(id) ON DELETE CASCADE;
+
+ALTER TABLE ONLY public.task_time_entry ADD CONSTRAINT user_fk FOREIGN KEY (user_id) REFERENCES public.users(id) ON DELETE CASCADE;
+
+-- entries of a task in time order
+CREATE INDEX task_time_entry_task_idx ON public.task_time_entry USING btree (task_id, started_at, id);
+
+-- a task has at most one running entry, it is open while the task is in progress
+CREATE UNIQUE INDEX task_time_entry_running_idx ON public.task_time_entry USING btree (task_id) WHERE ended_at IS NULL;
+
+-- original estimate in minutes, compared with the tracked time
+ALTER TABLE public.task ADD COLUMN estimate_minutes integer;